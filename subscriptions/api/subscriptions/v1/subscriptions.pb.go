@@ -0,0 +1,1279 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: subscriptions/v1/subscriptions.proto
+
+package subscriptionsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// MonthYear — месяц и год без дня, аналог models.MonthYear.
+type MonthYear struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Year          int32                  `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	Month         int32                  `protobuf:"varint,2,opt,name=month,proto3" json:"month,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MonthYear) Reset() {
+	*x = MonthYear{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MonthYear) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MonthYear) ProtoMessage() {}
+
+func (x *MonthYear) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MonthYear.ProtoReflect.Descriptor instead.
+func (*MonthYear) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MonthYear) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *MonthYear) GetMonth() int32 {
+	if x != nil {
+		return x.Month
+	}
+	return 0
+}
+
+// PricePeriod — цена с месяца effective_from до следующего периода.
+type PricePeriod struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EffectiveFrom *MonthYear             `protobuf:"bytes,1,opt,name=effective_from,json=effectiveFrom,proto3" json:"effective_from,omitempty"`
+	Price         int64                  `protobuf:"varint,2,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PricePeriod) Reset() {
+	*x = PricePeriod{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PricePeriod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PricePeriod) ProtoMessage() {}
+
+func (x *PricePeriod) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PricePeriod.ProtoReflect.Descriptor instead.
+func (*PricePeriod) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PricePeriod) GetEffectiveFrom() *MonthYear {
+	if x != nil {
+		return x.EffectiveFrom
+	}
+	return nil
+}
+
+func (x *PricePeriod) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+// Promo — вводная цена на months месяцев после пробного периода: скидка
+// value процентов (type "percentage") или фиксированная цена value ("fixed").
+type Promo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Value         int64                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	Months        int32                  `protobuf:"varint,3,opt,name=months,proto3" json:"months,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Promo) Reset() {
+	*x = Promo{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Promo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Promo) ProtoMessage() {}
+
+func (x *Promo) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Promo.ProtoReflect.Descriptor instead.
+func (*Promo) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Promo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Promo) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Promo) GetMonths() int32 {
+	if x != nil {
+		return x.Months
+	}
+	return 0
+}
+
+type Subscription struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ServiceName string                 `protobuf:"bytes,2,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	// Цена текущего месяца, история - в prices.
+	Price     int64          `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
+	UserId    string         `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	StartDate *MonthYear     `protobuf:"bytes,5,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate   *MonthYear     `protobuf:"bytes,6,opt,name=end_date,json=endDate,proto3,oneof" json:"end_date,omitempty"`
+	Category  string         `protobuf:"bytes,7,opt,name=category,proto3" json:"category,omitempty"`
+	Tags      []string       `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`
+	Prices    []*PricePeriod `protobuf:"bytes,9,rep,name=prices,proto3" json:"prices,omitempty"`
+	// Последний бесплатный месяц.
+	TrialEnd      *MonthYear `protobuf:"bytes,10,opt,name=trial_end,json=trialEnd,proto3,oneof" json:"trial_end,omitempty"`
+	Promo         *Promo     `protobuf:"bytes,11,opt,name=promo,proto3,oneof" json:"promo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Subscription) Reset() {
+	*x = Subscription{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Subscription) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Subscription) ProtoMessage() {}
+
+func (x *Subscription) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Subscription.ProtoReflect.Descriptor instead.
+func (*Subscription) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Subscription) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Subscription) GetServiceName() string {
+	if x != nil {
+		return x.ServiceName
+	}
+	return ""
+}
+
+func (x *Subscription) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Subscription) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Subscription) GetStartDate() *MonthYear {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *Subscription) GetEndDate() *MonthYear {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+func (x *Subscription) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Subscription) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Subscription) GetPrices() []*PricePeriod {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+func (x *Subscription) GetTrialEnd() *MonthYear {
+	if x != nil {
+		return x.TrialEnd
+	}
+	return nil
+}
+
+func (x *Subscription) GetPromo() *Promo {
+	if x != nil {
+		return x.Promo
+	}
+	return nil
+}
+
+type CreateSubscriptionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Если id не указан, он будет сгенерирован.
+	Id          string     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ServiceName string     `protobuf:"bytes,2,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	Price       int64      `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
+	UserId      string     `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	StartDate   *MonthYear `protobuf:"bytes,5,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate     *MonthYear `protobuf:"bytes,6,opt,name=end_date,json=endDate,proto3,oneof" json:"end_date,omitempty"`
+	// Без category берется категория из каталога сервисов.
+	Category      string     `protobuf:"bytes,7,opt,name=category,proto3" json:"category,omitempty"`
+	Tags          []string   `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`
+	TrialEnd      *MonthYear `protobuf:"bytes,9,opt,name=trial_end,json=trialEnd,proto3,oneof" json:"trial_end,omitempty"`
+	Promo         *Promo     `protobuf:"bytes,10,opt,name=promo,proto3,oneof" json:"promo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSubscriptionRequest) Reset() {
+	*x = CreateSubscriptionRequest{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSubscriptionRequest) ProtoMessage() {}
+
+func (x *CreateSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*CreateSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateSubscriptionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CreateSubscriptionRequest) GetServiceName() string {
+	if x != nil {
+		return x.ServiceName
+	}
+	return ""
+}
+
+func (x *CreateSubscriptionRequest) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *CreateSubscriptionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateSubscriptionRequest) GetStartDate() *MonthYear {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *CreateSubscriptionRequest) GetEndDate() *MonthYear {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+func (x *CreateSubscriptionRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *CreateSubscriptionRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *CreateSubscriptionRequest) GetTrialEnd() *MonthYear {
+	if x != nil {
+		return x.TrialEnd
+	}
+	return nil
+}
+
+func (x *CreateSubscriptionRequest) GetPromo() *Promo {
+	if x != nil {
+		return x.Promo
+	}
+	return nil
+}
+
+// TagList отличает замену тегов пустым списком от отсутствия изменений.
+type TagList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []string               `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagList) Reset() {
+	*x = TagList{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagList) ProtoMessage() {}
+
+func (x *TagList) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagList.ProtoReflect.Descriptor instead.
+func (*TagList) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TagList) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type GetSubscriptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSubscriptionRequest) Reset() {
+	*x = GetSubscriptionRequest{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSubscriptionRequest) ProtoMessage() {}
+
+func (x *GetSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*GetSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetSubscriptionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// UpdateSubscriptionRequest изменяет только переданные поля.
+type UpdateSubscriptionRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ServiceName *string                `protobuf:"bytes,2,opt,name=service_name,json=serviceName,proto3,oneof" json:"service_name,omitempty"`
+	Price       *int64                 `protobuf:"varint,3,opt,name=price,proto3,oneof" json:"price,omitempty"`
+	UserId      *string                `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3,oneof" json:"user_id,omitempty"`
+	StartDate   *MonthYear             `protobuf:"bytes,5,opt,name=start_date,json=startDate,proto3,oneof" json:"start_date,omitempty"`
+	EndDate     *MonthYear             `protobuf:"bytes,6,opt,name=end_date,json=endDate,proto3,oneof" json:"end_date,omitempty"`
+	// Сбросить end_date, сделав подписку бессрочной.
+	ClearEndDate bool `protobuf:"varint,7,opt,name=clear_end_date,json=clearEndDate,proto3" json:"clear_end_date,omitempty"`
+	// Пустая category возвращает категорию из каталога.
+	Category *string `protobuf:"bytes,8,opt,name=category,proto3,oneof" json:"category,omitempty"`
+	// Заменяет теги целиком, пустой список убирает все теги.
+	Tags *TagList `protobuf:"bytes,9,opt,name=tags,proto3,oneof" json:"tags,omitempty"`
+	// Месяц, с которого действует новая price; без него - текущий.
+	EffectiveFrom *MonthYear `protobuf:"bytes,10,opt,name=effective_from,json=effectiveFrom,proto3,oneof" json:"effective_from,omitempty"`
+	TrialEnd      *MonthYear `protobuf:"bytes,11,opt,name=trial_end,json=trialEnd,proto3,oneof" json:"trial_end,omitempty"`
+	// Убрать пробный период.
+	ClearTrialEnd bool   `protobuf:"varint,12,opt,name=clear_trial_end,json=clearTrialEnd,proto3" json:"clear_trial_end,omitempty"`
+	Promo         *Promo `protobuf:"bytes,13,opt,name=promo,proto3,oneof" json:"promo,omitempty"`
+	// Убрать промо.
+	ClearPromo    bool `protobuf:"varint,14,opt,name=clear_promo,json=clearPromo,proto3" json:"clear_promo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSubscriptionRequest) Reset() {
+	*x = UpdateSubscriptionRequest{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSubscriptionRequest) ProtoMessage() {}
+
+func (x *UpdateSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateSubscriptionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateSubscriptionRequest) GetServiceName() string {
+	if x != nil && x.ServiceName != nil {
+		return *x.ServiceName
+	}
+	return ""
+}
+
+func (x *UpdateSubscriptionRequest) GetPrice() int64 {
+	if x != nil && x.Price != nil {
+		return *x.Price
+	}
+	return 0
+}
+
+func (x *UpdateSubscriptionRequest) GetUserId() string {
+	if x != nil && x.UserId != nil {
+		return *x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateSubscriptionRequest) GetStartDate() *MonthYear {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *UpdateSubscriptionRequest) GetEndDate() *MonthYear {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+func (x *UpdateSubscriptionRequest) GetClearEndDate() bool {
+	if x != nil {
+		return x.ClearEndDate
+	}
+	return false
+}
+
+func (x *UpdateSubscriptionRequest) GetCategory() string {
+	if x != nil && x.Category != nil {
+		return *x.Category
+	}
+	return ""
+}
+
+func (x *UpdateSubscriptionRequest) GetTags() *TagList {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UpdateSubscriptionRequest) GetEffectiveFrom() *MonthYear {
+	if x != nil {
+		return x.EffectiveFrom
+	}
+	return nil
+}
+
+func (x *UpdateSubscriptionRequest) GetTrialEnd() *MonthYear {
+	if x != nil {
+		return x.TrialEnd
+	}
+	return nil
+}
+
+func (x *UpdateSubscriptionRequest) GetClearTrialEnd() bool {
+	if x != nil {
+		return x.ClearTrialEnd
+	}
+	return false
+}
+
+func (x *UpdateSubscriptionRequest) GetPromo() *Promo {
+	if x != nil {
+		return x.Promo
+	}
+	return nil
+}
+
+func (x *UpdateSubscriptionRequest) GetClearPromo() bool {
+	if x != nil {
+		return x.ClearPromo
+	}
+	return false
+}
+
+type DeleteSubscriptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSubscriptionRequest) Reset() {
+	*x = DeleteSubscriptionRequest{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSubscriptionRequest) ProtoMessage() {}
+
+func (x *DeleteSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteSubscriptionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// DeletePriceRequest отменяет изменение цены, начинающееся с month.
+type DeletePriceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Month         *MonthYear             `protobuf:"bytes,2,opt,name=month,proto3" json:"month,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePriceRequest) Reset() {
+	*x = DeletePriceRequest{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePriceRequest) ProtoMessage() {}
+
+func (x *DeletePriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePriceRequest.ProtoReflect.Descriptor instead.
+func (*DeletePriceRequest) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeletePriceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeletePriceRequest) GetMonth() *MonthYear {
+	if x != nil {
+		return x.Month
+	}
+	return nil
+}
+
+// TrialsEndingRequest выбирает подписки, пробный период которых
+// заканчивается в month (по умолчанию - текущем месяце).
+type TrialsEndingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Month         *MonthYear             `protobuf:"bytes,1,opt,name=month,proto3,oneof" json:"month,omitempty"`
+	UserIds       []string               `protobuf:"bytes,2,rep,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrialsEndingRequest) Reset() {
+	*x = TrialsEndingRequest{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrialsEndingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrialsEndingRequest) ProtoMessage() {}
+
+func (x *TrialsEndingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrialsEndingRequest.ProtoReflect.Descriptor instead.
+func (*TrialsEndingRequest) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TrialsEndingRequest) GetMonth() *MonthYear {
+	if x != nil {
+		return x.Month
+	}
+	return nil
+}
+
+func (x *TrialsEndingRequest) GetUserIds() []string {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+type ListSubscriptionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      string                 `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	Tag           string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscriptionsRequest) Reset() {
+	*x = ListSubscriptionsRequest{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscriptionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscriptionsRequest) ProtoMessage() {}
+
+func (x *ListSubscriptionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscriptionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSubscriptionsRequest) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListSubscriptionsRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ListSubscriptionsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type ListSubscriptionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscriptions []*Subscription        `protobuf:"bytes,1,rep,name=subscriptions,proto3" json:"subscriptions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscriptionsResponse) Reset() {
+	*x = ListSubscriptionsResponse{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscriptionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscriptionsResponse) ProtoMessage() {}
+
+func (x *ListSubscriptionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscriptionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSubscriptionsResponse) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListSubscriptionsResponse) GetSubscriptions() []*Subscription {
+	if x != nil {
+		return x.Subscriptions
+	}
+	return nil
+}
+
+type SumSubscriptionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartDate     *MonthYear             `protobuf:"bytes,1,opt,name=start_date,json=startDate,proto3,oneof" json:"start_date,omitempty"`
+	EndDate       *MonthYear             `protobuf:"bytes,2,opt,name=end_date,json=endDate,proto3,oneof" json:"end_date,omitempty"`
+	ServiceName   string                 `protobuf:"bytes,3,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	UserIds       []string               `protobuf:"bytes,4,rep,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	Category      string                 `protobuf:"bytes,5,opt,name=category,proto3" json:"category,omitempty"`
+	Tag           string                 `protobuf:"bytes,6,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SumSubscriptionsRequest) Reset() {
+	*x = SumSubscriptionsRequest{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SumSubscriptionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SumSubscriptionsRequest) ProtoMessage() {}
+
+func (x *SumSubscriptionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SumSubscriptionsRequest.ProtoReflect.Descriptor instead.
+func (*SumSubscriptionsRequest) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SumSubscriptionsRequest) GetStartDate() *MonthYear {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *SumSubscriptionsRequest) GetEndDate() *MonthYear {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+func (x *SumSubscriptionsRequest) GetServiceName() string {
+	if x != nil {
+		return x.ServiceName
+	}
+	return ""
+}
+
+func (x *SumSubscriptionsRequest) GetUserIds() []string {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+func (x *SumSubscriptionsRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *SumSubscriptionsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type SumSubscriptionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sum           int64                  `protobuf:"varint,1,opt,name=sum,proto3" json:"sum,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SumSubscriptionsResponse) Reset() {
+	*x = SumSubscriptionsResponse{}
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SumSubscriptionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SumSubscriptionsResponse) ProtoMessage() {}
+
+func (x *SumSubscriptionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_subscriptions_v1_subscriptions_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SumSubscriptionsResponse.ProtoReflect.Descriptor instead.
+func (*SumSubscriptionsResponse) Descriptor() ([]byte, []int) {
+	return file_subscriptions_v1_subscriptions_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SumSubscriptionsResponse) GetSum() int64 {
+	if x != nil {
+		return x.Sum
+	}
+	return 0
+}
+
+var File_subscriptions_v1_subscriptions_proto protoreflect.FileDescriptor
+
+const file_subscriptions_v1_subscriptions_proto_rawDesc = "" +
+	"\n" +
+	"$subscriptions/v1/subscriptions.proto\x12\x10subscriptions.v1\x1a\x1bgoogle/protobuf/empty.proto\"5\n" +
+	"\tMonthYear\x12\x12\n" +
+	"\x04year\x18\x01 \x01(\x05R\x04year\x12\x14\n" +
+	"\x05month\x18\x02 \x01(\x05R\x05month\"g\n" +
+	"\vPricePeriod\x12B\n" +
+	"\x0eeffective_from\x18\x01 \x01(\v2\x1b.subscriptions.v1.MonthYearR\reffectiveFrom\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x03R\x05price\"I\n" +
+	"\x05Promo\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value\x12\x16\n" +
+	"\x06months\x18\x03 \x01(\x05R\x06months\"\xe8\x03\n" +
+	"\fSubscription\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fservice_name\x18\x02 \x01(\tR\vserviceName\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x03R\x05price\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\x12:\n" +
+	"\n" +
+	"start_date\x18\x05 \x01(\v2\x1b.subscriptions.v1.MonthYearR\tstartDate\x12;\n" +
+	"\bend_date\x18\x06 \x01(\v2\x1b.subscriptions.v1.MonthYearH\x00R\aendDate\x88\x01\x01\x12\x1a\n" +
+	"\bcategory\x18\a \x01(\tR\bcategory\x12\x12\n" +
+	"\x04tags\x18\b \x03(\tR\x04tags\x125\n" +
+	"\x06prices\x18\t \x03(\v2\x1d.subscriptions.v1.PricePeriodR\x06prices\x12=\n" +
+	"\ttrial_end\x18\n" +
+	" \x01(\v2\x1b.subscriptions.v1.MonthYearH\x01R\btrialEnd\x88\x01\x01\x122\n" +
+	"\x05promo\x18\v \x01(\v2\x17.subscriptions.v1.PromoH\x02R\x05promo\x88\x01\x01B\v\n" +
+	"\t_end_dateB\f\n" +
+	"\n" +
+	"_trial_endB\b\n" +
+	"\x06_promo\"\xbe\x03\n" +
+	"\x19CreateSubscriptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fservice_name\x18\x02 \x01(\tR\vserviceName\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x03R\x05price\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\x12:\n" +
+	"\n" +
+	"start_date\x18\x05 \x01(\v2\x1b.subscriptions.v1.MonthYearR\tstartDate\x12;\n" +
+	"\bend_date\x18\x06 \x01(\v2\x1b.subscriptions.v1.MonthYearH\x00R\aendDate\x88\x01\x01\x12\x1a\n" +
+	"\bcategory\x18\a \x01(\tR\bcategory\x12\x12\n" +
+	"\x04tags\x18\b \x03(\tR\x04tags\x12=\n" +
+	"\ttrial_end\x18\t \x01(\v2\x1b.subscriptions.v1.MonthYearH\x01R\btrialEnd\x88\x01\x01\x122\n" +
+	"\x05promo\x18\n" +
+	" \x01(\v2\x17.subscriptions.v1.PromoH\x02R\x05promo\x88\x01\x01B\v\n" +
+	"\t_end_dateB\f\n" +
+	"\n" +
+	"_trial_endB\b\n" +
+	"\x06_promo\"\x1d\n" +
+	"\aTagList\x12\x12\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\"(\n" +
+	"\x16GetSubscriptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x8e\x06\n" +
+	"\x19UpdateSubscriptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12&\n" +
+	"\fservice_name\x18\x02 \x01(\tH\x00R\vserviceName\x88\x01\x01\x12\x19\n" +
+	"\x05price\x18\x03 \x01(\x03H\x01R\x05price\x88\x01\x01\x12\x1c\n" +
+	"\auser_id\x18\x04 \x01(\tH\x02R\x06userId\x88\x01\x01\x12?\n" +
+	"\n" +
+	"start_date\x18\x05 \x01(\v2\x1b.subscriptions.v1.MonthYearH\x03R\tstartDate\x88\x01\x01\x12;\n" +
+	"\bend_date\x18\x06 \x01(\v2\x1b.subscriptions.v1.MonthYearH\x04R\aendDate\x88\x01\x01\x12$\n" +
+	"\x0eclear_end_date\x18\a \x01(\bR\fclearEndDate\x12\x1f\n" +
+	"\bcategory\x18\b \x01(\tH\x05R\bcategory\x88\x01\x01\x122\n" +
+	"\x04tags\x18\t \x01(\v2\x19.subscriptions.v1.TagListH\x06R\x04tags\x88\x01\x01\x12G\n" +
+	"\x0eeffective_from\x18\n" +
+	" \x01(\v2\x1b.subscriptions.v1.MonthYearH\aR\reffectiveFrom\x88\x01\x01\x12=\n" +
+	"\ttrial_end\x18\v \x01(\v2\x1b.subscriptions.v1.MonthYearH\bR\btrialEnd\x88\x01\x01\x12&\n" +
+	"\x0fclear_trial_end\x18\f \x01(\bR\rclearTrialEnd\x122\n" +
+	"\x05promo\x18\r \x01(\v2\x17.subscriptions.v1.PromoH\tR\x05promo\x88\x01\x01\x12\x1f\n" +
+	"\vclear_promo\x18\x0e \x01(\bR\n" +
+	"clearPromoB\x0f\n" +
+	"\r_service_nameB\b\n" +
+	"\x06_priceB\n" +
+	"\n" +
+	"\b_user_idB\r\n" +
+	"\v_start_dateB\v\n" +
+	"\t_end_dateB\v\n" +
+	"\t_categoryB\a\n" +
+	"\x05_tagsB\x11\n" +
+	"\x0f_effective_fromB\f\n" +
+	"\n" +
+	"_trial_endB\b\n" +
+	"\x06_promo\"+\n" +
+	"\x19DeleteSubscriptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"W\n" +
+	"\x12DeletePriceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x121\n" +
+	"\x05month\x18\x02 \x01(\v2\x1b.subscriptions.v1.MonthYearR\x05month\"r\n" +
+	"\x13TrialsEndingRequest\x126\n" +
+	"\x05month\x18\x01 \x01(\v2\x1b.subscriptions.v1.MonthYearH\x00R\x05month\x88\x01\x01\x12\x19\n" +
+	"\buser_ids\x18\x02 \x03(\tR\auserIdsB\b\n" +
+	"\x06_month\"H\n" +
+	"\x18ListSubscriptionsRequest\x12\x1a\n" +
+	"\bcategory\x18\x01 \x01(\tR\bcategory\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\"a\n" +
+	"\x19ListSubscriptionsResponse\x12D\n" +
+	"\rsubscriptions\x18\x01 \x03(\v2\x1e.subscriptions.v1.SubscriptionR\rsubscriptions\"\x9f\x02\n" +
+	"\x17SumSubscriptionsRequest\x12?\n" +
+	"\n" +
+	"start_date\x18\x01 \x01(\v2\x1b.subscriptions.v1.MonthYearH\x00R\tstartDate\x88\x01\x01\x12;\n" +
+	"\bend_date\x18\x02 \x01(\v2\x1b.subscriptions.v1.MonthYearH\x01R\aendDate\x88\x01\x01\x12!\n" +
+	"\fservice_name\x18\x03 \x01(\tR\vserviceName\x12\x19\n" +
+	"\buser_ids\x18\x04 \x03(\tR\auserIds\x12\x1a\n" +
+	"\bcategory\x18\x05 \x01(\tR\bcategory\x12\x10\n" +
+	"\x03tag\x18\x06 \x01(\tR\x03tagB\r\n" +
+	"\v_start_dateB\v\n" +
+	"\t_end_date\",\n" +
+	"\x18SumSubscriptionsResponse\x12\x10\n" +
+	"\x03sum\x18\x01 \x01(\x03R\x03sum2\xa5\x06\n" +
+	"\x13SubscriptionService\x12a\n" +
+	"\x12CreateSubscription\x12+.subscriptions.v1.CreateSubscriptionRequest\x1a\x1e.subscriptions.v1.Subscription\x12[\n" +
+	"\x0fGetSubscription\x12(.subscriptions.v1.GetSubscriptionRequest\x1a\x1e.subscriptions.v1.Subscription\x12a\n" +
+	"\x12UpdateSubscription\x12+.subscriptions.v1.UpdateSubscriptionRequest\x1a\x1e.subscriptions.v1.Subscription\x12Y\n" +
+	"\x12DeleteSubscription\x12+.subscriptions.v1.DeleteSubscriptionRequest\x1a\x16.google.protobuf.Empty\x12l\n" +
+	"\x11ListSubscriptions\x12*.subscriptions.v1.ListSubscriptionsRequest\x1a+.subscriptions.v1.ListSubscriptionsResponse\x12i\n" +
+	"\x10SumSubscriptions\x12).subscriptions.v1.SumSubscriptionsRequest\x1a*.subscriptions.v1.SumSubscriptionsResponse\x12S\n" +
+	"\vDeletePrice\x12$.subscriptions.v1.DeletePriceRequest\x1a\x1e.subscriptions.v1.Subscription\x12b\n" +
+	"\fTrialsEnding\x12%.subscriptions.v1.TrialsEndingRequest\x1a+.subscriptions.v1.ListSubscriptionsResponseBMZKgithub.com/ekkserapopova/subscriptions/api/subscriptions/v1;subscriptionsv1b\x06proto3"
+
+var (
+	file_subscriptions_v1_subscriptions_proto_rawDescOnce sync.Once
+	file_subscriptions_v1_subscriptions_proto_rawDescData []byte
+)
+
+func file_subscriptions_v1_subscriptions_proto_rawDescGZIP() []byte {
+	file_subscriptions_v1_subscriptions_proto_rawDescOnce.Do(func() {
+		file_subscriptions_v1_subscriptions_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_subscriptions_v1_subscriptions_proto_rawDesc), len(file_subscriptions_v1_subscriptions_proto_rawDesc)))
+	})
+	return file_subscriptions_v1_subscriptions_proto_rawDescData
+}
+
+var file_subscriptions_v1_subscriptions_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_subscriptions_v1_subscriptions_proto_goTypes = []any{
+	(*MonthYear)(nil),                 // 0: subscriptions.v1.MonthYear
+	(*PricePeriod)(nil),               // 1: subscriptions.v1.PricePeriod
+	(*Promo)(nil),                     // 2: subscriptions.v1.Promo
+	(*Subscription)(nil),              // 3: subscriptions.v1.Subscription
+	(*CreateSubscriptionRequest)(nil), // 4: subscriptions.v1.CreateSubscriptionRequest
+	(*TagList)(nil),                   // 5: subscriptions.v1.TagList
+	(*GetSubscriptionRequest)(nil),    // 6: subscriptions.v1.GetSubscriptionRequest
+	(*UpdateSubscriptionRequest)(nil), // 7: subscriptions.v1.UpdateSubscriptionRequest
+	(*DeleteSubscriptionRequest)(nil), // 8: subscriptions.v1.DeleteSubscriptionRequest
+	(*DeletePriceRequest)(nil),        // 9: subscriptions.v1.DeletePriceRequest
+	(*TrialsEndingRequest)(nil),       // 10: subscriptions.v1.TrialsEndingRequest
+	(*ListSubscriptionsRequest)(nil),  // 11: subscriptions.v1.ListSubscriptionsRequest
+	(*ListSubscriptionsResponse)(nil), // 12: subscriptions.v1.ListSubscriptionsResponse
+	(*SumSubscriptionsRequest)(nil),   // 13: subscriptions.v1.SumSubscriptionsRequest
+	(*SumSubscriptionsResponse)(nil),  // 14: subscriptions.v1.SumSubscriptionsResponse
+	(*emptypb.Empty)(nil),             // 15: google.protobuf.Empty
+}
+var file_subscriptions_v1_subscriptions_proto_depIdxs = []int32{
+	0,  // 0: subscriptions.v1.PricePeriod.effective_from:type_name -> subscriptions.v1.MonthYear
+	0,  // 1: subscriptions.v1.Subscription.start_date:type_name -> subscriptions.v1.MonthYear
+	0,  // 2: subscriptions.v1.Subscription.end_date:type_name -> subscriptions.v1.MonthYear
+	1,  // 3: subscriptions.v1.Subscription.prices:type_name -> subscriptions.v1.PricePeriod
+	0,  // 4: subscriptions.v1.Subscription.trial_end:type_name -> subscriptions.v1.MonthYear
+	2,  // 5: subscriptions.v1.Subscription.promo:type_name -> subscriptions.v1.Promo
+	0,  // 6: subscriptions.v1.CreateSubscriptionRequest.start_date:type_name -> subscriptions.v1.MonthYear
+	0,  // 7: subscriptions.v1.CreateSubscriptionRequest.end_date:type_name -> subscriptions.v1.MonthYear
+	0,  // 8: subscriptions.v1.CreateSubscriptionRequest.trial_end:type_name -> subscriptions.v1.MonthYear
+	2,  // 9: subscriptions.v1.CreateSubscriptionRequest.promo:type_name -> subscriptions.v1.Promo
+	0,  // 10: subscriptions.v1.UpdateSubscriptionRequest.start_date:type_name -> subscriptions.v1.MonthYear
+	0,  // 11: subscriptions.v1.UpdateSubscriptionRequest.end_date:type_name -> subscriptions.v1.MonthYear
+	5,  // 12: subscriptions.v1.UpdateSubscriptionRequest.tags:type_name -> subscriptions.v1.TagList
+	0,  // 13: subscriptions.v1.UpdateSubscriptionRequest.effective_from:type_name -> subscriptions.v1.MonthYear
+	0,  // 14: subscriptions.v1.UpdateSubscriptionRequest.trial_end:type_name -> subscriptions.v1.MonthYear
+	2,  // 15: subscriptions.v1.UpdateSubscriptionRequest.promo:type_name -> subscriptions.v1.Promo
+	0,  // 16: subscriptions.v1.DeletePriceRequest.month:type_name -> subscriptions.v1.MonthYear
+	0,  // 17: subscriptions.v1.TrialsEndingRequest.month:type_name -> subscriptions.v1.MonthYear
+	3,  // 18: subscriptions.v1.ListSubscriptionsResponse.subscriptions:type_name -> subscriptions.v1.Subscription
+	0,  // 19: subscriptions.v1.SumSubscriptionsRequest.start_date:type_name -> subscriptions.v1.MonthYear
+	0,  // 20: subscriptions.v1.SumSubscriptionsRequest.end_date:type_name -> subscriptions.v1.MonthYear
+	4,  // 21: subscriptions.v1.SubscriptionService.CreateSubscription:input_type -> subscriptions.v1.CreateSubscriptionRequest
+	6,  // 22: subscriptions.v1.SubscriptionService.GetSubscription:input_type -> subscriptions.v1.GetSubscriptionRequest
+	7,  // 23: subscriptions.v1.SubscriptionService.UpdateSubscription:input_type -> subscriptions.v1.UpdateSubscriptionRequest
+	8,  // 24: subscriptions.v1.SubscriptionService.DeleteSubscription:input_type -> subscriptions.v1.DeleteSubscriptionRequest
+	11, // 25: subscriptions.v1.SubscriptionService.ListSubscriptions:input_type -> subscriptions.v1.ListSubscriptionsRequest
+	13, // 26: subscriptions.v1.SubscriptionService.SumSubscriptions:input_type -> subscriptions.v1.SumSubscriptionsRequest
+	9,  // 27: subscriptions.v1.SubscriptionService.DeletePrice:input_type -> subscriptions.v1.DeletePriceRequest
+	10, // 28: subscriptions.v1.SubscriptionService.TrialsEnding:input_type -> subscriptions.v1.TrialsEndingRequest
+	3,  // 29: subscriptions.v1.SubscriptionService.CreateSubscription:output_type -> subscriptions.v1.Subscription
+	3,  // 30: subscriptions.v1.SubscriptionService.GetSubscription:output_type -> subscriptions.v1.Subscription
+	3,  // 31: subscriptions.v1.SubscriptionService.UpdateSubscription:output_type -> subscriptions.v1.Subscription
+	15, // 32: subscriptions.v1.SubscriptionService.DeleteSubscription:output_type -> google.protobuf.Empty
+	12, // 33: subscriptions.v1.SubscriptionService.ListSubscriptions:output_type -> subscriptions.v1.ListSubscriptionsResponse
+	14, // 34: subscriptions.v1.SubscriptionService.SumSubscriptions:output_type -> subscriptions.v1.SumSubscriptionsResponse
+	3,  // 35: subscriptions.v1.SubscriptionService.DeletePrice:output_type -> subscriptions.v1.Subscription
+	12, // 36: subscriptions.v1.SubscriptionService.TrialsEnding:output_type -> subscriptions.v1.ListSubscriptionsResponse
+	29, // [29:37] is the sub-list for method output_type
+	21, // [21:29] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
+}
+
+func init() { file_subscriptions_v1_subscriptions_proto_init() }
+func file_subscriptions_v1_subscriptions_proto_init() {
+	if File_subscriptions_v1_subscriptions_proto != nil {
+		return
+	}
+	file_subscriptions_v1_subscriptions_proto_msgTypes[3].OneofWrappers = []any{}
+	file_subscriptions_v1_subscriptions_proto_msgTypes[4].OneofWrappers = []any{}
+	file_subscriptions_v1_subscriptions_proto_msgTypes[7].OneofWrappers = []any{}
+	file_subscriptions_v1_subscriptions_proto_msgTypes[10].OneofWrappers = []any{}
+	file_subscriptions_v1_subscriptions_proto_msgTypes[13].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_subscriptions_v1_subscriptions_proto_rawDesc), len(file_subscriptions_v1_subscriptions_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_subscriptions_v1_subscriptions_proto_goTypes,
+		DependencyIndexes: file_subscriptions_v1_subscriptions_proto_depIdxs,
+		MessageInfos:      file_subscriptions_v1_subscriptions_proto_msgTypes,
+	}.Build()
+	File_subscriptions_v1_subscriptions_proto = out.File
+	file_subscriptions_v1_subscriptions_proto_goTypes = nil
+	file_subscriptions_v1_subscriptions_proto_depIdxs = nil
+}
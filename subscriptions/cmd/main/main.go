@@ -2,23 +2,53 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	subscriptionsv1 "github.com/ekkserapopova/subscriptions/api/subscriptions/v1"
 	"github.com/ekkserapopova/subscriptions/internal/config"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/cache"
 	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/grpcserver"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/health"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
 	"github.com/ekkserapopova/subscriptions/internal/pkg/migrations"
 	"github.com/ekkserapopova/subscriptions/internal/pkg/server"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tracing"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/catalog"
+	catalogHandler "github.com/ekkserapopova/subscriptions/internal/services/catalog/delivery/http"
+	catalogRepository "github.com/ekkserapopova/subscriptions/internal/services/catalog/repo"
+	catalogUseCase "github.com/ekkserapopova/subscriptions/internal/services/catalog/usecase"
+	"github.com/ekkserapopova/subscriptions/internal/services/households"
+	householdHandler "github.com/ekkserapopova/subscriptions/internal/services/households/delivery/http"
+	householdRepository "github.com/ekkserapopova/subscriptions/internal/services/households/repo"
+	householdUseCase "github.com/ekkserapopova/subscriptions/internal/services/households/usecase"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	subscriptionGraphQL "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/graphql"
+	subscriptionGRPCHandler "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/grpc"
 	subscriptionHandler "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/http"
+	subscriptionEvents "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
 	subscriptionRepository "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/repo"
 	subscriptionUseCase "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/usecase"
+	"github.com/ekkserapopova/subscriptions/internal/services/users"
+	userHandler "github.com/ekkserapopova/subscriptions/internal/services/users/delivery/http"
+	userRepository "github.com/ekkserapopova/subscriptions/internal/services/users/repo"
+	userUseCase "github.com/ekkserapopova/subscriptions/internal/services/users/usecase"
 	"github.com/ekkserapopova/subscriptions/pkg/builder"
 	"github.com/ekkserapopova/subscriptions/pkg/logger"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
+	"log"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
+// stopTimeout должен покрывать health.drainDelay и httpServer.shutdownTimeout.
+const stopTimeout = 30 * time.Second
+
 // @title Subscriptions API
 // @version 1.0
 // @description API для управления подписками
@@ -26,41 +56,218 @@ import (
 // @host localhost:8080
 // @BasePath /api/v1
 func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Printf("failed to load config: %s", err)
+		os.Exit(1)
+	}
+
+	if cfg.PrintConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			log.Printf("failed to print config: %s", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app := fx.New(
+		fx.Supply(cfg),
 		fx.Provide(
 			logger.SetupLogger,
 			builder.SetupBuilder,
-			config.MustLoad,
-
-			db.NewPostgresPool,
-			db.NewPostgresConnect,
+			config.Provide,
+			metrics.NewMetrics,
+			tracing.NewTracerProvider,
 
 			server.NewRouter,
+			health.NewHandler,
 
 			subscriptionHandler.NewHandler,
-			subscriptionUseCase.NewUseCase,
-			subscriptionRepository.NewRepository,
+			subscriptionGraphQL.NewHandler,
+			fx.Annotate(
+				subscriptionGRPCHandler.NewHandler,
+				fx.As(new(subscriptionsv1.SubscriptionServiceServer)),
+			),
+			fx.Annotate(
+				subscriptionUseCase.NewUseCase,
+				fx.As(new(subscriptions.UseCase)),
+			),
+			subscriptionEvents.NewBroker,
+
+			userHandler.NewHandler,
+			fx.Annotate(
+				userUseCase.NewUseCase,
+				fx.As(new(users.UseCase)),
+			),
+
+			householdHandler.NewHandler,
+			fx.Annotate(
+				householdUseCase.NewUseCase,
+				fx.As(new(households.UseCase)),
+			),
+
+			catalogHandler.NewHandler,
+			fx.Annotate(
+				catalogUseCase.NewUseCase,
+				fx.As(new(catalog.UseCase)),
+			),
 		),
 
+		storage(cfg.Storage),
+		caching(cfg.Cache.Backend),
+
+		fx.StopTimeout(stopTimeout),
+
 		fx.WithLogger(func(logger *slog.Logger) fxevent.Logger {
 			return &fxevent.SlogLogger{Logger: logger}
 		}),
 
 		fx.Invoke(
+			config.RunReloader,
 			server.RunServer,
-			migrations.RunMigrations,
+			grpcserver.RunServer,
+			subscriptionUseCase.RunStatsRefresher,
+			health.RunDrain,
 		),
 	)
 
-	ctx := context.Background()
+	startCtx, cancelStart := context.WithTimeout(context.Background(), app.StartTimeout())
+	defer cancelStart()
+
+	if err := app.Start(startCtx); err != nil {
+		log.Printf("failed to start application: %s", err)
+		os.Exit(1)
+	}
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
 
-	if err := app.Start(ctx); err != nil {
-		panic(err)
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), app.StopTimeout())
+	defer cancelStop()
+
+	if err := app.Stop(stopCtx); err != nil {
+		log.Printf("failed to stop application gracefully: %s", err)
+		os.Exit(1)
 	}
+}
 
-	<-stop
-	app.Stop(ctx)
+// storage подключает хранилище подписок и зависящие от него компоненты.
+func storage(kind string) fx.Option {
+	switch kind {
+	case config.StorageSQLite:
+		return fx.Options(
+			fx.Provide(
+				db.NewSQLite,
+				fx.Annotate(
+					subscriptionRepository.NewSQLiteRepository,
+					fx.As(new(subscriptions.Repository)),
+				),
+				fx.Annotate(
+					userRepository.NewSQLiteRepository,
+					fx.As(new(users.Repository)),
+				),
+				fx.Annotate(
+					householdRepository.NewSQLiteRepository,
+					fx.As(new(households.Repository)),
+					fx.As(new(subscriptions.SplitRepository)),
+				),
+				fx.Annotate(
+					catalogRepository.NewSQLiteRepository,
+					fx.As(new(catalog.Repository)),
+					fx.As(new(subscriptions.ServiceCatalog)),
+				),
+				fx.Annotate(
+					db.NewSQLiteTxManager,
+					fx.As(new(tx.Manager)),
+				),
+				fx.Annotate(
+					subscriptionEvents.NewLocalPublisher,
+					fx.As(new(subscriptionEvents.Publisher)),
+				),
+			),
+			fx.Invoke(migrations.RunSQLiteMigrations),
+		)
+	case config.StorageMemory:
+		return fx.Provide(
+			fx.Annotate(
+				subscriptionRepository.NewMemoryRepository,
+				fx.As(new(subscriptions.Repository)),
+				fx.As(new(tx.Manager)),
+			),
+			fx.Annotate(
+				userRepository.NewMemoryRepository,
+				fx.As(new(users.Repository)),
+				fx.As(new(subscriptionRepository.UserChecker)),
+				fx.As(new(householdRepository.UserChecker)),
+			),
+			fx.Annotate(
+				householdRepository.NewMemoryRepository,
+				fx.As(new(households.Repository)),
+				fx.As(new(subscriptions.SplitRepository)),
+			),
+			fx.Annotate(
+				catalogRepository.NewMemoryRepository,
+				fx.As(new(catalog.Repository)),
+				fx.As(new(subscriptions.ServiceCatalog)),
+			),
+			fx.Annotate(
+				subscriptionEvents.NewLocalPublisher,
+				fx.As(new(subscriptionEvents.Publisher)),
+			),
+		)
+	}
+
+	return fx.Options(
+		fx.Provide(
+			db.NewPostgresPool,
+			db.NewCluster,
+			fx.Annotate(
+				subscriptionRepository.NewRepository,
+				fx.As(new(subscriptions.Repository)),
+			),
+			fx.Annotate(
+				userRepository.NewRepository,
+				fx.As(new(users.Repository)),
+			),
+			fx.Annotate(
+				householdRepository.NewRepository,
+				fx.As(new(households.Repository)),
+				fx.As(new(subscriptions.SplitRepository)),
+			),
+			fx.Annotate(
+				catalogRepository.NewRepository,
+				fx.As(new(catalog.Repository)),
+				fx.As(new(subscriptions.ServiceCatalog)),
+			),
+			fx.Annotate(
+				db.NewPostgresTxManager,
+				fx.As(new(tx.Manager)),
+			),
+			fx.Annotate(
+				subscriptionEvents.NewPostgresPublisher,
+				fx.As(new(subscriptionEvents.Publisher)),
+			),
+		),
+		fx.Invoke(
+			migrations.RunMigrations,
+			subscriptionEvents.RunListener,
+			metrics.RegisterPoolCollector,
+		),
+	)
+}
+
+// caching оборачивает репозиторий кэшем, если он включен в конфиге.
+func caching(backend string) fx.Option {
+	if backend == cache.BackendNone {
+		return fx.Options()
+	}
+
+	return fx.Options(
+		fx.Provide(cache.NewBackend),
+		fx.Decorate(subscriptionRepository.NewCachedRepository),
+	)
 }
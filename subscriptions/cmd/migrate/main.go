@@ -0,0 +1,238 @@
+// Команда migrate управляет схемой базы вне сервера:
+//
+//	migrate [--config path] up [N]
+//	migrate [--config path] down [N]
+//	migrate [--config path] goto V
+//	migrate [--config path] force V
+//	migrate [--config path] version
+//	migrate [--config path] status
+//
+// Настройки подключения берутся из того же конфига, что и у сервера;
+// при storage: sqlite команды применяются к файлу SQLite и его схеме.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/config"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	_ "modernc.org/sqlite"
+)
+
+const usage = `usage: migrate [--config path] <command> [arg]
+
+commands:
+  up [N]     apply all or N pending migrations
+  down [N]   roll back all or N applied migrations
+  goto V     migrate up or down to version V
+  force V    set version V without running migrations and clear dirty flag
+  version    print current version
+  status     list embedded migrations and whether they are applied
+`
+
+func main() {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to YAML config (overrides CONFIG_PATH)")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var configArgs []string
+	if *configPath != "" {
+		configArgs = []string{"--config", *configPath}
+	}
+
+	cfg, err := config.Load(configArgs)
+	if err != nil {
+		log.Printf("failed to load config: %s", err)
+		os.Exit(1)
+	}
+
+	if err := run(cfg, fs.Args()); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+}
+
+func run(cfg *config.Config, args []string) error {
+	action, err := parseCommand(args)
+	if err != nil {
+		return err
+	}
+
+	m, closeDB, err := newMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	defer m.Close()
+
+	return action(m)
+}
+
+// parseCommand проверяет аргументы до подключения к базе, чтобы опечатка
+// в команде не ждала connectTimeout.
+func parseCommand(args []string) (func(*migrations.Migrator) error, error) {
+	command, arg := args[0], ""
+	if len(args) > 1 {
+		arg = args[1]
+	}
+	if len(args) > 2 {
+		return nil, errors.New("too many arguments\n\n" + usage)
+	}
+
+	switch command {
+	case "up":
+		if arg == "" {
+			return thenVersion((*migrations.Migrator).Up), nil
+		}
+		n, err := parseSteps(arg)
+		if err != nil {
+			return nil, err
+		}
+		return thenVersion(func(m *migrations.Migrator) error { return m.Steps(n) }), nil
+	case "down":
+		if arg == "" {
+			return thenVersion((*migrations.Migrator).Down), nil
+		}
+		n, err := parseSteps(arg)
+		if err != nil {
+			return nil, err
+		}
+		return thenVersion(func(m *migrations.Migrator) error { return m.Steps(-n) }), nil
+	case "goto":
+		version, err := strconv.ParseUint(arg, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", arg)
+		}
+		return thenVersion(func(m *migrations.Migrator) error { return m.Goto(uint(version)) }), nil
+	case "force":
+		version, err := strconv.Atoi(arg)
+		if err != nil || version < -1 {
+			return nil, fmt.Errorf("invalid version %q", arg)
+		}
+		return thenVersion(func(m *migrations.Migrator) error { return m.Force(version) }), nil
+	case "version":
+		if arg != "" {
+			return nil, errors.New("too many arguments\n\n" + usage)
+		}
+		return printVersion, nil
+	case "status":
+		if arg != "" {
+			return nil, errors.New("too many arguments\n\n" + usage)
+		}
+		return printStatus, nil
+	default:
+		return nil, fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+}
+
+// thenVersion печатает версию схемы после успешной миграции.
+func thenVersion(migrate func(*migrations.Migrator) error) func(*migrations.Migrator) error {
+	return func(m *migrations.Migrator) error {
+		if err := migrate(m); err != nil {
+			return err
+		}
+		return printVersion(m)
+	}
+}
+
+// newMigrator подключается к хранилищу из конфига. Возвращаемая функция
+// закрывает подключение после Migrator.Close.
+func newMigrator(cfg *config.Config) (*migrations.Migrator, func(), error) {
+	if cfg.Storage == config.StorageSQLite {
+		sqliteDB, err := sql.Open("sqlite", cfg.SQLite.DSN())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open SQLite: %w", err)
+		}
+		m, err := migrations.NewSQLite(sqliteDB)
+		if err != nil {
+			sqliteDB.Close()
+			return nil, nil, err
+		}
+		// Migrator.Close закрывает базу SQLite сам.
+		return m, func() {}, nil
+	}
+
+	poolConfig, err := cfg.DB.PoolConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse pool config: %w", err)
+	}
+	poolConfig.MaxConns = 2
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DB.ConnectTimeout)
+	defer cancel()
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	m, err := migrations.New(pool)
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+	return m, pool.Close, nil
+}
+
+func parseSteps(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid number of steps %q", arg)
+	}
+	return n, nil
+}
+
+func printVersion(m *migrations.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		fmt.Printf("version %d (dirty)\n", version)
+	} else {
+		fmt.Printf("version %d\n", version)
+	}
+	return nil
+}
+
+func printStatus(m *migrations.Migrator) error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATUS")
+	for _, s := range statuses {
+		status := "pending"
+		switch {
+		case s.Dirty:
+			status = "dirty"
+		case s.Applied:
+			status = "applied"
+		}
+		fmt.Fprintf(w, "%d\t%s\n", s.Version, status)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"database/sql"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/migrations"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "up", args: []string{"up"}},
+		{name: "up N", args: []string{"up", "2"}},
+		{name: "up без числа шагов", args: []string{"up", "two"}, err: `invalid number of steps "two"`},
+		{name: "up ноль шагов", args: []string{"up", "0"}, err: `invalid number of steps "0"`},
+		{name: "down", args: []string{"down"}},
+		{name: "down N", args: []string{"down", "1"}},
+		{name: "down отрицательное число шагов", args: []string{"down", "-1"}, err: `invalid number of steps "-1"`},
+		{name: "goto", args: []string{"goto", "3"}},
+		{name: "goto без версии", args: []string{"goto"}, err: `invalid version ""`},
+		{name: "goto отрицательная версия", args: []string{"goto", "-1"}, err: `invalid version "-1"`},
+		{name: "force", args: []string{"force", "3"}},
+		{name: "force -1", args: []string{"force", "-1"}},
+		{name: "force без версии", args: []string{"force"}, err: `invalid version ""`},
+		{name: "force меньше -1", args: []string{"force", "-2"}, err: `invalid version "-2"`},
+		{name: "force не число", args: []string{"force", "v3"}, err: `invalid version "v3"`},
+		{name: "лишний аргумент", args: []string{"up", "1", "2"}, err: "too many arguments"},
+		{name: "version с аргументом", args: []string{"version", "1"}, err: "too many arguments"},
+		{name: "status с аргументом", args: []string{"status", "all"}, err: "too many arguments"},
+		{name: "неизвестная команда", args: []string{"migrate"}, err: `unknown command "migrate"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, err := parseCommand(tt.args)
+			if tt.err == "" {
+				if err != nil || action == nil {
+					t.Fatalf("parseCommand(%q) = %v", tt.args, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("parseCommand(%q) error = %v, want %q", tt.args, err, tt.err)
+			}
+		})
+	}
+}
+
+// TestCommands выполняет разобранные команды на SQLite по очереди.
+func TestCommands(t *testing.T) {
+	sqliteDB, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrations.NewSQLite(sqliteDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := statuses[len(statuses)-1].Version
+
+	steps := []struct {
+		args    []string
+		version uint
+	}{
+		{args: []string{"up", "2"}, version: 2},
+		{args: []string{"down", "1"}, version: 1},
+		{args: []string{"goto", "3"}, version: 3},
+		{args: []string{"up"}, version: latest},
+		{args: []string{"force", "2"}, version: 2},
+	}
+
+	for _, step := range steps {
+		action, err := parseCommand(step.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := action(m); err != nil {
+			t.Fatalf("%q: %v", step.args, err)
+		}
+		version, dirty, err := m.Version()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != step.version || dirty {
+			t.Errorf("%q: version = %d (dirty %v), want %d", step.args, version, dirty, step.version)
+		}
+	}
+}
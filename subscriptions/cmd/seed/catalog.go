@@ -0,0 +1,67 @@
+package main
+
+import (
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	serviceCatalog "github.com/ekkserapopova/subscriptions/internal/services/catalog"
+)
+
+const (
+	periodMonthly = 1
+	periodYearly  = 12
+)
+
+type plan struct {
+	name string
+	// price - цена за период оплаты в рублях.
+	price  int
+	period int
+}
+
+type service struct {
+	name  string
+	plans []plan
+	// weight задает относительную популярность сервиса.
+	weight int
+}
+
+// catalog - типичные подписки с ценами, близкими к реальным.
+var catalog = []service{
+	{"Yandex Plus", []plan{{"Мульти", 399, periodMonthly}, {"Мульти на год", 3990, periodYearly}}, 30},
+	{"Kinopoisk", []plan{{"Базовый", 299, periodMonthly}}, 12},
+	{"Okko", []plan{{"Оптимум", 399, periodMonthly}, {"Премиум", 699, periodMonthly}}, 10},
+	{"ivi", []plan{{"Подписка", 399, periodMonthly}, {"На год", 2990, periodYearly}}, 8},
+	{"Wink", []plan{{"Трансформер", 349, periodMonthly}}, 6},
+	{"START", []plan{{"Подписка", 299, periodMonthly}}, 5},
+	{"Amediateka", []plan{{"Подписка", 599, periodMonthly}}, 4},
+	{"VK Music", []plan{{"Подписка", 199, periodMonthly}, {"Семейная", 299, periodMonthly}}, 20},
+	{"Spotify", []plan{{"Individual", 169, periodMonthly}, {"Family", 269, periodMonthly}}, 7},
+	{"YouTube Premium", []plan{{"Individual", 299, periodMonthly}, {"Family", 449, periodMonthly}}, 12},
+	{"Netflix", []plan{{"Standard", 999, periodMonthly}, {"Premium", 1499, periodMonthly}}, 5},
+	{"Telegram Premium", []plan{{"Месяц", 299, periodMonthly}, {"Год", 2490, periodYearly}}, 15},
+	{"iCloud+", []plan{{"50 ГБ", 149, periodMonthly}, {"200 ГБ", 299, periodMonthly}, {"2 ТБ", 599, periodMonthly}}, 14},
+	{"Google One", []plan{{"100 ГБ", 139, periodMonthly}, {"2 ТБ", 699, periodMonthly}, {"2 ТБ на год", 6990, periodYearly}}, 9},
+	{"Microsoft 365", []plan{{"Personal", 549, periodMonthly}, {"Family на год", 6990, periodYearly}}, 6},
+	{"Dropbox", []plan{{"Plus", 990, periodMonthly}}, 3},
+	{"Notion", []plan{{"Plus", 800, periodMonthly}}, 4},
+	{"Figma", []plan{{"Professional", 1200, periodMonthly}}, 3},
+	{"ChatGPT", []plan{{"Plus", 2000, periodMonthly}}, 8},
+	{"GitHub Copilot", []plan{{"Individual", 1000, periodMonthly}, {"Individual на год", 10000, periodYearly}}, 4},
+	{"JetBrains", []plan{{"All Products на год", 24900, periodYearly}}, 2},
+	{"Adobe Creative Cloud", []plan{{"Photography", 990, periodMonthly}, {"All Apps", 5990, periodMonthly}}, 2},
+	{"Duolingo", []plan{{"Super", 499, periodMonthly}, {"Super на год", 3990, periodYearly}}, 6},
+	{"Litres", []plan{{"Абонемент", 399, periodMonthly}}, 5},
+	{"Storytel", []plan{{"Подписка", 499, periodMonthly}}, 3},
+	{"Strava", []plan{{"Subscription", 449, periodMonthly}}, 2},
+}
+
+// builtinService возвращает встроенный сервис каталога, который загружают
+// миграции, чтобы сгенерированные подписки были к нему привязаны.
+func builtinService(name string) *models.Service {
+	key := models.ServiceKey(name)
+	for i := range serviceCatalog.Builtin {
+		if models.ServiceKey(serviceCatalog.Builtin[i].Name) == key {
+			return &serviceCatalog.Builtin[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"io"
+	"strconv"
+	"time"
+)
+
+type fixtureWriter interface {
+	write(subs []*models.Subscription) error
+	close() error
+}
+
+func newFixtureWriter(w io.Writer, format string) (fixtureWriter, error) {
+	switch format {
+	case "json":
+		return &jsonWriter{w: w}, nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"id", "service_name", "service_id", "category", "price", "user_id", "start_date", "end_date"}); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	default:
+		return nil, fmt.Errorf("unknown fixture format %q", format)
+	}
+}
+
+// jsonWriter пишет массив потоково, по одной подписке на строку.
+type jsonWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (j *jsonWriter) write(subs []*models.Subscription) error {
+	for _, sub := range subs {
+		prefix := ",\n  "
+		if !j.written {
+			prefix = "[\n  "
+			j.written = true
+		}
+
+		data, err := json.Marshal(sub)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(j.w, prefix); err != nil {
+			return err
+		}
+		if _, err := j.w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *jsonWriter) close() error {
+	end := "\n]\n"
+	if !j.written {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) write(subs []*models.Subscription) error {
+	for _, sub := range subs {
+		endDate := ""
+		if sub.EndDate != nil {
+			endDate = sub.EndDate.Time().Format(monthLayout)
+		}
+
+		serviceID := ""
+		if sub.ServiceID != nil {
+			serviceID = sub.ServiceID.String()
+		}
+
+		price := ""
+		if sub.Price != nil {
+			price = strconv.Itoa(*sub.Price)
+		}
+
+		if err := c.w.Write([]string{
+			sub.ID.String(),
+			sub.ServiceName,
+			serviceID,
+			sub.Category,
+			price,
+			sub.UserID.String(),
+			sub.StartDate.Time().Format(monthLayout),
+			endDate,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+const monthLayout = "01-2006"
+
+func parseMonth(value string) (time.Time, error) {
+	t, err := time.Parse(monthLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %q, expected MM-YYYY", value)
+	}
+	return t, nil
+}
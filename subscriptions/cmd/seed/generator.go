@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/google/uuid"
+	"math/rand/v2"
+	"time"
+)
+
+type generatorConfig struct {
+	users int
+	seed  uint64
+	// until - последний месяц, в котором могут начинаться подписки.
+	until time.Time
+	// history - на сколько месяцев назад от until уходят даты начала.
+	history int
+	// cancelRate - доля отмененных подписок.
+	cancelRate float64
+}
+
+// generator детерминирован: одинаковые seed и until дают одинаковые данные,
+// включая идентификаторы.
+type generator struct {
+	cfg         generatorConfig
+	source      *rand.ChaCha8
+	rng         *rand.Rand
+	totalWeight int
+}
+
+func newGenerator(cfg generatorConfig) *generator {
+	var key [32]byte
+	binary.LittleEndian.PutUint64(key[:], cfg.seed)
+	source := rand.NewChaCha8(key)
+
+	g := &generator{
+		cfg:    cfg,
+		source: source,
+		rng:    rand.New(source),
+	}
+	for _, s := range catalog {
+		g.totalWeight += s.weight
+	}
+	return g
+}
+
+// generate вызывает emit для подписок каждого пользователя по очереди,
+// чтобы не держать в памяти весь набор данных.
+func (g *generator) generate(emit func([]*models.Subscription) error) error {
+	for i := 0; i < g.cfg.users; i++ {
+		subs, err := g.portfolio()
+		if err != nil {
+			return err
+		}
+		if err := emit(subs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) portfolio() ([]*models.Subscription, error) {
+	userID, err := g.uuid()
+	if err != nil {
+		return nil, err
+	}
+
+	// Чаще всего 2-4 подписки, изредка до 8.
+	size := 1 + g.rng.IntN(3) + g.rng.IntN(3) + g.rng.IntN(2)*g.rng.IntN(3)
+
+	picked := make(map[int]bool, size)
+	subs := make([]*models.Subscription, 0, size)
+	for len(subs) < size && len(picked) < len(catalog) {
+		idx := g.pickService()
+		if picked[idx] {
+			continue
+		}
+		picked[idx] = true
+		sub, err := g.subscription(userID, catalog[idx])
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (g *generator) subscription(userID uuid.UUID, s service) (*models.Subscription, error) {
+	p := s.plans[g.rng.IntN(len(s.plans))]
+
+	// Цена в базе - ежемесячная, годовые планы пересчитываются.
+	price := p.price / p.period
+
+	start := g.cfg.until.AddDate(0, -g.rng.IntN(g.cfg.history+1), 0)
+
+	id, err := g.uuid()
+	if err != nil {
+		return nil, err
+	}
+	sub := &models.Subscription{
+		ID:          id,
+		ServiceName: s.name,
+		Price:       &price,
+		UserID:      userID,
+		StartDate:   models.MonthYear(start),
+	}
+
+	if service := builtinService(s.name); service != nil {
+		serviceID := service.ID
+		sub.ServiceID = &serviceID
+		sub.Category = service.Category
+	}
+
+	// Отмена приходится на конец одного из оплаченных периодов и не позже
+	// until; если ни один период еще не закончился, подписка остается активной.
+	months := monthsBetween(start, g.cfg.until) + 1
+	if maxPeriods := min(months/p.period, 24/p.period); maxPeriods > 0 && g.rng.Float64() < g.cfg.cancelRate {
+		periods := 1 + g.rng.IntN(maxPeriods)
+		end := models.MonthYear(start.AddDate(0, periods*p.period-1, 0))
+		sub.EndDate = &end
+	}
+
+	return sub, nil
+}
+
+func (g *generator) pickService() int {
+	n := g.rng.IntN(g.totalWeight)
+	for i, s := range catalog {
+		if n < s.weight {
+			return i
+		}
+		n -= s.weight
+	}
+	return len(catalog) - 1
+}
+
+func (g *generator) uuid() (uuid.UUID, error) {
+	id, err := uuid.NewRandomFromReader(g.source)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to generate id: %w", err)
+	}
+	return id, nil
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testUntil = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func generateAll(t *testing.T, cfg generatorConfig) []*models.Subscription {
+	t.Helper()
+	var all []*models.Subscription
+	err := newGenerator(cfg).generate(func(subs []*models.Subscription) error {
+		all = append(all, subs...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return all
+}
+
+// planOf находит план каталога по ежемесячной цене подписки.
+func planOf(t *testing.T, sub *models.Subscription) plan {
+	t.Helper()
+	for _, s := range catalog {
+		if s.name != sub.ServiceName {
+			continue
+		}
+		for _, p := range s.plans {
+			if p.price/p.period == *sub.Price {
+				return p
+			}
+		}
+	}
+	t.Fatalf("no plan of %s costs %d a month", sub.ServiceName, *sub.Price)
+	return plan{}
+}
+
+func TestGeneratorDeterministic(t *testing.T) {
+	cfg := generatorConfig{users: 50, seed: 42, until: testUntil, history: 36, cancelRate: 0.3}
+
+	first, second := generateAll(t, cfg), generateAll(t, cfg)
+	if len(first) == 0 {
+		t.Fatal("no subscriptions generated")
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Error("same seed and until produced different data")
+	}
+
+	tests := []struct {
+		name   string
+		change func(*generatorConfig)
+	}{
+		{name: "другой seed", change: func(c *generatorConfig) { c.seed++ }},
+		{name: "другой until", change: func(c *generatorConfig) { c.until = c.until.AddDate(0, 1, 0) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := cfg
+			tt.change(&other)
+			if reflect.DeepEqual(first, generateAll(t, other)) {
+				t.Error("different config produced the same data")
+			}
+		})
+	}
+}
+
+func TestGeneratorSubscriptions(t *testing.T) {
+	subs := generateAll(t, generatorConfig{users: 500, seed: 7, until: testUntil, history: 36, cancelRate: 1})
+
+	var yearly, ended int
+	for _, sub := range subs {
+		p := planOf(t, sub)
+		if p.period == periodYearly {
+			yearly++
+		}
+
+		start := time.Time(sub.StartDate)
+		if start.After(testUntil) || monthsBetween(start, testUntil) > 36 {
+			t.Errorf("%s: start %s outside history", sub.ID, start.Format(monthLayout))
+		}
+
+		if sub.EndDate == nil {
+			continue
+		}
+		ended++
+		end := time.Time(*sub.EndDate)
+		if end.After(testUntil) {
+			t.Errorf("%s: end %s after until", sub.ID, end.Format(monthLayout))
+		}
+		if months := monthsBetween(start, end) + 1; months <= 0 || months%p.period != 0 {
+			t.Errorf("%s: %d months from %s to %s is not a whole number of %d-month periods",
+				sub.ID, months, start.Format(monthLayout), end.Format(monthLayout), p.period)
+		}
+	}
+
+	// Иначе проверки выше ничего не покрывают.
+	if yearly == 0 || ended == 0 {
+		t.Fatalf("generated %d yearly and %d ended subscriptions, want both", yearly, ended)
+	}
+}
@@ -0,0 +1,265 @@
+// Команда seed генерирует пользователей с правдоподобными наборами подписок
+// и загружает их в базу через COPY и/или пишет фикстуры в JSON или CSV:
+//
+//	seed --users 1000 --seed 42
+//	seed --users 20 --until 06-2025 --insert=false --out fixtures.json
+//
+// При одинаковых --seed и --until результат полностью воспроизводим.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/config"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tracing"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/repo"
+	userRepository "github.com/ekkserapopova/subscriptions/internal/services/users/repo"
+	"github.com/ekkserapopova/subscriptions/pkg/builder"
+	"github.com/ekkserapopova/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type options struct {
+	configPath string
+	generator  generatorConfig
+	until      string
+	batchSize  int
+	insert     bool
+	out        string
+	format     string
+}
+
+func main() {
+	opts := options{}
+
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	fs.StringVar(&opts.configPath, "config", "", "path to YAML config (overrides CONFIG_PATH)")
+	fs.IntVar(&opts.generator.users, "users", 100, "number of users to generate")
+	fs.Uint64Var(&opts.generator.seed, "seed", 1, "random seed")
+	fs.StringVar(&opts.until, "until", time.Now().Format(monthLayout), "latest start month, MM-YYYY")
+	fs.IntVar(&opts.generator.history, "history", 36, "how many months back start dates go")
+	fs.Float64Var(&opts.generator.cancelRate, "cancel-rate", 0.3, "share of cancelled subscriptions")
+	fs.IntVar(&opts.batchSize, "batch", 1000, "rows per COPY batch")
+	fs.BoolVar(&opts.insert, "insert", true, "insert generated data into the database")
+	fs.StringVar(&opts.out, "out", "", "write fixtures to this file")
+	fs.StringVar(&opts.format, "format", "", "fixture format: json or csv (defaults to the --out extension)")
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
+	}
+
+	if err := run(opts); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options) error {
+	until, err := parseMonth(opts.until)
+	if err != nil {
+		return err
+	}
+	opts.generator.until = until
+
+	if opts.generator.users <= 0 || opts.batchSize <= 0 || opts.generator.history < 0 {
+		return errors.New("--users and --batch must be positive, --history must not be negative")
+	}
+	if !opts.insert && opts.out == "" {
+		return errors.New("nothing to do: set --out or --insert")
+	}
+
+	var (
+		sinks    []func([]*models.Subscription) error
+		inserter *batchInserter
+	)
+
+	if opts.out != "" {
+		format := opts.format
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(opts.out), ".")
+		}
+
+		file, err := os.Create(opts.out)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", opts.out, err)
+		}
+		defer file.Close()
+
+		fixtures, err := newFixtureWriter(file, format)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := fixtures.close(); err != nil {
+				log.Printf("failed to finish %s: %s", opts.out, err)
+			}
+		}()
+
+		sinks = append(sinks, fixtures.write)
+	}
+
+	if opts.insert {
+		ctx := context.Background()
+
+		repositories, stop, err := openRepositories(ctx, opts.configPath)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		inserter = &batchInserter{
+			ctx:   ctx,
+			repo:  repositories.subscriptions,
+			users: repositories.users,
+			seen:  make(map[uuid.UUID]struct{}),
+			size:  opts.batchSize,
+		}
+		sinks = append(sinks, inserter.add)
+	}
+
+	err = newGenerator(opts.generator).generate(func(subs []*models.Subscription) error {
+		for _, sink := range sinks {
+			if err := sink(subs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if inserter != nil {
+		if err := inserter.flush(); err != nil {
+			return err
+		}
+		log.Printf("inserted %d users and %d subscriptions", inserter.totalUsers, inserter.total)
+	}
+	return nil
+}
+
+type repositories struct {
+	subscriptions *repo.Repository
+	users         *userRepository.Repository
+}
+
+// openRepositories поднимает тот же граф зависимостей, что и сервер,
+// но только до репозиториев.
+func openRepositories(ctx context.Context, configPath string) (*repositories, func(), error) {
+	var configArgs []string
+	if configPath != "" {
+		configArgs = []string{"--config", configPath}
+	}
+
+	cfg, err := config.Load(configArgs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	// Трассировка COPY-запросов при загрузке не нужна.
+	cfg.Tracing.Exporter = tracing.ExporterNone
+
+	repository := &repositories{}
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(cfg),
+		fx.Provide(
+			config.Provide,
+			logger.SetupLogger,
+			builder.SetupBuilder,
+			metrics.NewMetrics,
+			tracing.NewTracerProvider,
+			db.NewPostgresPool,
+			db.NewCluster,
+			repo.NewRepository,
+			userRepository.NewRepository,
+		),
+		fx.Populate(&repository.subscriptions, &repository.users),
+	)
+
+	startCtx, cancel := context.WithTimeout(ctx, app.StartTimeout())
+	defer cancel()
+	if err := app.Start(startCtx); err != nil {
+		return nil, nil, err
+	}
+
+	stop := func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), app.StopTimeout())
+		defer cancel()
+		if err := app.Stop(stopCtx); err != nil {
+			log.Printf("failed to stop: %s", err)
+		}
+	}
+	return repository, stop, nil
+}
+
+type batchInserter struct {
+	ctx   context.Context
+	repo  *repo.Repository
+	users *userRepository.Repository
+	// seen - уже вставленные пользователи: подписки ссылаются на них
+	// внешним ключом, поэтому пользователи вставляются первыми.
+	seen       map[uuid.UUID]struct{}
+	size       int
+	batch      []*models.Subscription
+	total      int64
+	totalUsers int64
+}
+
+func (b *batchInserter) add(subs []*models.Subscription) error {
+	b.batch = append(b.batch, subs...)
+	if len(b.batch) >= b.size {
+		return b.flush()
+	}
+	return nil
+}
+
+func (b *batchInserter) flush() error {
+	if len(b.batch) == 0 {
+		return nil
+	}
+
+	var newUsers []*models.User
+	for _, sub := range b.batch {
+		if _, ok := b.seen[sub.UserID]; ok {
+			continue
+		}
+		b.seen[sub.UserID] = struct{}{}
+		newUsers = append(newUsers, &models.User{
+			ID:       sub.UserID,
+			Name:     "Seed user " + sub.UserID.String()[:8],
+			Currency: models.DefaultCurrency,
+			Locale:   models.DefaultLocale,
+			TimeZone: models.DefaultTimeZone,
+		})
+	}
+	if len(newUsers) > 0 {
+		copiedUsers, err := b.users.CopyUsers(b.ctx, newUsers)
+		if err != nil {
+			return fmt.Errorf("failed to copy users: %w", err)
+		}
+		b.totalUsers += copiedUsers
+	}
+
+	copied, err := b.repo.CopySubscriptions(b.ctx, b.batch)
+	if err != nil {
+		return fmt.Errorf("failed to copy batch: %w", err)
+	}
+
+	b.total += copied
+	b.batch = b.batch[:0]
+	return nil
+}
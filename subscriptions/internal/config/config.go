@@ -1,68 +1,104 @@
+// Package config собирает настройки всех подсистем.
+//
+// Источники применяются в порядке возрастания приоритета:
+//
+//  1. значения по умолчанию (теги env-default);
+//  2. YAML-файл (--config, CONFIG_PATH или config/config.yaml);
+//  3. файл .env в рабочей директории;
+//  4. переменные окружения;
+//  5. флаги командной строки.
+//
+// Переменные из .env не перезаписывают уже заданные в окружении,
+// поэтому окружение приоритетнее .env. Значение по умолчанию применяется
+// только к полям, которых нет в YAML, так что явный ноль в файле
+// (например transactions.maxRetries: 0) сохраняется.
 package config
 
 import (
+	"github.com/ekkserapopova/subscriptions/internal/pkg/cache"
 	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/grpcserver"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/health"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/migrations"
 	"github.com/ekkserapopova/subscriptions/internal/pkg/server"
-	"github.com/ilyakaznacheev/cleanenv"
-	"github.com/joho/godotenv"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tracing"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	subscriptionGraphQL "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/graphql"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	"github.com/ekkserapopova/subscriptions/pkg/logger"
 	"go.uber.org/fx"
-	"log"
-	"os"
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+	StorageSQLite   = "sqlite"
 )
 
 type Config struct {
-	ConfigPath string `env:"CONFIG_PATH" env-default:"config/config.yaml"`
+	ConfigPath string `yaml:"-" env:"CONFIG_PATH" env-default:"config/config.yaml"`
+
+	// Storage выбирает хранилище подписок. В режиме memory база не нужна,
+	// данные живут до перезапуска, а события не выходят за пределы процесса.
+	// Режим sqlite хранит данные в одном файле; события тоже локальные.
+	Storage string `yaml:"storage" env:"STORAGE" env-default:"postgres"`
+
+	HTTPServer server.Config              `yaml:"httpServer"`
+	GRPCServer grpcserver.Config          `yaml:"grpcServer"`
+	DB         db.Config                  `yaml:"db"`
+	SQLite     db.SQLiteConfig            `yaml:"sqlite"`
+	Migrations migrations.Config          `yaml:"migrations"`
+	Cache      cache.Config               `yaml:"cache"`
+	Tx         tx.Config                  `yaml:"transactions"`
+	Events     events.Config              `yaml:"events"`
+	GraphQL    subscriptionGraphQL.Config `yaml:"graphql"`
+	Health     health.Config              `yaml:"health"`
+	Metrics    metrics.Config             `yaml:"metrics"`
+	Tracing    tracing.Config             `yaml:"tracing"`
+	Logger     logger.Config              `yaml:"logger"`
 
-	HTTPServer server.Config `yaml:"httpServer"`
-	DB         db.Config     `yaml:"db"`
+	// PrintConfig выставляется флагом --print-config.
+	PrintConfig bool `yaml:"-"`
+
+	// args сохраняются, чтобы при перечитывании конфига
+	// учитывались те же флаги.
+	args []string
 }
 
 type Out struct {
 	fx.Out
 
 	HTTPServer server.Config
+	GRPCServer grpcserver.Config
 	DB         db.Config
+	SQLite     db.SQLiteConfig
+	Migrations migrations.Config
+	Cache      cache.Config
+	Tx         tx.Config
+	Events     events.Config
+	GraphQL    subscriptionGraphQL.Config
+	Health     health.Config
+	Metrics    metrics.Config
+	Tracing    tracing.Config
+	Logger     logger.Config
 }
 
-func MustLoad() Out {
-	// 1. Подгружаем переменные из .env, если файл существует
-	if _, err := os.Stat(".env"); err == nil {
-		if err := godotenv.Load(); err != nil {
-			log.Printf("failed to load .env file: %s", err)
-			os.Exit(1)
-		}
-	}
-
-	var cfg Config
-
-	// 2. Читаем CONFIG_PATH из env
-	if err := cleanenv.ReadEnv(&cfg); err != nil {
-		log.Printf("cannot read env variables: %s", err)
-		os.Exit(1)
-	}
-
-	// 3. Читаем YAML
-	if _, err := os.Stat(cfg.ConfigPath); os.IsNotExist(err) {
-		log.Printf("config file does not exist: %s", cfg.ConfigPath)
-		os.Exit(1)
-	}
-	if err := cleanenv.ReadConfig(cfg.ConfigPath, &cfg); err != nil {
-		log.Printf("cannot read %s: %v", cfg.ConfigPath, err)
-		os.Exit(1)
-	}
-
-	// 4. Переопределяем настройками из env (POSTGRES_*)
-	if err := cleanenv.ReadEnv(&cfg.DB); err != nil {
-		log.Printf("cannot read DB env variables: %s", err)
-		os.Exit(1)
-	}
-	if err := cleanenv.ReadEnv(&cfg.HTTPServer); err != nil {
-		log.Printf("cannot read HTTPServer env variables: %s", err)
-		os.Exit(1)
-	}
-
+// Provide раздает конфиги подсистем через fx.
+func Provide(cfg *Config) Out {
 	return Out{
 		HTTPServer: cfg.HTTPServer,
+		GRPCServer: cfg.GRPCServer,
 		DB:         cfg.DB,
+		SQLite:     cfg.SQLite,
+		Migrations: cfg.Migrations,
+		Cache:      cfg.Cache,
+		Tx:         cfg.Tx,
+		Events:     cfg.Events,
+		GraphQL:    cfg.GraphQL,
+		Health:     cfg.Health,
+		Metrics:    cfg.Metrics,
+		Tracing:    cfg.Tracing,
+		Logger:     cfg.Logger,
 	}
 }
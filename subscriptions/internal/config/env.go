@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyDefaults заполняет поля значениями из тегов env-default.
+func applyDefaults(cfg *Config) error {
+	return walkEnv(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField) (string, bool) {
+		return field.Tag.Lookup("env-default")
+	})
+}
+
+// applyEnv перезаписывает только поля, чьи переменные заданы, поэтому
+// явный ноль из YAML не заменяется значением по умолчанию.
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	return walkEnv(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField) (string, bool) {
+		name := field.Tag.Get("env")
+		if name == "" {
+			return "", false
+		}
+		return lookup(name)
+	})
+}
+
+func walkEnv(v reflect.Value, value func(reflect.StructField) (string, bool)) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := walkEnv(v.Field(i), value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, ok := value(field)
+		if !ok {
+			continue
+		}
+		if err := setValue(v.Field(i), raw, field.Tag.Get("env-separator")); err != nil {
+			return fmt.Errorf("parsing %s %s: %w", field.Name, field.Tag.Get("env"), err)
+		}
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, raw, separator string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		if separator == "" {
+			separator = ","
+		}
+		var items []string
+		if raw != "" {
+			items = strings.Split(raw, separator)
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
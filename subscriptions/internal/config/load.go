@@ -0,0 +1,185 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"strconv"
+)
+
+const envFile = ".env"
+
+// Load читает конфиг из всех источников и проверяет его. Ошибки
+// валидации возвращаются все сразу через errors.Join.
+func Load(args []string) (*Config, error) {
+	flags, err := parseFlags(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// .env читается заново при каждой загрузке и не попадает в окружение
+	// процесса, поэтому SIGHUP видит его изменения.
+	dotenv, err := readEnvFile()
+	if err != nil {
+		return nil, err
+	}
+	lookup := func(name string) (string, bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		value, ok := dotenv[name]
+		return value, ok
+	}
+
+	var cfg Config
+	if err := applyDefaults(&cfg); err != nil {
+		return nil, err
+	}
+	path := cfg.ConfigPath
+	if value, ok := lookup("CONFIG_PATH"); ok {
+		path = value
+	}
+	if flags.configPath != "" {
+		path = flags.configPath
+	}
+
+	if err := readYAML(path, &cfg); err != nil {
+		return nil, err
+	}
+	if err := applyEnv(&cfg, lookup); err != nil {
+		return nil, fmt.Errorf("cannot read env variables: %w", err)
+	}
+	cfg.ConfigPath = path
+
+	if err := flags.apply(&cfg); err != nil {
+		return nil, err
+	}
+	cfg.PrintConfig = flags.printConfig
+	cfg.args = args
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	return &cfg, nil
+}
+
+func readEnvFile() (map[string]string, error) {
+	if _, err := os.Stat(envFile); err != nil {
+		return nil, nil
+	}
+	values, err := godotenv.Read(envFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s file: %w", envFile, err)
+	}
+	return values, nil
+}
+
+// readYAML накладывает файл на уже заполненный конфиг: поля,
+// которых нет в файле, сохраняют значения по умолчанию.
+func readYAML(path string, cfg *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := yaml.NewDecoder(f).Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	return nil
+}
+
+type flagValues struct {
+	configPath  string
+	printConfig bool
+	overrides   map[string]string
+}
+
+// overrideFlags перечисляет флаги, переопределяющие отдельные настройки.
+var overrideFlags = map[string]struct {
+	usage string
+	set   func(cfg *Config, value string) error
+}{
+	"storage": {"subscription storage: postgres, memory or sqlite", func(cfg *Config, v string) error {
+		cfg.Storage = v
+		return nil
+	}},
+	"http-address": {"HTTP server address", func(cfg *Config, v string) error {
+		cfg.HTTPServer.Address = v
+		return nil
+	}},
+	"grpc-address": {"gRPC server address", func(cfg *Config, v string) error {
+		cfg.GRPCServer.Address = v
+		return nil
+	}},
+	"db-host": {"PostgreSQL host", func(cfg *Config, v string) error {
+		cfg.DB.Host = v
+		return nil
+	}},
+	"db-port": {"PostgreSQL port", func(cfg *Config, v string) error {
+		port, err := strconv.ParseUint(v, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid --db-port %q: %w", v, err)
+		}
+		cfg.DB.Port = uint16(port)
+		return nil
+	}},
+	"db-name": {"PostgreSQL database name", func(cfg *Config, v string) error {
+		cfg.DB.DB = v
+		return nil
+	}},
+	"log-env": {"logger environment: local, dev or prod", func(cfg *Config, v string) error {
+		cfg.Logger.Environment = v
+		return nil
+	}},
+	"log-level": {"log level: debug, info, warn or error", func(cfg *Config, v string) error {
+		cfg.Logger.Level = v
+		return nil
+	}},
+	"log-format": {"log format: text or json", func(cfg *Config, v string) error {
+		cfg.Logger.Format = v
+		return nil
+	}},
+}
+
+func parseFlags(args []string) (*flagValues, error) {
+	values := &flagValues{overrides: make(map[string]string)}
+
+	fs := flag.NewFlagSet("subscriptions", flag.ContinueOnError)
+	fs.StringVar(&values.configPath, "config", "", "path to YAML config (overrides CONFIG_PATH)")
+	fs.BoolVar(&values.printConfig, "print-config", false, "print effective config with secrets redacted and exit")
+	for name, f := range overrideFlags {
+		fs.String(name, "", f.usage)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, errors.New("unexpected arguments: " + fmt.Sprint(fs.Args()))
+	}
+
+	// Учитываются только явно переданные флаги, чтобы пустое значение
+	// по умолчанию не затирало YAML и окружение.
+	fs.Visit(func(f *flag.Flag) {
+		if _, ok := overrideFlags[f.Name]; ok {
+			values.overrides[f.Name] = f.Value.String()
+		}
+	})
+
+	return values, nil
+}
+
+func (v *flagValues) apply(cfg *Config) error {
+	for name, value := range v.overrides {
+		if err := overrideFlags[name].set(cfg, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const baseYAML = `storage: memory
+httpServer:
+  address: "localhost:8080"
+grpcServer:
+  address: "localhost:9090"
+`
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		dotenv  string
+		env     map[string]string
+		args    []string
+		retries int
+		level   string
+	}{
+		{name: "значение по умолчанию", retries: 3},
+		{name: "YAML поверх умолчаний", yaml: "transactions:\n  maxRetries: 5\n", retries: 5},
+		{name: "явный ноль из YAML", yaml: "transactions:\n  maxRetries: 0\n", retries: 0},
+		{
+			name:    ".env поверх YAML",
+			yaml:    "transactions:\n  maxRetries: 0\nlogger:\n  level: debug\n",
+			dotenv:  "TX_MAX_RETRIES=5\nLOG_LEVEL=warn\n",
+			retries: 5,
+			level:   "warn",
+		},
+		{
+			name:    "окружение поверх .env",
+			dotenv:  "TX_MAX_RETRIES=5\n",
+			env:     map[string]string{"TX_MAX_RETRIES": "7"},
+			retries: 7,
+		},
+		{
+			name:    "ноль из окружения",
+			yaml:    "transactions:\n  maxRetries: 5\n",
+			env:     map[string]string{"TX_MAX_RETRIES": "0"},
+			retries: 0,
+		},
+		{
+			name:    "флаг поверх окружения",
+			env:     map[string]string{"LOG_LEVEL": "warn"},
+			args:    []string{"--log-level", "error"},
+			retries: 3,
+			level:   "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			writeFile(t, filepath.Join(dir, "config.yaml"), baseYAML+tt.yaml)
+			if tt.dotenv != "" {
+				writeFile(t, filepath.Join(dir, envFile), tt.dotenv)
+			}
+			clearEnv(t, "CONFIG_PATH", "TX_MAX_RETRIES", "LOG_LEVEL")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load(append([]string{"--config", "config.yaml"}, tt.args...))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Tx.MaxRetries != tt.retries {
+				t.Errorf("maxRetries = %d, want %d", cfg.Tx.MaxRetries, tt.retries)
+			}
+			if cfg.Logger.Level != tt.level {
+				t.Errorf("logger.level = %q, want %q", cfg.Logger.Level, tt.level)
+			}
+		})
+	}
+}
+
+func TestLoadConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFile(t, filepath.Join(dir, "other.yaml"), baseYAML+"transactions:\n  maxRetries: 1\n")
+	writeFile(t, filepath.Join(dir, envFile), "CONFIG_PATH=other.yaml\n")
+	clearEnv(t, "CONFIG_PATH", "TX_MAX_RETRIES")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.ConfigPath != "other.yaml" || cfg.Tx.MaxRetries != 1 {
+		t.Errorf("config %s, maxRetries %d, want other.yaml, 1", cfg.ConfigPath, cfg.Tx.MaxRetries)
+	}
+}
+
+// TestLoadRereadsEnvFile повторяет SIGHUP: изменения .env должны
+// учитываться при повторной загрузке.
+func TestLoadRereadsEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFile(t, filepath.Join(dir, "config.yaml"), baseYAML)
+	clearEnv(t, "CONFIG_PATH", "LOG_LEVEL")
+
+	args := []string{"--config", "config.yaml"}
+	for _, level := range []string{"info", "debug"} {
+		writeFile(t, filepath.Join(dir, envFile), "LOG_LEVEL="+level+"\n")
+		cfg, err := Load(args)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Logger.Level != level {
+			t.Errorf("logger.level = %q, want %q", cfg.Logger.Level, level)
+		}
+	}
+	if _, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		t.Error(".env leaked into the process environment")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// clearEnv снимает переменные на время теста и возвращает их после.
+func clearEnv(t *testing.T, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		t.Setenv(name, "")
+		if err := os.Unsetenv(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// Print выводит действующий конфиг в YAML. Поля с тегом secret:"true"
+// заменяются на [REDACTED], если они заданы.
+func Print(w io.Writer, cfg *Config) error {
+	fmt.Fprintf(w, "# config file: %s\n", cfg.ConfigPath)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(toNode(reflect.ValueOf(*cfg))); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return enc.Close()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// toNode строит YAML вручную, чтобы сохранить порядок полей
+// и печатать длительности как 4s, а не в наносекундах.
+func toNode(v reflect.Value) *yaml.Node {
+	switch {
+	case v.Type() == durationType:
+		return scalar(v.Interface().(time.Duration).String())
+	case v.Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if !field.IsExported() || name == "-" || name == "" {
+				continue
+			}
+
+			value := toNode(v.Field(i))
+			if field.Tag.Get("secret") == "true" && !v.Field(i).IsZero() {
+				value = scalar(redacted)
+			}
+			node.Content = append(node.Content, scalar(name), value)
+		}
+		return node
+	case v.Kind() == reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := 0; i < v.Len(); i++ {
+			node.Content = append(node.Content, toNode(v.Index(i)))
+		}
+		return node
+	case v.Kind() == reflect.String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: v.String(), Tag: "!!str"}
+	case v.Kind() == reflect.Bool:
+		return scalar(strconv.FormatBool(v.Bool()))
+	default:
+		return scalar(fmt.Sprint(v.Interface()))
+	}
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"context"
+	"github.com/ekkserapopova/subscriptions/pkg/logger"
+	"go.uber.org/fx"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+type ReloadParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    *Config
+	Logger    *slog.Logger
+	LogLevel  *slog.LevelVar
+}
+
+// RunReloader перечитывает конфиг по SIGHUP. Применяются только
+// безопасные настройки (уровень логирования), остальные изменения
+// требуют перезапуска. Невалидный конфиг игнорируется целиком.
+func RunReloader(p ReloadParams) {
+	hup := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			signal.Notify(hup, syscall.SIGHUP)
+
+			go func() {
+				for {
+					select {
+					case <-hup:
+						reload(p)
+					case <-done:
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			signal.Stop(hup)
+			close(done)
+			return nil
+		},
+	})
+}
+
+func reload(p ReloadParams) {
+	cfg, err := Load(p.Config.args)
+	if err != nil {
+		p.Logger.Error("reload config: " + err.Error())
+		return
+	}
+
+	level, _, err := logger.Resolve(cfg.Logger)
+	if err != nil {
+		p.Logger.Error("reload config: " + err.Error())
+		return
+	}
+
+	p.LogLevel.Set(level)
+	p.Logger.Info("reloaded config, log level " + level.String())
+}
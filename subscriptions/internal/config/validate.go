@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/cache"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tracing"
+	"github.com/ekkserapopova/subscriptions/pkg/logger"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Validate проверяет конфиг целиком и возвращает все найденные
+// ошибки, а не только первую.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.address("httpServer.address", c.HTTPServer.Address)
+	v.positive("httpServer.timeout", c.HTTPServer.Timeout)
+	v.positive("httpServer.idleTimeout", c.HTTPServer.IdleTimeout)
+	v.positive("httpServer.readHeaderTimeout", c.HTTPServer.ReadHeaderTimeout)
+	v.positive("httpServer.shutdownTimeout", c.HTTPServer.ShutdownTimeout)
+
+	v.address("grpcServer.address", c.GRPCServer.Address)
+	for i, token := range c.GRPCServer.AuthTokens {
+		v.check(strings.TrimSpace(token) != "", fmt.Sprintf("grpcServer.authTokens[%d]", i), "must not be empty")
+	}
+
+	switch c.Storage {
+	case StorageMemory:
+	case StorageSQLite:
+		v.required("sqlite.path", c.SQLite.Path)
+		v.positive("sqlite.busyTimeout", c.SQLite.BusyTimeout)
+	case StoragePostgres:
+		v.required("db.host", c.DB.Host)
+		v.required("db.name", c.DB.DB)
+		v.required("db.user", c.DB.User)
+		v.check(c.DB.Port != 0, "db.port", "must be set")
+		v.positive("db.connectTimeout", c.DB.ConnectTimeout)
+		v.check(c.DB.MaxConns > 0, "db.maxConns", "must be positive")
+		v.check(c.DB.MinConns >= 0 && c.DB.MinConns <= c.DB.MaxConns, "db.minConns", "must be between 0 and db.maxConns")
+		v.check(c.DB.MaxConnLifetime >= 0, "db.maxConnLifetime", "must not be negative")
+		v.check(c.DB.MaxConnIdleTime >= 0, "db.maxConnIdleTime", "must not be negative")
+		v.positive("db.healthCheckPeriod", c.DB.HealthCheckPeriod)
+		v.check(c.DB.StatementTimeout >= 0, "db.statementTimeout", "must not be negative")
+		v.check(slices.Contains(db.SSLModes, c.DB.TLS.Mode), "db.tls.mode", "must be one of "+strings.Join(db.SSLModes, ", "))
+		v.check((c.DB.TLS.CertFile == "") == (c.DB.TLS.KeyFile == ""), "db.tls", "certFile and keyFile must be set together")
+		for i, replica := range c.DB.Replicas {
+			v.address(fmt.Sprintf("db.replicas[%d]", i), replica)
+		}
+		if len(c.DB.Replicas) > 0 {
+			v.positive("db.replicaCheckPeriod", c.DB.ReplicaCheckPeriod)
+			v.positive("db.replicaCheckTimeout", c.DB.ReplicaCheckTimeout)
+		}
+	default:
+		v.fail("storage", fmt.Sprintf("unknown storage %q", c.Storage))
+	}
+
+	switch c.Cache.Backend {
+	case cache.BackendNone:
+	case cache.BackendMemory:
+		v.positive("cache.ttl", c.Cache.TTL)
+		v.check(c.Cache.Size > 0, "cache.size", "must be positive")
+	case cache.BackendRedis:
+		v.positive("cache.ttl", c.Cache.TTL)
+		v.address("cache.redis.address", c.Cache.Redis.Address)
+	default:
+		v.fail("cache.backend", fmt.Sprintf("unknown cache backend %q", c.Cache.Backend))
+	}
+
+	v.check(c.Tx.MaxRetries >= 0, "transactions.maxRetries", "must not be negative")
+	v.check(c.Tx.RetryDelay >= 0, "transactions.retryDelay", "must not be negative")
+
+	v.required("events.channel", c.Events.Channel)
+	v.check(c.Events.LogSize > 0, "events.logSize", "must be positive")
+	v.check(c.Events.BufferSize > 0, "events.bufferSize", "must be positive")
+	v.positive("events.heartbeat", c.Events.Heartbeat)
+
+	v.check(c.GraphQL.MaxDepth > 0, "graphql.maxDepth", "must be positive")
+	v.check(c.GraphQL.MaxComplexity > 0, "graphql.maxComplexity", "must be positive")
+
+	v.positive("health.checkTimeout", c.Health.CheckTimeout)
+	v.check(c.Health.DrainDelay >= 0, "health.drainDelay", "must not be negative")
+
+	v.check(strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path", "must start with /")
+	v.positive("metrics.refreshInterval", c.Metrics.RefreshInterval)
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterOTLP:
+		v.required("tracing.endpoint", c.Tracing.Endpoint)
+	default:
+		v.fail("tracing.exporter", fmt.Sprintf("unknown exporter %q", c.Tracing.Exporter))
+	}
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio", "must be between 0 and 1")
+
+	if _, _, err := logger.Resolve(c.Logger); err != nil {
+		v.fail("logger", err.Error())
+	}
+	if c.Logger.File.Enabled {
+		v.check(c.Logger.File.MaxSizeMB > 0, "logger.file.maxSizeMB", "must be positive")
+	}
+
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(field, msg string) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", field, msg))
+}
+
+func (v *validator) check(ok bool, field, msg string) {
+	if !ok {
+		v.fail(field, msg)
+	}
+}
+
+func (v *validator) required(field, value string) {
+	v.check(value != "", field, "is required")
+}
+
+func (v *validator) positive(field string, d time.Duration) {
+	v.check(d > 0, field, "must be positive")
+}
+
+func (v *validator) address(field, value string) {
+	if _, _, err := net.SplitHostPort(value); err != nil {
+		v.fail(field, err.Error())
+	}
+}
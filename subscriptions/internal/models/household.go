@@ -0,0 +1,131 @@
+package models
+
+import (
+	"bytes"
+	"github.com/google/uuid"
+	"slices"
+	"time"
+)
+
+// Household объединяет пользователей, которые делят общие подписки.
+type Household struct {
+	ID        uuid.UUID         `json:"id"`
+	Name      string            `json:"name"`
+	Members   []HouseholdMember `json:"members"`
+	CreatedAt time.Time         `json:"created_at,omitzero"`
+	UpdatedAt time.Time         `json:"updated_at,omitzero"`
+}
+
+type HouseholdMember struct {
+	UserID   uuid.UUID `json:"user_id"`
+	JoinedAt time.Time `json:"joined_at,omitzero"`
+}
+
+// HasMember сообщает, состоит ли пользователь в домохозяйстве.
+func (h *Household) HasMember(userID uuid.UUID) bool {
+	return slices.ContainsFunc(h.Members, func(m HouseholdMember) bool {
+		return m.UserID == userID
+	})
+}
+
+type SplitRule string
+
+const (
+	// SplitEqual делит цену поровну между участниками и плательщиком.
+	SplitEqual SplitRule = "equal"
+	// SplitPercentage задает долю участника в процентах от цены.
+	SplitPercentage SplitRule = "percentage"
+	// SplitFixed задает долю участника фиксированной суммой.
+	SplitFixed SplitRule = "fixed"
+)
+
+// Split - правило разделения общей подписки внутри домохозяйства. Платит
+// владелец подписки, на него приходится остаток цены после долей участников.
+type Split struct {
+	SubscriptionID uuid.UUID    `json:"subscription_id"`
+	HouseholdID    uuid.UUID    `json:"household_id"`
+	Rule           SplitRule    `json:"rule"`
+	Shares         []SplitShare `json:"shares"`
+	CreatedAt      time.Time    `json:"created_at,omitzero"`
+	UpdatedAt      time.Time    `json:"updated_at,omitzero"`
+}
+
+// SplitShare - участник общей подписки. Value - проценты для percentage,
+// сумма для fixed; для equal не используется.
+type SplitShare struct {
+	UserID uuid.UUID `json:"user_id"`
+	Value  int       `json:"value,omitempty"`
+}
+
+// Amounts распределяет price между участниками и плательщиком payer. Сумма
+// долей всегда равна price: округление и все, что не покрыто долями,
+// достается плательщику. Фиксированные доли, которые после снижения цены
+// ее превышают, урезаются по порядку участников.
+func (s *Split) Amounts(price int, payer uuid.UUID) map[uuid.UUID]int {
+	shares := slices.Clone(s.Shares)
+	slices.SortFunc(shares, func(a, b SplitShare) int {
+		return bytes.Compare(a.UserID[:], b.UserID[:])
+	})
+
+	participants := 1
+	for _, share := range shares {
+		if share.UserID != payer {
+			participants++
+		}
+	}
+
+	amounts := make(map[uuid.UUID]int, participants)
+	rest := price
+	for _, share := range shares {
+		if share.UserID == payer {
+			continue
+		}
+
+		var amount int
+		switch s.Rule {
+		case SplitEqual:
+			amount = price / participants
+		case SplitPercentage:
+			amount = price * share.Value / 100
+		case SplitFixed:
+			amount = share.Value
+		}
+		amount = min(amount, rest)
+
+		amounts[share.UserID] = amount
+		rest -= amount
+	}
+	amounts[payer] = rest
+
+	return amounts
+}
+
+// Settlement - взаиморасчеты домохозяйства за месяц. Balance участника -
+// сколько он заплатил сверх своей доли; отрицательный баланс - долг.
+type Settlement struct {
+	HouseholdID uuid.UUID       `json:"household_id"`
+	Month       MonthYear       `json:"month"`
+	Balances    []MemberBalance `json:"balances"`
+	Transfers   []Transfer      `json:"transfers"`
+}
+
+type MemberBalance struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Paid    int       `json:"paid"`
+	Share   int       `json:"share"`
+	Balance int       `json:"balance"`
+}
+
+// Transfer - перевод, которым From гасит долг перед To.
+type Transfer struct {
+	From   uuid.UUID `json:"from"`
+	To     uuid.UUID `json:"to"`
+	Amount int       `json:"amount"`
+}
+
+// UserCost - стоимость подписок, приходящаяся на пользователя с учетом
+// долей в общих подписках.
+type UserCost struct {
+	UserID uuid.UUID `json:"user_id"`
+	Total  int       `json:"total"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"maps"
+	"testing"
+)
+
+// user возвращает ID с заданным последним байтом: порядок ID в тестах
+// совпадает с порядком n.
+func user(n byte) uuid.UUID {
+	var id uuid.UUID
+	id[15] = n
+	return id
+}
+
+func TestSplitAmounts(t *testing.T) {
+	payer, a, b := user(1), user(2), user(3)
+
+	tests := []struct {
+		name   string
+		rule   SplitRule
+		price  int
+		shares []SplitShare
+		want   map[uuid.UUID]int
+	}{
+		{
+			name:   "поровну без остатка",
+			rule:   SplitEqual,
+			price:  900,
+			shares: []SplitShare{{UserID: a}, {UserID: b}},
+			want:   map[uuid.UUID]int{payer: 300, a: 300, b: 300},
+		},
+		{
+			name:   "остаток от деления достается плательщику",
+			rule:   SplitEqual,
+			price:  1000,
+			shares: []SplitShare{{UserID: a}, {UserID: b}},
+			want:   map[uuid.UUID]int{payer: 334, a: 333, b: 333},
+		},
+		{
+			name:   "плательщик среди участников не считается дважды",
+			rule:   SplitEqual,
+			price:  1000,
+			shares: []SplitShare{{UserID: payer}, {UserID: a}},
+			want:   map[uuid.UUID]int{payer: 500, a: 500},
+		},
+		{
+			name:   "процент округляется вниз",
+			rule:   SplitPercentage,
+			price:  999,
+			shares: []SplitShare{{UserID: a, Value: 33}, {UserID: b, Value: 33}},
+			want:   map[uuid.UUID]int{payer: 341, a: 329, b: 329},
+		},
+		{
+			name:   "сто процентов на участников",
+			rule:   SplitPercentage,
+			price:  1000,
+			shares: []SplitShare{{UserID: a, Value: 60}, {UserID: b, Value: 40}},
+			want:   map[uuid.UUID]int{payer: 0, a: 600, b: 400},
+		},
+		{
+			name:   "фиксированные доли",
+			rule:   SplitFixed,
+			price:  1000,
+			shares: []SplitShare{{UserID: a, Value: 200}, {UserID: b, Value: 300}},
+			want:   map[uuid.UUID]int{payer: 500, a: 200, b: 300},
+		},
+		{
+			name:   "фиксированные доли урезаются по порядку участников",
+			rule:   SplitFixed,
+			price:  500,
+			shares: []SplitShare{{UserID: b, Value: 300}, {UserID: a, Value: 300}},
+			want:   map[uuid.UUID]int{payer: 0, a: 300, b: 200},
+		},
+		{
+			name:   "бесплатный месяц",
+			rule:   SplitFixed,
+			price:  0,
+			shares: []SplitShare{{UserID: a, Value: 300}},
+			want:   map[uuid.UUID]int{payer: 0, a: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			split := &Split{Rule: tt.rule, Shares: tt.shares}
+			got := split.Amounts(tt.price, payer)
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Amounts = %v, want %v", got, tt.want)
+			}
+
+			total := 0
+			for _, amount := range got {
+				total += amount
+			}
+			if total != tt.price {
+				t.Errorf("sum of amounts = %d, want %d", total, tt.price)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// Service — запись каталога сервисов. Подписки на известный сервис
+// ссылаются на нее и хранят каноническое название.
+type Service struct {
+	ID       uuid.UUID     `json:"id"`
+	Name     string        `json:"name"`
+	Aliases  []string      `json:"aliases"`
+	Category string        `json:"category"`
+	Currency string        `json:"currency"`
+	Plans    []ServicePlan `json:"plans"`
+
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// ServicePlan — тариф сервиса с ценой по прайсу за период оплаты.
+type ServicePlan struct {
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+	// Period - период оплаты в месяцах.
+	Period int `json:"period"`
+}
+
+// ServiceUpdate содержит изменяемые поля сервиса; nil-поля не меняются,
+// псевдонимы и тарифы заменяются целиком.
+type ServiceUpdate struct {
+	Name     *string        `json:"name,omitempty"`
+	Aliases  *[]string      `json:"aliases,omitempty"`
+	Category *string        `json:"category,omitempty"`
+	Currency *string        `json:"currency,omitempty"`
+	Plans    *[]ServicePlan `json:"plans,omitempty"`
+}
+
+func (u ServiceUpdate) Apply(service *Service) {
+	if u.Name != nil {
+		service.Name = *u.Name
+	}
+	if u.Aliases != nil {
+		service.Aliases = *u.Aliases
+	}
+	if u.Category != nil {
+		service.Category = *u.Category
+	}
+	if u.Currency != nil {
+		service.Currency = *u.Currency
+	}
+	if u.Plans != nil {
+		service.Plans = *u.Plans
+	}
+}
+
+// ServiceMatch — кандидат из каталога для названия в свободной форме.
+type ServiceMatch struct {
+	Service *Service `json:"service"`
+	// Matched - название или псевдоним, совпавший лучше всего.
+	Matched string  `json:"matched"`
+	Score   float64 `json:"score"`
+}
+
+// ServiceKey приводит название к виду, по которому каталог ищет сервис:
+// нижний регистр, ё как е, одиночные пробелы.
+func ServiceKey(name string) string {
+	key := strings.ToLower(strings.Join(strings.Fields(name), " "))
+	return strings.ReplaceAll(key, "ё", "е")
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"strings"
@@ -13,10 +14,51 @@ type MonthYear time.Time
 type Subscription struct {
 	ID          uuid.UUID  `json:"id"`
 	ServiceName string     `json:"service_name"`
-	Price       *int       `json:"price"`
-	UserID      uuid.UUID  `json:"user_id"`
-	StartDate   MonthYear  `json:"start_date"`
-	EndDate     *MonthYear `json:"end_date"`
+	ServiceID   *uuid.UUID `json:"service_id"`
+	// Category по умолчанию берется из каталога, Tags задает пользователь.
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+	// Price - цена текущего месяца, Prices - история цен с первым периодом
+	// от начала подписки. При создании Price задает начальную цену.
+	Price     *int          `json:"price"`
+	Prices    []PricePeriod `json:"prices,omitempty"`
+	UserID    uuid.UUID     `json:"user_id"`
+	StartDate MonthYear     `json:"start_date"`
+	EndDate   *MonthYear    `json:"end_date"`
+	// TrialEnd - последний месяц бесплатного пробного периода.
+	TrialEnd  *MonthYear `json:"trial_end"`
+	Promo     *Promo     `json:"promo"`
+	CreatedAt time.Time  `json:"created_at,omitzero"`
+	UpdatedAt time.Time  `json:"updated_at,omitzero"`
+}
+
+// Виды промо-периода.
+const (
+	PromoPercentage = "percentage"
+	PromoFixed      = "fixed"
+)
+
+// Promo - вводная цена на Months месяцев сразу после пробного периода,
+// а без него - с начала подписки: скидка Value процентов (PromoPercentage)
+// или фиксированная цена Value (PromoFixed).
+type Promo struct {
+	Type   string `json:"type"`
+	Value  int    `json:"value"`
+	Months int    `json:"months"`
+}
+
+// Apply возвращает цену price с учетом промо.
+func (p *Promo) Apply(price int) int {
+	if p.Type == PromoFixed {
+		return p.Value
+	}
+	return price * (100 - p.Value) / 100
+}
+
+// PricePeriod - цена подписки с месяца EffectiveFrom до следующего периода.
+type PricePeriod struct {
+	EffectiveFrom MonthYear `json:"effective_from"`
+	Price         int       `json:"price"`
 }
 
 func (m MonthYear) MarshalJSON() ([]byte, error) {
@@ -43,15 +85,29 @@ func (m MonthYear) PtrTime() *time.Time {
 	return &t
 }
 
+// Scan принимает time.Time от Postgres и строку YYYY-MM-DD от SQLite,
+// где даты хранятся текстом.
 func (m *MonthYear) Scan(value interface{}) error {
-	if value == nil {
+	switch v := value.(type) {
+	case nil:
 		*m = MonthYear(time.Time{})
-		return nil
-	}
-	t, ok := value.(time.Time)
-	if !ok {
+	case time.Time:
+		*m = MonthYear(v)
+	case string:
+		return m.scanText(v)
+	case []byte:
+		return m.scanText(string(v))
+	default:
 		return fmt.Errorf("cannot scan %T into MonthYear", value)
 	}
+	return nil
+}
+
+func (m *MonthYear) scanText(s string) error {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into MonthYear: %w", s, err)
+	}
 	*m = MonthYear(t)
 	return nil
 }
@@ -63,3 +119,253 @@ func (m MonthYear) Value() (driver.Value, error) {
 	}
 	return t, nil
 }
+
+// SubscriptionFilter задает выборку подписок с пагинацией по ID: возвращаются
+// подписки с ID больше AfterID, не более Limit штук (0 — без ограничения).
+// Непустой TrialEnd оставляет подписки, пробный период которых
+// заканчивается в этом месяце.
+type SubscriptionFilter struct {
+	IDs         []uuid.UUID
+	UserIDs     []uuid.UUID
+	ServiceName string
+	Category    string
+	Tag         string
+	TrialEnd    time.Time
+	AfterID     uuid.UUID
+	Limit       uint64
+}
+
+// CostFilter задает выборку для отчетов о расходах. Значения передаются
+// в том виде, в каком приходят в запросе: месяцы в формате MM-YYYY,
+// пользователи - ID через запятую.
+type CostFilter struct {
+	StartDate   string
+	EndDate     string
+	ServiceName string
+	UsersIDs    string
+	Category    string
+	Tag         string
+}
+
+// ParsePeriod разбирает границы отчета в формате MM-YYYY и возвращает первые
+// дни месяцев. Неверная граница не ограничивает выборку, ошибка о ней
+// возвращается для лога.
+func ParsePeriod(startDate, endDate string) (from, to *time.Time, err error) {
+	var errs []error
+
+	if startDate != "" {
+		t, parseErr := time.Parse("01-2006", startDate)
+		if parseErr != nil {
+			errs = append(errs, fmt.Errorf("invalid start_date format, expected MM-YYYY: %w", parseErr))
+		} else {
+			from = &t
+		}
+	}
+
+	if endDate != "" {
+		t, parseErr := time.Parse("01-2006", endDate)
+		if parseErr != nil {
+			errs = append(errs, fmt.Errorf("invalid end_date format, expected MM-YYYY: %w", parseErr))
+		} else {
+			to = &t
+		}
+	}
+
+	return from, to, errors.Join(errs...)
+}
+
+// ParseUserIDs разбирает ID пользователей через запятую, пропуская неверные.
+func ParseUserIDs(usersIds string) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, idStr := range strings.Split(usersIds, ",") {
+		id, err := uuid.Parse(strings.TrimSpace(idStr))
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Группировки отчета о расходах.
+const (
+	GroupByCategory = "category"
+	GroupByTag      = "tag"
+)
+
+// GroupCost - расходы одной категории или одного тега. Подписки без
+// категории или без тегов попадают в группу с пустым названием.
+type GroupCost struct {
+	Group string `json:"group"`
+	Total int    `json:"total"`
+}
+
+// TagUsage - тег и число подписок с ним.
+type TagUsage struct {
+	Tag           string `json:"tag"`
+	Subscriptions int    `json:"subscriptions"`
+}
+
+// TagRename - новое название тега.
+type TagRename struct {
+	Name string `json:"name"`
+}
+
+// TagMerge - теги Tags, которые заменяются тегом Into.
+type TagMerge struct {
+	Tags []string `json:"tags"`
+	Into string   `json:"into"`
+}
+
+// NormalizeLabel приводит категорию или тег к виду, в котором они
+// хранятся: нижний регистр и одиночные пробелы.
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// ActiveIn сообщает, действует ли подписка в месяце month.
+func (s *Subscription) ActiveIn(month time.Time) bool {
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := s.StartDate.Time()
+	if time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC).After(month) {
+		return false
+	}
+	if s.EndDate == nil {
+		return true
+	}
+	end := s.EndDate.Time()
+	return !time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC).Before(month)
+}
+
+// Within повторяет period <@ daterange(from, to, '[]') из отчета о сумме:
+// подписка целиком лежит в периоде, бессрочная не входит в период с концом.
+// nil-граница не ограничивает.
+func (s *Subscription) Within(from, to *time.Time) bool {
+	if from != nil && s.StartDate.Time().Before(*from) {
+		return false
+	}
+	if to != nil && (s.EndDate == nil || s.EndDate.Time().After(*to)) {
+		return false
+	}
+	return true
+}
+
+// ApplyPriceChanges строит Prices из начальной цены (Price) и изменений,
+// отсортированных по месяцу, и заменяет Price ценой месяца now. Изменение
+// не позже начала подписки заменяет начальную цену.
+func (s *Subscription) ApplyPriceChanges(changes []PricePeriod, now time.Time) {
+	if s.Price == nil {
+		return
+	}
+
+	start := s.StartDate.Time()
+	s.Prices = []PricePeriod{{EffectiveFrom: MonthYear(time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)), Price: *s.Price}}
+	for _, change := range changes {
+		if change.EffectiveFrom.Time().After(s.Prices[0].EffectiveFrom.Time()) {
+			s.Prices = append(s.Prices, change)
+		} else {
+			s.Prices[0].Price = change.Price
+		}
+	}
+
+	price := s.PriceIn(now)
+	s.Price = &price
+}
+
+// PriceIn возвращает цену, действовавшую в месяце month. До начала
+// подписки действует цена первого периода.
+func (s *Subscription) PriceIn(month time.Time) int {
+	if len(s.Prices) == 0 {
+		if s.Price == nil {
+			return 0
+		}
+		return *s.Price
+	}
+
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	price := s.Prices[0].Price
+	for _, period := range s.Prices[1:] {
+		if period.EffectiveFrom.Time().After(month) {
+			break
+		}
+		price = period.Price
+	}
+	return price
+}
+
+// ChargeIn возвращает сумму к оплате за месяц month: ноль в пробный период,
+// цену со скидкой в промо-период, иначе цену месяца.
+func (s *Subscription) ChargeIn(month time.Time) int {
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if s.TrialEnd != nil && !month.After(s.TrialEnd.Time()) {
+		return 0
+	}
+
+	price := s.PriceIn(month)
+	if s.Promo == nil {
+		return price
+	}
+	from, to := s.PromoPeriod()
+	if month.Before(from) || !month.Before(to) {
+		return price
+	}
+	return s.Promo.Apply(price)
+}
+
+// PromoPeriod возвращает первый месяц промо-периода и первый месяц после
+// него.
+func (s *Subscription) PromoPeriod() (time.Time, time.Time) {
+	start := s.StartDate.Time()
+	from := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if s.TrialEnd != nil {
+		end := s.TrialEnd.Time()
+		from = time.Date(end.Year(), end.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if s.Promo == nil {
+		return from, from
+	}
+	return from, from.AddDate(0, s.Promo.Months, 0)
+}
+
+// ReportMonth - месяц, за который подписка входит в суммарную стоимость:
+// ее последний месяц, у бессрочной - первый месяц, после которого сумма к
+// оплате не меняется (конец пробного и промо-периода, последнее изменение
+// цены). Так сумма за период не зависит от текущей даты.
+func (s *Subscription) ReportMonth() time.Time {
+	if s.EndDate != nil {
+		return s.EndDate.Time()
+	}
+
+	start := s.StartDate.Time()
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if s.TrialEnd != nil {
+		end := s.TrialEnd.Time()
+		month = later(month, time.Date(end.Year(), end.Month()+1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	if s.Promo != nil {
+		_, to := s.PromoPeriod()
+		month = later(month, to)
+	}
+	if len(s.Prices) > 0 {
+		month = later(month, s.Prices[len(s.Prices)-1].EffectiveFrom.Time())
+	}
+	return month
+}
+
+// ReportPrice - сумма к оплате за ReportMonth.
+func (s *Subscription) ReportPrice() int {
+	return s.ChargeIn(s.ReportMonth())
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// SubscriptionStats — сводка по подпискам, активным в одном месяце.
+type SubscriptionStats struct {
+	Active       int
+	MonthlySpend int
+}
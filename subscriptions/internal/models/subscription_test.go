@@ -0,0 +1,203 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func month(year int, m time.Month) MonthYear {
+	return MonthYear(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func monthPtr(year int, m time.Month) *MonthYear {
+	v := month(year, m)
+	return &v
+}
+
+// testSubscription - подписка с января 2025 по цене 1000 и ее изменениями.
+func testSubscription(changes ...PricePeriod) *Subscription {
+	price := 1000
+	sub := &Subscription{Price: &price, StartDate: month(2025, time.January)}
+	sub.ApplyPriceChanges(changes, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC))
+	return sub
+}
+
+func TestReportMonth(t *testing.T) {
+	tests := []struct {
+		name  string
+		sub   func() *Subscription
+		month MonthYear
+		price int
+	}{
+		{
+			name:  "с окончанием",
+			sub:   func() *Subscription { s := testSubscription(); s.EndDate = monthPtr(2025, time.June); return s },
+			month: month(2025, time.June),
+			price: 1000,
+		},
+		{
+			name:  "бессрочная без изменений",
+			sub:   func() *Subscription { return testSubscription() },
+			month: month(2025, time.January),
+			price: 1000,
+		},
+		{
+			name: "бессрочная с пробным периодом",
+			sub: func() *Subscription {
+				s := testSubscription()
+				s.TrialEnd = monthPtr(2025, time.March)
+				return s
+			},
+			month: month(2025, time.April),
+			price: 1000,
+		},
+		{
+			name: "бессрочная с промо после пробного периода",
+			sub: func() *Subscription {
+				s := testSubscription()
+				s.TrialEnd = monthPtr(2025, time.February)
+				s.Promo = &Promo{Type: PromoPercentage, Value: 50, Months: 3}
+				return s
+			},
+			month: month(2025, time.June),
+			price: 1000,
+		},
+		{
+			name: "бессрочная с запланированной ценой",
+			sub: func() *Subscription {
+				return testSubscription(PricePeriod{EffectiveFrom: month(2040, time.May), Price: 1500})
+			},
+			month: month(2040, time.May),
+			price: 1500,
+		},
+		{
+			name: "окончание раньше изменения цены",
+			sub: func() *Subscription {
+				s := testSubscription(PricePeriod{EffectiveFrom: month(2025, time.May), Price: 1500})
+				s.EndDate = monthPtr(2025, time.April)
+				return s
+			},
+			month: month(2025, time.April),
+			price: 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := tt.sub()
+			if got := sub.ReportMonth(); !got.Equal(tt.month.Time()) {
+				t.Errorf("ReportMonth() = %s, want %s", got.Format("01-2006"), tt.month.Time().Format("01-2006"))
+			}
+			if got := sub.ReportPrice(); got != tt.price {
+				t.Errorf("ReportPrice() = %d, want %d", got, tt.price)
+			}
+		})
+	}
+}
+
+func TestChargeIn(t *testing.T) {
+	tests := []struct {
+		name  string
+		sub   func() *Subscription
+		month MonthYear
+		want  int
+	}{
+		{name: "без скидок", sub: func() *Subscription { return testSubscription() }, month: month(2025, time.March), want: 1000},
+		{
+			name:  "пробный период включает последний месяц",
+			sub:   func() *Subscription { s := testSubscription(); s.TrialEnd = monthPtr(2025, time.February); return s },
+			month: month(2025, time.February),
+			want:  0,
+		},
+		{
+			name:  "после пробного периода",
+			sub:   func() *Subscription { s := testSubscription(); s.TrialEnd = monthPtr(2025, time.February); return s },
+			month: month(2025, time.March),
+			want:  1000,
+		},
+		{
+			name: "процентное промо",
+			sub: func() *Subscription {
+				s := testSubscription()
+				s.Promo = &Promo{Type: PromoPercentage, Value: 25, Months: 2}
+				return s
+			},
+			month: month(2025, time.February),
+			want:  750,
+		},
+		{
+			name: "после промо",
+			sub: func() *Subscription {
+				s := testSubscription()
+				s.Promo = &Promo{Type: PromoPercentage, Value: 25, Months: 2}
+				return s
+			},
+			month: month(2025, time.March),
+			want:  1000,
+		},
+		{
+			name: "фиксированное промо",
+			sub: func() *Subscription {
+				s := testSubscription()
+				s.Promo = &Promo{Type: PromoFixed, Value: 199, Months: 1}
+				return s
+			},
+			month: month(2025, time.January),
+			want:  199,
+		},
+		{
+			name: "промо начинается после пробного периода",
+			sub: func() *Subscription {
+				s := testSubscription()
+				s.TrialEnd = monthPtr(2025, time.January)
+				s.Promo = &Promo{Type: PromoPercentage, Value: 50, Months: 1}
+				return s
+			},
+			month: month(2025, time.February),
+			want:  500,
+		},
+		{
+			name: "процент округляется вниз",
+			sub: func() *Subscription {
+				price := 999
+				return &Subscription{Price: &price, StartDate: month(2025, time.January), Promo: &Promo{Type: PromoPercentage, Value: 33, Months: 1}}
+			},
+			month: month(2025, time.January),
+			want:  669,
+		},
+		{
+			name: "изменение цены",
+			sub: func() *Subscription {
+				return testSubscription(PricePeriod{EffectiveFrom: month(2025, time.March), Price: 700})
+			},
+			month: month(2025, time.March),
+			want:  700,
+		},
+		{
+			name: "промо от измененной цены",
+			sub: func() *Subscription {
+				s := testSubscription(PricePeriod{EffectiveFrom: month(2025, time.February), Price: 999})
+				s.Promo = &Promo{Type: PromoPercentage, Value: 10, Months: 3}
+				return s
+			},
+			month: month(2025, time.February),
+			want:  899,
+		},
+		{
+			name: "до начала подписки",
+			sub: func() *Subscription {
+				return testSubscription(PricePeriod{EffectiveFrom: month(2025, time.March), Price: 700})
+			},
+			month: month(2024, time.December),
+			want:  1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sub().ChargeIn(tt.month.Time()); got != tt.want {
+				t.Errorf("ChargeIn(%s) = %d, want %d", tt.month.Time().Format("01-2006"), got, tt.want)
+			}
+		})
+	}
+}
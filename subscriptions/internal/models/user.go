@@ -0,0 +1,71 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Настройки нового пользователя, если они не переданы.
+const (
+	DefaultCurrency = "RUB"
+	DefaultLocale   = "ru-RU"
+	DefaultTimeZone = "Europe/Moscow"
+)
+
+type User struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Email string    `json:"email,omitempty"`
+	// Currency - код ISO 4217. Цены подписок хранятся без валюты: цена
+	// подписки на сервис каталога указана в валюте сервиса, остальные -
+	// в валюте их пользователя.
+	Currency string `json:"currency"`
+	Locale   string `json:"locale"`
+	// TimeZone - имя из базы IANA; по нему определяется текущий месяц
+	// пользователя.
+	TimeZone  string    `json:"time_zone"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// UserUpdate - частичное изменение пользователя: nil-поля не меняются.
+type UserUpdate struct {
+	Name     *string `json:"name"`
+	Email    *string `json:"email"`
+	Currency *string `json:"currency"`
+	Locale   *string `json:"locale"`
+	TimeZone *string `json:"time_zone"`
+}
+
+// UserSummary - итоги по подпискам пользователя за его текущий месяц.
+type UserSummary struct {
+	UserID              uuid.UUID `json:"user_id"`
+	Currency            string    `json:"currency"`
+	Month               MonthYear `json:"month"`
+	Subscriptions       int       `json:"subscriptions"`
+	ActiveSubscriptions int       `json:"active_subscriptions"`
+	// MonthlyTotal включает только подписки в валюте пользователя.
+	// Курсов в сервисе нет, поэтому подписки в других валютах не
+	// пересчитываются, а суммируются по валютам в OtherCurrencies.
+	MonthlyTotal    int            `json:"monthly_total"`
+	OtherCurrencies map[string]int `json:"other_currencies,omitempty"`
+}
+
+// Apply переносит заданные поля изменения в user.
+func (u UserUpdate) Apply(user *User) {
+	if u.Name != nil {
+		user.Name = *u.Name
+	}
+	if u.Email != nil {
+		user.Email = *u.Email
+	}
+	if u.Currency != nil {
+		user.Currency = *u.Currency
+	}
+	if u.Locale != nil {
+		user.Locale = *u.Locale
+	}
+	if u.TimeZone != nil {
+		user.TimeZone = *u.TimeZone
+	}
+}
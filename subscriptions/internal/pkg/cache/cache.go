@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/fx"
+	"log/slog"
+	"time"
+)
+
+// Backend хранит закэшированные значения и версии тегов. Записи не удаляются
+// при изменении данных: ключ включает версии тегов, от которых зависит
+// значение, и Bump делает старые ключи недостижимыми. Так запись, посчитанная
+// до изменения и сохраненная после него, не может вернуться читателю.
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+	// Versions возвращает версии тегов в том же порядке; у незнакомого тега версия 0.
+	Versions(ctx context.Context, tags ...string) ([]uint64, error)
+	Bump(ctx context.Context, tags ...string) error
+}
+
+type Params struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    Config
+	Logger    *slog.Logger
+}
+
+func NewBackend(params Params) (Backend, error) {
+	switch params.Config.Backend {
+	case BackendMemory:
+		params.Logger.Info("cache backend: memory")
+		return NewMemory(params.Config.Size, params.Config.TTL), nil
+	case BackendRedis:
+		return NewRedis(params)
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", params.Config.Backend)
+	}
+}
+
+// versionTTL - сколько хранится версия тега после последнего Bump. Она
+// должна пережить все записи, собранные из прежних версий, иначе после
+// сброса версии в 0 такие записи снова станут достижимы.
+func versionTTL(ttl time.Duration) time.Duration {
+	return 2 * ttl
+}
@@ -0,0 +1,26 @@
+package cache
+
+import "time"
+
+const (
+	BackendNone   = "none"
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+type Config struct {
+	// Backend none отключает кэш. memory держит записи в процессе и не видит
+	// записи других экземпляров до истечения TTL; redis общий для всех.
+	Backend string        `yaml:"backend" env:"CACHE_BACKEND" env-default:"none"`
+	TTL     time.Duration `yaml:"ttl" env:"CACHE_TTL" env-default:"1m"`
+	// Size ограничивает число записей в памяти процесса.
+	Size  int         `yaml:"size" env:"CACHE_SIZE" env-default:"10000"`
+	Redis RedisConfig `yaml:"redis"`
+}
+
+type RedisConfig struct {
+	Address   string `yaml:"address" env:"REDIS_ADDRESS" env-default:"localhost:6379"`
+	Password  string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB        int    `yaml:"db" env:"REDIS_DB"`
+	KeyPrefix string `yaml:"keyPrefix" env-default:"subscriptions:"`
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory - LRU с TTL в памяти процесса.
+type Memory struct {
+	size int
+	ttl  time.Duration
+
+	mu       sync.Mutex
+	items    map[string]*list.Element
+	order    *list.List // в начале - последние использованные
+	versions map[string]memoryVersion
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+type memoryVersion struct {
+	value   uint64
+	expires time.Time
+}
+
+func NewMemory(size int, ttl time.Duration) *Memory {
+	return &Memory{
+		size:     size,
+		ttl:      ttl,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		versions: make(map[string]memoryVersion),
+	}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := elem.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		m.order.Remove(elem)
+		delete(m.items, key)
+		return nil, false, nil
+	}
+
+	m.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expires := time.Now().Add(m.ttl)
+	if elem, ok := m.items[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value, entry.expires = value, expires
+		m.order.MoveToFront(elem)
+		return nil
+	}
+
+	m.items[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryEntry).key)
+	}
+
+	return nil
+}
+
+func (m *Memory) Versions(_ context.Context, tags ...string) ([]uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	versions := make([]uint64, len(tags))
+	for i, tag := range tags {
+		if v, ok := m.versions[tag]; ok && now.Before(v.expires) {
+			versions[i] = v.value
+		}
+	}
+	return versions, nil
+}
+
+func (m *Memory) Bump(_ context.Context, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	expires := now.Add(versionTTL(m.ttl))
+	for _, tag := range tags {
+		v := m.versions[tag]
+		if now.After(v.expires) {
+			v.value = 0
+		}
+		m.versions[tag] = memoryVersion{value: v.value + 1, expires: expires}
+	}
+
+	// Версии тегов живут вне LRU; устаревшие вычищаются, когда их
+	// становится больше, чем записей.
+	if len(m.versions) > m.size {
+		for tag, v := range m.versions {
+			if now.After(v.expires) {
+				delete(m.versions, tag)
+			}
+		}
+	}
+
+	return nil
+}
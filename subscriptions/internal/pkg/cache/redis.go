@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+type Redis struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedis(params Params) (*Redis, error) {
+	cfg := params.Config.Redis
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Address,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := client.Ping(ctx).Err(); err != nil {
+				return fmt.Errorf("failed to connect to Redis: %w", err)
+			}
+			params.Logger.Info("cache backend: redis", slog.String("address", cfg.Address))
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			return client.Close()
+		},
+	})
+
+	return &Redis{client: client, prefix: cfg.KeyPrefix, ttl: params.Config.TTL}, nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte) error {
+	return r.client.Set(ctx, r.prefix+key, value, r.ttl).Err()
+}
+
+func (r *Redis) Versions(ctx context.Context, tags ...string) ([]uint64, error) {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = r.versionKey(tag)
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]uint64, len(tags))
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if versions[i], err = strconv.ParseUint(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid version of tag %s: %w", tags[i], err)
+		}
+	}
+	return versions, nil
+}
+
+func (r *Redis) Bump(ctx context.Context, tags ...string) error {
+	ttl := versionTTL(r.ttl)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, tag := range tags {
+			pipe.Incr(ctx, r.versionKey(tag))
+			pipe.PExpire(ctx, r.versionKey(tag), ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *Redis) versionKey(tag string) string {
+	return r.prefix + "version:" + tag
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestRedis(t *testing.T, ttl time.Duration) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return &Redis{client: client, prefix: "test:", ttl: ttl}, server
+}
+
+func TestRedisGetSet(t *testing.T) {
+	ctx := context.Background()
+	r, server := newTestRedis(t, time.Minute)
+
+	if _, ok, err := r.Get(ctx, "key"); err != nil || ok {
+		t.Fatalf("Get before Set = %v, %v", ok, err)
+	}
+
+	if err := r.Set(ctx, "key", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	value, ok, err := r.Get(ctx, "key")
+	if err != nil || !ok || string(value) != "value" {
+		t.Fatalf("Get = %q, %v, %v", value, ok, err)
+	}
+	if !server.Exists("test:key") {
+		t.Error("key is stored without prefix")
+	}
+
+	server.FastForward(time.Minute)
+	if _, ok, err := r.Get(ctx, "key"); err != nil || ok {
+		t.Errorf("Get after TTL = %v, %v", ok, err)
+	}
+}
+
+func TestRedisVersions(t *testing.T) {
+	ctx := context.Background()
+	r, server := newTestRedis(t, time.Minute)
+
+	versions, err := r.Versions(ctx, "a", "b")
+	if err != nil || !slices.Equal(versions, []uint64{0, 0}) {
+		t.Fatalf("Versions of unknown tags = %v, %v", versions, err)
+	}
+
+	if err := r.Bump(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Bump(ctx, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	versions, err = r.Versions(ctx, "b", "a")
+	if err != nil || !slices.Equal(versions, []uint64{1, 2}) {
+		t.Fatalf("Versions = %v, %v, want [1 2]", versions, err)
+	}
+
+	// Версия живет дольше записей, собранных из прежних версий.
+	if ttl := server.TTL("test:version:a"); ttl != versionTTL(time.Minute) {
+		t.Errorf("version TTL = %s, want %s", ttl, versionTTL(time.Minute))
+	}
+
+	if err := server.Set("test:version:c", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Versions(ctx, "c"); err == nil {
+		t.Error("Versions accepted a non-numeric version")
+	}
+}
+
+func TestRedisUnavailable(t *testing.T) {
+	ctx := context.Background()
+	r, server := newTestRedis(t, time.Minute)
+	server.Close()
+
+	if _, _, err := r.Get(ctx, "key"); err == nil {
+		t.Error("Get without Redis returned no error")
+	}
+	if _, err := r.Versions(ctx, "a"); err == nil {
+		t.Error("Versions without Redis returned no error")
+	}
+	if err := r.Bump(ctx, "a"); err == nil {
+		t.Error("Bump without Redis returned no error")
+	}
+}
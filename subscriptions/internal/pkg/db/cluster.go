@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type primaryKey struct{}
+
+// WithPrimary помечает контекст так, что чтения идут в primary.
+// Нужен для read-your-writes, когда отставание реплики недопустимо.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// UsePrimary сообщает, помечен ли контекст WithPrimary.
+func UsePrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// Pool - часть *pgxpool.Pool, которая нужна кластеру и менеджеру
+// транзакций.
+type Pool interface {
+	Querier
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	Ping(ctx context.Context) error
+	Close()
+}
+
+type replica struct {
+	addr    string
+	pool    Pool
+	healthy atomic.Bool
+}
+
+// Cluster распределяет запросы между primary и репликами. Записи
+// всегда идут в primary, чтения - по кругу в здоровые реплики.
+type Cluster struct {
+	primary  Pool
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+type ClusterParams struct {
+	fx.In
+
+	Lifecycle      fx.Lifecycle
+	Cfg            Config
+	Logger         *slog.Logger
+	Primary        *pgxpool.Pool
+	TracerProvider trace.TracerProvider
+}
+
+func NewCluster(p ClusterParams) (*Cluster, error) {
+	cluster := &Cluster{primary: p.Primary}
+
+	for _, addr := range p.Cfg.Replicas {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replica address %s: %w", addr, err)
+		}
+		portNum, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replica port %s: %w", addr, err)
+		}
+
+		replicaCfg := p.Cfg
+		replicaCfg.Host, replicaCfg.Port = host, uint16(portNum)
+
+		poolConfig, err := replicaCfg.PoolConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse replica %s config: %w", addr, err)
+		}
+		poolConfig.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTracerProvider(p.TracerProvider))
+
+		// Пул подключается лениво: недоступная реплика не мешает старту,
+		// она просто будет помечена нездоровой при проверке.
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create replica %s pool: %w", addr, err)
+		}
+		cluster.replicas = append(cluster.replicas, &replica{addr: addr, pool: pool})
+	}
+
+	if len(cluster.replicas) == 0 {
+		return cluster, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			cluster.checkReplicas(ctx, p.Cfg.ReplicaCheckTimeout, p.Logger)
+
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(p.Cfg.ReplicaCheckPeriod)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						cluster.checkReplicas(ctx, p.Cfg.ReplicaCheckTimeout, p.Logger)
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			for _, r := range cluster.replicas {
+				r.pool.Close()
+			}
+			p.Logger.Info("closed replica pools")
+			return nil
+		},
+	})
+
+	p.Logger.Info("created replica pools: " + strconv.Itoa(len(cluster.replicas)))
+	return cluster, nil
+}
+
+func (c *Cluster) Primary() Pool {
+	return c.primary
+}
+
+// Writer возвращает транзакцию из контекста или primary.
+func (c *Cluster) Writer(ctx context.Context) Querier {
+	if pgTx, ok := txFrom(ctx); ok {
+		return pgTx
+	}
+	return c.primary
+}
+
+// Reader возвращает пул для read-only запроса. Если реплик нет, все они
+// недоступны или контекст помечен WithPrimary, используется primary.
+// Внутри транзакции чтение идет в ней же.
+func (c *Cluster) Reader(ctx context.Context) Querier {
+	if pgTx, ok := txFrom(ctx); ok {
+		return pgTx
+	}
+	if len(c.replicas) == 0 || UsePrimary(ctx) {
+		return c.primary
+	}
+
+	// Очередь идет по здоровым репликам: иначе после недоступной реплики
+	// следующая получала бы и ее долю чтений.
+	var healthy uint64
+	for _, r := range c.replicas {
+		if r.healthy.Load() {
+			healthy++
+		}
+	}
+	if healthy == 0 {
+		return c.primary
+	}
+
+	n := c.next.Add(1) % healthy
+	for _, r := range c.replicas {
+		if !r.healthy.Load() {
+			continue
+		}
+		if n == 0 {
+			return r.pool
+		}
+		n--
+	}
+	// Реплика стала нездоровой между проходами.
+	return c.primary
+}
+
+func (c *Cluster) checkReplicas(ctx context.Context, timeout time.Duration, log *slog.Logger) {
+	var wg sync.WaitGroup
+	for _, r := range c.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+
+			pingCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			err := r.pool.Ping(pingCtx)
+			healthy := err == nil
+			if r.healthy.Swap(healthy) != healthy {
+				if healthy {
+					log.Info("replica " + r.addr + " is healthy")
+				} else {
+					log.Warn("replica " + r.addr + " is unhealthy: " + err.Error())
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+}
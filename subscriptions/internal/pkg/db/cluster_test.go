@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePool отвечает на Ping заданной ошибкой. Запросы к нему не
+// выполняются: тесты проверяют только выбор пула.
+type fakePool struct {
+	Querier
+
+	name string
+
+	mu      sync.Mutex
+	pingErr error
+}
+
+func (p *fakePool) BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *fakePool) Ping(context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pingErr
+}
+
+func (p *fakePool) Close() {}
+
+func (p *fakePool) setPingErr(err error) {
+	p.mu.Lock()
+	p.pingErr = err
+	p.mu.Unlock()
+}
+
+// fakeTx - pgx.Tx, который можно положить в контекст через tx.Run.
+type fakeTx struct {
+	pgx.Tx
+}
+
+func (fakeTx) Commit(context.Context) error   { return nil }
+func (fakeTx) Rollback(context.Context) error { return nil }
+
+// newTestCluster собирает кластер из primary и здоровых реплик.
+func newTestCluster(replicas ...*fakePool) *Cluster {
+	cluster := &Cluster{primary: &fakePool{name: "primary"}}
+	for _, pool := range replicas {
+		r := &replica{addr: pool.name, pool: pool}
+		r.healthy.Store(true)
+		cluster.replicas = append(cluster.replicas, r)
+	}
+	return cluster
+}
+
+func poolName(q Querier) string {
+	if pool, ok := q.(*fakePool); ok {
+		return pool.name
+	}
+	return "unknown"
+}
+
+func TestClusterReaderRoundRobin(t *testing.T) {
+	a, b, c := &fakePool{name: "a"}, &fakePool{name: "b"}, &fakePool{name: "c"}
+	cluster := newTestCluster(a, b, c)
+
+	var got []string
+	for range 6 {
+		got = append(got, poolName(cluster.Reader(context.Background())))
+	}
+
+	want := []string{"b", "c", "a", "b", "c", "a"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("readers = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestClusterReaderSpreadsOverHealthy(t *testing.T) {
+	tests := []struct {
+		name      string
+		unhealthy []string
+		want      map[string]int
+	}{
+		{name: "все здоровы", want: map[string]int{"a": 4, "b": 4, "c": 4}},
+		{name: "первая недоступна", unhealthy: []string{"a"}, want: map[string]int{"b": 6, "c": 6}},
+		{name: "средняя недоступна", unhealthy: []string{"b"}, want: map[string]int{"a": 6, "c": 6}},
+		{name: "осталась одна", unhealthy: []string{"a", "c"}, want: map[string]int{"b": 12}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newTestCluster(&fakePool{name: "a"}, &fakePool{name: "b"}, &fakePool{name: "c"})
+			for _, r := range cluster.replicas {
+				if slices.Contains(tt.unhealthy, r.addr) {
+					r.healthy.Store(false)
+				}
+			}
+
+			got := make(map[string]int)
+			for range 12 {
+				got[poolName(cluster.Reader(context.Background()))]++
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("readers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterRouting(t *testing.T) {
+	tests := []struct {
+		name      string
+		replicas  []*fakePool
+		unhealthy []string
+		ctx       func(ctx context.Context) context.Context
+		reader    string
+	}{
+		{name: "без реплик", reader: "primary"},
+		{name: "здоровая реплика", replicas: []*fakePool{{name: "a"}}, reader: "a"},
+		{name: "WithPrimary", replicas: []*fakePool{{name: "a"}}, ctx: WithPrimary, reader: "primary"},
+		{name: "нездоровая реплика пропускается", replicas: []*fakePool{{name: "a"}, {name: "b"}}, unhealthy: []string{"a"}, reader: "b"},
+		{name: "все реплики нездоровы", replicas: []*fakePool{{name: "a"}, {name: "b"}}, unhealthy: []string{"a", "b"}, reader: "primary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newTestCluster(tt.replicas...)
+			for _, r := range cluster.replicas {
+				for _, name := range tt.unhealthy {
+					if r.addr == name {
+						r.healthy.Store(false)
+					}
+				}
+			}
+
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+
+			for range 3 {
+				if got := poolName(cluster.Reader(ctx)); got != tt.reader {
+					t.Errorf("Reader = %s, want %s", got, tt.reader)
+				}
+			}
+			if got := poolName(cluster.Writer(ctx)); got != "primary" {
+				t.Errorf("Writer = %s, want primary", got)
+			}
+		})
+	}
+}
+
+func TestClusterInTx(t *testing.T) {
+	cluster := newTestCluster(&fakePool{name: "a"})
+	begin := func(context.Context) (tx.Tx, error) { return fakeTx{}, nil }
+
+	err := tx.Run(context.Background(), tx.Config{}, begin, func(error) bool { return false }, func(ctx context.Context) error {
+		if _, ok := cluster.Reader(ctx).(pgx.Tx); !ok {
+			t.Error("Reader inside tx is not the transaction")
+		}
+		if _, ok := cluster.Writer(ctx).(pgx.Tx); !ok {
+			t.Error("Writer inside tx is not the transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClusterFailover(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	a, b := &fakePool{name: "a"}, &fakePool{name: "b"}
+	cluster := newTestCluster(a, b)
+
+	readers := func() map[string]int {
+		counts := make(map[string]int)
+		for range 4 {
+			counts[poolName(cluster.Reader(ctx))]++
+		}
+		return counts
+	}
+
+	steps := []struct {
+		name string
+		aErr error
+		bErr error
+		want map[string]int
+	}{
+		{name: "обе реплики здоровы", want: map[string]int{"a": 2, "b": 2}},
+		{name: "реплика a недоступна", aErr: errors.New("connection refused"), want: map[string]int{"b": 4}},
+		{name: "обе реплики недоступны", aErr: errors.New("connection refused"), bErr: errors.New("timeout"), want: map[string]int{"primary": 4}},
+		{name: "реплика a вернулась", bErr: errors.New("timeout"), want: map[string]int{"a": 4}},
+		{name: "обе реплики вернулись", want: map[string]int{"a": 2, "b": 2}},
+	}
+
+	for _, step := range steps {
+		a.setPingErr(step.aErr)
+		b.setPingErr(step.bErr)
+		cluster.checkReplicas(ctx, time.Second, log)
+
+		if got := readers(); !maps.Equal(got, step.want) {
+			t.Errorf("%s: readers = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
@@ -3,10 +3,43 @@ package db
 import "time"
 
 type Config struct {
-	DB             string        `env:"POSTGRES_DB"`
-	User           string        `env:"POSTGRES_USER"`
-	Password       string        `env:"POSTGRES_PASSWORD"`
-	Host           string        `env:"POSTGRES_HOST"`
-	Port           uint16        `env:"POSTGRES_PORT"`
+	DB             string        `yaml:"name" env:"POSTGRES_DB"`
+	User           string        `yaml:"user" env:"POSTGRES_USER"`
+	Password       string        `yaml:"password" env:"POSTGRES_PASSWORD" secret:"true"`
+	Host           string        `yaml:"host" env:"POSTGRES_HOST"`
+	Port           uint16        `yaml:"port" env:"POSTGRES_PORT" env-default:"5432"`
 	ConnectTimeout time.Duration `yaml:"connectTimeout" env-default:"5m"`
+
+	MaxConns          int32         `yaml:"maxConns" env:"POSTGRES_MAX_CONNS" env-default:"10"`
+	MinConns          int32         `yaml:"minConns" env:"POSTGRES_MIN_CONNS"`
+	MaxConnLifetime   time.Duration `yaml:"maxConnLifetime" env-default:"1h"`
+	MaxConnIdleTime   time.Duration `yaml:"maxConnIdleTime" env-default:"30m"`
+	HealthCheckPeriod time.Duration `yaml:"healthCheckPeriod" env-default:"1m"`
+	// StatementTimeout 0 оставляет значение сервера.
+	StatementTimeout time.Duration `yaml:"statementTimeout" env:"POSTGRES_STATEMENT_TIMEOUT"`
+	ApplicationName  string        `yaml:"applicationName" env-default:"subscriptions"`
+
+	TLS TLSConfig `yaml:"tls"`
+
+	// Replicas - адреса host:port реплик для read-only запросов.
+	// Учетные данные и настройки пула общие с primary.
+	Replicas            []string      `yaml:"replicas" env:"POSTGRES_REPLICAS" env-separator:","`
+	ReplicaCheckPeriod  time.Duration `yaml:"replicaCheckPeriod" env-default:"5s"`
+	ReplicaCheckTimeout time.Duration `yaml:"replicaCheckTimeout" env-default:"1s"`
+}
+
+// TLSConfig повторяет параметры libpq: sslmode, sslrootcert, sslcert, sslkey.
+type TLSConfig struct {
+	Mode     string `yaml:"mode" env:"POSTGRES_SSLMODE" env-default:"disable"`
+	CAFile   string `yaml:"caFile" env:"POSTGRES_SSLROOTCERT"`
+	CertFile string `yaml:"certFile" env:"POSTGRES_SSLCERT"`
+	KeyFile  string `yaml:"keyFile" env:"POSTGRES_SSLKEY"`
+}
+
+var SSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// SQLiteConfig задает файл базы для хранилища sqlite.
+type SQLiteConfig struct {
+	Path        string        `yaml:"path" env:"SQLITE_PATH" env-default:"data/subscriptions.db"`
+	BusyTimeout time.Duration `yaml:"busyTimeout" env:"SQLITE_BUSY_TIMEOUT" env-default:"5s"`
 }
@@ -2,43 +2,81 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
-	"github.com/jackc/pgx/v5"
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/jackc/pgx/v5/stdlib"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
 )
 
 type PostgresParams struct {
 	fx.In
 
-	Cfg    Config
-	Logger *slog.Logger
+	Lifecycle      fx.Lifecycle
+	Cfg            Config
+	Logger         *slog.Logger
+	TracerProvider trace.TracerProvider
 }
 
-func getConnStr(cfg *Config) string {
-	return fmt.Sprintf(
-		"postgresql://%s:%s@%s:%d/%s?sslmode=disable",
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.DB,
-	)
+// DSN собирает строку подключения через net/url, поэтому спецсимволы
+// в логине и пароле экранируются.
+func (c Config) DSN() string {
+	query := url.Values{}
+	query.Set("sslmode", c.TLS.Mode)
+	if c.TLS.CAFile != "" {
+		query.Set("sslrootcert", c.TLS.CAFile)
+	}
+	if c.TLS.CertFile != "" {
+		query.Set("sslcert", c.TLS.CertFile)
+	}
+	if c.TLS.KeyFile != "" {
+		query.Set("sslkey", c.TLS.KeyFile)
+	}
+	if c.ApplicationName != "" {
+		query.Set("application_name", c.ApplicationName)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(int(c.Port))),
+		Path:     "/" + c.DB,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
 }
 
-func NewPostgresPool(p PostgresParams) (*pgxpool.Pool, error) {
-	connStr := getConnStr(&p.Cfg)
+// PoolConfig переносит настройки пула из конфига в pgxpool.Config.
+func (c Config) PoolConfig() (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(c.DSN())
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.MaxConns = c.MaxConns
+	poolConfig.MinConns = c.MinConns
+	poolConfig.MaxConnLifetime = c.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = c.MaxConnIdleTime
+	poolConfig.HealthCheckPeriod = c.HealthCheckPeriod
+	if c.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	}
+
+	return poolConfig, nil
+}
 
-	poolConfig, err := pgxpool.ParseConfig(connStr)
+func NewPostgresPool(p PostgresParams) (*pgxpool.Pool, error) {
+	poolConfig, err := p.Cfg.PoolConfig()
 	if err != nil {
 		p.Logger.Error("pgxpool parse config: " + err.Error())
-		return nil, fmt.Errorf("failed to parse connStr: %w", err)
+		return nil, fmt.Errorf("failed to parse pool config: %w", err)
 	}
 
-	poolConfig.MaxConns = 10
+	poolConfig.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTracerProvider(p.TracerProvider))
 
 	ctx, cancel := context.WithTimeout(context.Background(), p.Cfg.ConnectTimeout)
 	defer cancel()
@@ -49,34 +87,21 @@ func NewPostgresPool(p PostgresParams) (*pgxpool.Pool, error) {
 		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
 	}
 
-	err = pool.Ping(context.Background())
+	err = pool.Ping(ctx)
 	if err != nil {
+		pool.Close()
 		p.Logger.Error("pgxpool ping: " + err.Error())
 		return nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
 	}
 
+	p.Lifecycle.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			pool.Close()
+			p.Logger.Info("closed pgx pool")
+			return nil
+		},
+	})
+
 	p.Logger.Info("created pgx pool")
 	return pool, nil
 }
-
-func NewPostgresConnect(p PostgresParams) (*sql.DB, error) {
-	connStr := getConnStr(&p.Cfg)
-
-	pgxConfig, err := pgx.ParseConfig(connStr)
-	if err != nil {
-		p.Logger.Error("pgxConfig parse config: " + err.Error())
-		return nil, fmt.Errorf("failed to parse DSN: %w", err)
-	}
-
-	db := stdlib.OpenDB(*pgxConfig)
-
-	ctx, cancel := context.WithTimeout(context.Background(), p.Cfg.ConnectTimeout)
-	defer cancel()
-
-	if err = db.PingContext(ctx); err != nil {
-		p.Logger.Error("db ping failed: " + err.Error())
-		return nil, fmt.Errorf("failed to ping DB: %w", err)
-	}
-	p.Logger.Info("connected to PostgreSQL")
-	return db, nil
-}
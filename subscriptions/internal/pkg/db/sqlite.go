@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"go.uber.org/fx"
+	"log/slog"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SQLiteNow пишет время в том же формате, что и DEFAULT в схемах SQLite.
+const SQLiteNow = "strftime('%Y-%m-%dT%H:%M:%fZ', 'now')"
+
+type SQLiteParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Cfg       SQLiteConfig
+	Logger    *slog.Logger
+}
+
+// DSN собирает строку подключения для драйвера modernc.org/sqlite.
+// WAL позволяет читать во время записи, а _txlock=immediate берет блокировку
+// записи в начале транзакции, а не при первом изменении.
+func (c SQLiteConfig) DSN() string {
+	query := url.Values{}
+	query.Add("_pragma", "busy_timeout("+strconv.FormatInt(c.BusyTimeout.Milliseconds(), 10)+")")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Set("_txlock", "immediate")
+
+	return "file:" + c.Path + "?" + query.Encode()
+}
+
+func NewSQLite(params SQLiteParams) (*sql.DB, error) {
+	if dir := filepath.Dir(params.Cfg.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create SQLite directory: %w", err)
+		}
+	}
+
+	sqliteDB, err := sql.Open("sqlite", params.Cfg.DSN())
+	if err != nil {
+		params.Logger.Error("sqlite open: " + err.Error())
+		return nil, fmt.Errorf("failed to open SQLite: %w", err)
+	}
+	// SQLite допускает одного писателя; одно соединение исключает
+	// SQLITE_BUSY между своими же запросами.
+	sqliteDB.SetMaxOpenConns(1)
+
+	if err := sqliteDB.Ping(); err != nil {
+		sqliteDB.Close()
+		params.Logger.Error("sqlite ping: " + err.Error())
+		return nil, fmt.Errorf("failed to open SQLite %s: %w", params.Cfg.Path, err)
+	}
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			err := sqliteDB.Close()
+			params.Logger.Info("closed SQLite")
+			return err
+		},
+	})
+
+	params.Logger.Info("opened SQLite", slog.String("path", params.Cfg.Path))
+	return sqliteDB, nil
+}
+
+// SQLiteQuerier - методы, общие у *sql.DB и *sql.Tx.
+type SQLiteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// SQLiteConn возвращает транзакцию из контекста или саму базу.
+func SQLiteConn(ctx context.Context, sqliteDB *sql.DB) SQLiteQuerier {
+	if t, ok := tx.From(ctx); ok {
+		if sqlTx, ok := t.(sqliteTx); ok {
+			return sqlTx.Tx
+		}
+	}
+	return sqliteDB
+}
+
+type SQLiteTxManagerParams struct {
+	fx.In
+
+	DB     *sql.DB
+	Config tx.Config
+}
+
+// SQLiteTxManager открывает транзакции SQLite. Уровень изоляции не
+// настраивается: SQLite всегда выполняет транзакции как serializable.
+// Повтор нужен, если блокировку записи не удалось получить за busy_timeout.
+type SQLiteTxManager struct {
+	db  *sql.DB
+	cfg tx.Config
+}
+
+func NewSQLiteTxManager(params SQLiteTxManagerParams) *SQLiteTxManager {
+	return &SQLiteTxManager{db: params.DB, cfg: params.Config}
+}
+
+func (m *SQLiteTxManager) Do(ctx context.Context, opts tx.Options, fn func(ctx context.Context) error) error {
+	begin := func(ctx context.Context) (tx.Tx, error) {
+		sqlTx, err := m.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: opts.ReadOnly})
+		if err != nil {
+			return nil, err
+		}
+		return sqliteTx{sqlTx}, nil
+	}
+	return tx.Run(ctx, m.cfg, begin, isBusy, fn)
+}
+
+type sqliteTx struct {
+	*sql.Tx
+}
+
+func (t sqliteTx) Commit(context.Context) error {
+	return t.Tx.Commit()
+}
+
+func (t sqliteTx) Rollback(context.Context) error {
+	return t.Tx.Rollback()
+}
+
+func isBusy(err error) bool {
+	sqliteErr := &sqlite.Error{}
+	return errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY
+}
+
+// SQLiteViolation сопоставляет нарушение ограничения SQLite с ошибкой
+// домена. Code - расширенный код SQLITE_CONSTRAINT_*; пустой Constraint
+// подходит к любому тексту ошибки.
+type SQLiteViolation struct {
+	Code       int
+	Constraint string
+	Err        error
+}
+
+// SQLiteViolations проверяются по порядку, выигрывает первое совпадение.
+type SQLiteViolations []SQLiteViolation
+
+// Map возвращает ошибку домена или nil, если err не нарушение ограничения
+// из списка.
+func (v SQLiteViolations) Map(err error) error {
+	sqliteErr := &sqlite.Error{}
+	if !errors.As(err, &sqliteErr) {
+		return nil
+	}
+
+	for _, violation := range v {
+		if sqliteErr.Code() == violation.Code && strings.Contains(sqliteErr.Error(), violation.Constraint) {
+			return violation.Err
+		}
+	}
+	return nil
+}
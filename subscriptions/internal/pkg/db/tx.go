@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/fx"
+)
+
+// Querier - методы, общие у пула и pgx.Tx.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+var isoLevels = map[tx.Isolation]pgx.TxIsoLevel{
+	tx.ReadCommitted:  pgx.ReadCommitted,
+	tx.RepeatableRead: pgx.RepeatableRead,
+	tx.Serializable:   pgx.Serializable,
+}
+
+type TxManagerParams struct {
+	fx.In
+
+	Cluster *Cluster
+	Config  tx.Config
+}
+
+// PostgresTxManager открывает транзакции в primary и повторяет их при
+// serialization_failure и deadlock_detected.
+type PostgresTxManager struct {
+	pool Pool
+	cfg  tx.Config
+}
+
+func NewPostgresTxManager(params TxManagerParams) *PostgresTxManager {
+	return &PostgresTxManager{
+		pool: params.Cluster.Primary(),
+		cfg:  params.Config,
+	}
+}
+
+func (m *PostgresTxManager) Do(ctx context.Context, opts tx.Options, fn func(ctx context.Context) error) error {
+	txOptions := pgx.TxOptions{IsoLevel: isoLevels[opts.Isolation]}
+	if opts.ReadOnly {
+		txOptions.AccessMode = pgx.ReadOnly
+	}
+
+	begin := func(ctx context.Context) (tx.Tx, error) {
+		return m.pool.BeginTx(ctx, txOptions)
+	}
+	return tx.Run(ctx, m.cfg, begin, isSerializationFailure, fn)
+}
+
+func isSerializationFailure(err error) bool {
+	pgErr := &pgconn.PgError{}
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+func txFrom(ctx context.Context) (pgx.Tx, bool) {
+	t, ok := tx.From(ctx)
+	if !ok {
+		return nil, false
+	}
+	pgTx, ok := t.(pgx.Tx)
+	return pgTx, ok
+}
@@ -0,0 +1,26 @@
+// Package errs содержит виды ошибок, общие для всех сервисов.
+package errs
+
+import "errors"
+
+// ErrInvalidArgument - неверные входные данные. Пакеты сервисов
+// экспортируют его под своим именем, чтобы delivery не зависел от errs.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// invalidArgumentError сохраняет исходный текст ошибки, но сопоставляется
+// с ErrInvalidArgument через errors.Is.
+type invalidArgumentError struct {
+	msg string
+}
+
+func InvalidArgument(msg string) error {
+	return &invalidArgumentError{msg: msg}
+}
+
+func (e *invalidArgumentError) Error() string {
+	return e.msg
+}
+
+func (e *invalidArgumentError) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
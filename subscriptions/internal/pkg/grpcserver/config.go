@@ -0,0 +1,7 @@
+package grpcserver
+
+type Config struct {
+	Address    string   `yaml:"address" env:"GRPC_ADDRESS" env-default:"localhost:9090"`
+	Reflection bool     `yaml:"reflection" env:"GRPC_REFLECTION"`
+	AuthTokens []string `yaml:"authTokens" env:"GRPC_AUTH_TOKENS" env-separator:"," secret:"true"`
+}
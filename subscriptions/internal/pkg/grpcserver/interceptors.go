@@ -0,0 +1,108 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const reflectionServicePrefix = "/grpc.reflection."
+
+func loggingUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func loggingStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(logger *slog.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	attrs := []any{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Duration("duration", time.Since(start)),
+	}
+
+	switch code {
+	case codes.OK, codes.NotFound, codes.InvalidArgument, codes.AlreadyExists, codes.Unauthenticated:
+		logger.Info("grpc call", attrs...)
+	default:
+		logger.Error("grpc call: "+err.Error(), attrs...)
+	}
+}
+
+// authenticator проверяет bearer-токен из метаданных authorization.
+// Пустой список токенов отключает проверку.
+type authenticator struct {
+	tokens [][]byte
+}
+
+func newAuthenticator(tokens []string) *authenticator {
+	a := &authenticator{}
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			a.tokens = append(a.tokens, []byte(token))
+		}
+	}
+	return a
+}
+
+func (a *authenticator) unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := a.authenticate(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *authenticator) stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.authenticate(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func (a *authenticator) authenticate(ctx context.Context, method string) error {
+	if len(a.tokens) == 0 || strings.HasPrefix(method, reflectionServicePrefix) {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return status.Error(codes.Unauthenticated, "authorization token is required")
+	}
+
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authorization must use Bearer scheme")
+	}
+
+	for _, allowed := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), allowed) == 1 {
+			return nil
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "invalid authorization token")
+}
@@ -0,0 +1,81 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	subscriptionsv1 "github.com/ekkserapopova/subscriptions/api/subscriptions/v1"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"log/slog"
+	"net"
+)
+
+type Params struct {
+	fx.In
+
+	Lifecycle           fx.Lifecycle
+	Config              Config
+	Logger              *slog.Logger
+	SubscriptionService subscriptionsv1.SubscriptionServiceServer
+}
+
+func RunServer(p Params) {
+	srv := newServer(p)
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			listener, err := net.Listen("tcp", p.Config.Address)
+			if err != nil {
+				return fmt.Errorf("failed to listen grpc address %s: %w", p.Config.Address, err)
+			}
+
+			go func() {
+				if err := srv.Serve(listener); err != nil {
+					p.Logger.Error("grpc server: " + err.Error())
+				}
+			}()
+
+			p.Logger.Info("grpc server started on " + listener.Addr().String())
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				srv.Stop()
+			}
+			return nil
+		},
+	})
+}
+
+// newServer собирает сервер с перехватчиками и зарегистрированными
+// сервисами.
+func newServer(p Params) *grpc.Server {
+	auth := newAuthenticator(p.Config.AuthTokens)
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			loggingUnaryInterceptor(p.Logger),
+			auth.unary(),
+		),
+		grpc.ChainStreamInterceptor(
+			loggingStreamInterceptor(p.Logger),
+			auth.stream(),
+		),
+	)
+
+	subscriptionsv1.RegisterSubscriptionServiceServer(srv, p.SubscriptionService)
+	if p.Config.Reflection {
+		reflection.Register(srv)
+	}
+
+	return srv
+}
@@ -0,0 +1,83 @@
+package grpcserver
+
+import (
+	"context"
+	subscriptionsv1 "github.com/ekkserapopova/subscriptions/api/subscriptions/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+)
+
+type echoService struct {
+	subscriptionsv1.UnimplementedSubscriptionServiceServer
+}
+
+func (echoService) GetSubscription(_ context.Context, req *subscriptionsv1.GetSubscriptionRequest) (*subscriptionsv1.Subscription, error) {
+	return &subscriptionsv1.Subscription{Id: req.GetId()}, nil
+}
+
+// dial запускает сервер в памяти и возвращает клиента к нему.
+func dial(t *testing.T, config Config) subscriptionsv1.SubscriptionServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := newServer(Params{
+		Config:              config,
+		Logger:              slog.New(slog.NewTextHandler(io.Discard, nil)),
+		SubscriptionService: echoService{},
+	})
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return subscriptionsv1.NewSubscriptionServiceClient(conn)
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	tests := []struct {
+		name          string
+		tokens        []string
+		authorization string
+		code          codes.Code
+	}{
+		{name: "проверка отключена", code: codes.OK},
+		{name: "пустые токены не включают проверку", tokens: []string{" ", ""}, code: codes.OK},
+		{name: "без токена", tokens: []string{"secret"}, code: codes.Unauthenticated},
+		{name: "не Bearer", tokens: []string{"secret"}, authorization: "Basic secret", code: codes.Unauthenticated},
+		{name: "неверный токен", tokens: []string{"secret"}, authorization: "Bearer wrong", code: codes.Unauthenticated},
+		{name: "верный токен", tokens: []string{"secret"}, authorization: "Bearer secret", code: codes.OK},
+		{name: "второй из токенов", tokens: []string{"secret", "other"}, authorization: "Bearer other", code: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dial(t, Config{AuthTokens: tt.tokens})
+
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.authorization)
+			}
+
+			_, err := client.GetSubscription(ctx, &subscriptionsv1.GetSubscriptionRequest{Id: "1"})
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("code = %s, want %s (%v)", code, tt.code, err)
+			}
+		})
+	}
+}
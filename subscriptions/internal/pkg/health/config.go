@@ -0,0 +1,8 @@
+package health
+
+import "time"
+
+type Config struct {
+	CheckTimeout time.Duration `yaml:"checkTimeout" env-default:"2s"`
+	DrainDelay   time.Duration `yaml:"drainDelay" env:"HEALTH_DRAIN_DELAY" env-default:"5s"`
+}
@@ -0,0 +1,171 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/migrations"
+	"github.com/ekkserapopova/subscriptions/pkg/responser"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type Params struct {
+	fx.In
+
+	Config Config
+	Logger *slog.Logger
+	// Pool отсутствует при хранении данных в памяти и в SQLite.
+	Pool *pgxpool.Pool `optional:"true"`
+	// SQLite есть только при storage: sqlite.
+	SQLite *sql.DB `optional:"true"`
+}
+
+type Handler struct {
+	cfg          Config
+	log          *slog.Logger
+	pool         *pgxpool.Pool
+	sqlite       *sql.DB
+	shuttingDown atomic.Bool
+}
+
+type ComponentStatus struct {
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Version *uint  `json:"version,omitempty"`
+	Expect  *uint  `json:"expected_version,omitempty"`
+}
+
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+func NewHandler(params Params) *Handler {
+	return &Handler{
+		cfg:    params.Config,
+		log:    params.Logger,
+		pool:   params.Pool,
+		sqlite: params.SQLite,
+	}
+}
+
+type DrainParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Handler   *Handler
+}
+
+// RunDrain переводит сервис в состояние not-ready при остановке приложения и
+// ждет DrainDelay, чтобы балансировщик успел вывести экземпляр из ротации до
+// закрытия серверов и соединений. Должен вызываться последним в fx.Invoke,
+// чтобы его OnStop выполнялся первым.
+func RunDrain(params DrainParams) {
+	params.Lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			params.Handler.shuttingDown.Store(true)
+			params.Handler.log.Info("readiness switched to not ready, draining")
+
+			select {
+			case <-time.After(params.Handler.cfg.DrainDelay):
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+}
+
+// Healthz сообщает, что процесс жив.
+func (h *Handler) Healthz(w http.ResponseWriter, _ *http.Request) {
+	responser.SendOK(w, http.StatusOK, map[string]string{"status": StatusUp})
+}
+
+// Readyz проверяет зависимости, без которых сервис не может обслуживать запросы.
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.CheckTimeout)
+	defer cancel()
+
+	report := Report{
+		Status: StatusUp,
+		Components: map[string]ComponentStatus{
+			"shutdown": h.checkShutdown(),
+		},
+	}
+	if h.pool != nil {
+		report.Components["postgres"] = h.checkPostgres(ctx)
+		report.Components["migrations"] = h.checkMigrations(ctx)
+	}
+	if h.sqlite != nil {
+		report.Components["sqlite"] = h.checkSQLite(ctx)
+	}
+
+	code := http.StatusOK
+	for _, component := range report.Components {
+		if component.Status != StatusUp {
+			report.Status = StatusDown
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	responser.SendOK(w, code, report)
+}
+
+func (h *Handler) checkShutdown() ComponentStatus {
+	if h.shuttingDown.Load() {
+		return ComponentStatus{Status: StatusDown, Error: "shutting down"}
+	}
+	return ComponentStatus{Status: StatusUp}
+}
+
+func (h *Handler) checkPostgres(ctx context.Context) ComponentStatus {
+	if err := h.pool.Ping(ctx); err != nil {
+		h.log.Warn("readiness postgres ping: " + err.Error())
+		return ComponentStatus{Status: StatusDown, Error: err.Error()}
+	}
+	return ComponentStatus{Status: StatusUp}
+}
+
+func (h *Handler) checkSQLite(ctx context.Context) ComponentStatus {
+	if err := h.sqlite.PingContext(ctx); err != nil {
+		h.log.Warn("readiness sqlite ping: " + err.Error())
+		return ComponentStatus{Status: StatusDown, Error: err.Error()}
+	}
+	return ComponentStatus{Status: StatusUp}
+}
+
+func (h *Handler) checkMigrations(ctx context.Context) ComponentStatus {
+	expected, err := migrations.LatestVersion()
+	if err != nil {
+		return ComponentStatus{Status: StatusDown, Error: err.Error()}
+	}
+
+	version, dirty, err := migrations.CurrentVersion(ctx, h.pool)
+	if err != nil {
+		h.log.Warn("readiness migrations check: " + err.Error())
+		return ComponentStatus{Status: StatusDown, Error: err.Error(), Expect: &expected}
+	}
+
+	status := ComponentStatus{Status: StatusUp, Version: &version, Expect: &expected}
+	switch {
+	case dirty:
+		status.Status = StatusDown
+		status.Error = fmt.Sprintf("schema version %d is dirty", version)
+	// Более новая версия допустима: во время выкладки схему мог обновить
+	// экземпляр с новой сборкой.
+	case version < expected:
+		status.Status = StatusDown
+		status.Error = fmt.Sprintf("schema version %d is behind expected %d", version, expected)
+	}
+
+	return status
+}
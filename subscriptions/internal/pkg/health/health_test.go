@@ -0,0 +1,168 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeConnector подменяет базу: отдает соединение, ошибку или ждет
+// отмены контекста, как зависшая сеть.
+type fakeConnector struct {
+	err   error
+	block bool
+}
+
+func (c fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return fakeConn{}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("not supported")
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func newTestHandler(t *testing.T, connector *fakeConnector) *Handler {
+	t.Helper()
+	params := Params{
+		Config: Config{CheckTimeout: 50 * time.Millisecond},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if connector != nil {
+		params.SQLite = sql.OpenDB(*connector)
+		t.Cleanup(func() { _ = params.SQLite.Close() })
+	}
+	return NewHandler(params)
+}
+
+func readyz(t *testing.T, h *Handler) (int, Report) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode %s: %v", w.Body, err)
+	}
+	return w.Code, report
+}
+
+func TestHealthz(t *testing.T) {
+	w := httptest.NewRecorder()
+	newTestHandler(t, nil).Healthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"up"`) {
+		t.Errorf("healthz = %d %s", w.Code, w.Body)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	tests := []struct {
+		name         string
+		connector    *fakeConnector
+		shuttingDown bool
+		code         int
+		status       string
+		down         string
+		error        string
+	}{
+		{name: "без базы", code: http.StatusOK, status: StatusUp},
+		{name: "база доступна", connector: &fakeConnector{}, code: http.StatusOK, status: StatusUp},
+		{
+			name:      "база недоступна",
+			connector: &fakeConnector{err: errors.New("unable to open database file")},
+			code:      http.StatusServiceUnavailable,
+			status:    StatusDown,
+			down:      "sqlite",
+			error:     "unable to open database file",
+		},
+		{
+			name:         "остановка",
+			connector:    &fakeConnector{},
+			shuttingDown: true,
+			code:         http.StatusServiceUnavailable,
+			status:       StatusDown,
+			down:         "shutdown",
+			error:        "shutting down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, tt.connector)
+			h.shuttingDown.Store(tt.shuttingDown)
+
+			code, report := readyz(t, h)
+			if code != tt.code || report.Status != tt.status {
+				t.Fatalf("readyz = %d %s, want %d %s", code, report.Status, tt.code, tt.status)
+			}
+			if _, ok := report.Components["sqlite"]; ok != (tt.connector != nil) {
+				t.Errorf("sqlite checked = %v, want %v", ok, tt.connector != nil)
+			}
+			for name, component := range report.Components {
+				want := StatusUp
+				if name == tt.down {
+					want = StatusDown
+				}
+				if component.Status != want {
+					t.Errorf("%s = %+v, want %s", name, component, want)
+				}
+			}
+			if tt.down != "" && !strings.Contains(report.Components[tt.down].Error, tt.error) {
+				t.Errorf("%s error = %q, want %q", tt.down, report.Components[tt.down].Error, tt.error)
+			}
+		})
+	}
+}
+
+func TestReadyzCheckTimeout(t *testing.T) {
+	h := newTestHandler(t, &fakeConnector{block: true})
+
+	start := time.Now()
+	code, report := readyz(t, h)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("readyz took %s with check timeout %s", elapsed, h.cfg.CheckTimeout)
+	}
+
+	if code != http.StatusServiceUnavailable || report.Components["sqlite"].Status != StatusDown {
+		t.Fatalf("readyz = %d %+v, want sqlite down", code, report)
+	}
+	if err := report.Components["sqlite"].Error; !strings.Contains(err, context.DeadlineExceeded.Error()) {
+		t.Errorf("sqlite error = %q, want deadline exceeded", err)
+	}
+}
@@ -0,0 +1,8 @@
+package metrics
+
+import "time"
+
+type Config struct {
+	Path            string        `yaml:"path" env-default:"/metrics"`
+	RefreshInterval time.Duration `yaml:"refreshInterval" env-default:"1m"`
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"github.com/ekkserapopova/subscriptions/pkg/responser"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware считает запросы и их длительность по шаблону маршрута mux,
+// чтобы ID в пути не порождали новые серии.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := responser.NewRecorder(w)
+
+		next.ServeHTTP(rw, r)
+
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		status := strconv.Itoa(rw.Status())
+		m.httpRequests.WithLabelValues(route, r.Method, status).Inc()
+		m.httpRequestDuration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+const namespace = "subscriptions"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	queryDuration       *prometheus.HistogramVec
+	cacheRequests       *prometheus.CounterVec
+
+	activeSubscriptions prometheus.Gauge
+	monthlySpend        prometheus.Gauge
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route template, method and status.",
+		}, []string{"route", "method", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_query_duration_seconds",
+			Help:      "Repository query latency by method.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"method"}),
+		cacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_requests_total",
+			Help:      "Repository cache lookups by method and result (hit or miss).",
+		}, []string{"method", "result"}),
+		activeSubscriptions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "active_subscriptions",
+			Help:      "Number of subscriptions active in the current month.",
+		}),
+		monthlySpend: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "monthly_recurring_spend",
+			Help:      "Total price of subscriptions active in the current month.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.queryDuration,
+		m.cacheRequests,
+		m.activeSubscriptions,
+		m.monthlySpend,
+	)
+
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Register добавляет сторонний коллектор, например статистику пула соединений.
+func (m *Metrics) Register(c prometheus.Collector) error {
+	return m.registry.Register(c)
+}
+
+// ObserveQuery записывает длительность запроса репозитория. Используется
+// через defer: defer metrics.ObserveQuery("GetSubscriptionByID", time.Now()).
+func (m *Metrics) ObserveQuery(method string, start time.Time) {
+	m.queryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// ObserveCache считает попадания и промахи кэша репозитория.
+func (m *Metrics) ObserveCache(method string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheRequests.WithLabelValues(method, result).Inc()
+}
+
+func (m *Metrics) SetSubscriptionStats(active, monthlySpend int) {
+	m.activeSubscriptions.Set(float64(active))
+	m.monthlySpend.Set(float64(monthlySpend))
+}
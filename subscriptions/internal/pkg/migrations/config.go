@@ -0,0 +1,7 @@
+package migrations
+
+type Config struct {
+	// DisableAuto отключает миграции при старте сервера; схему тогда
+	// обновляют отдельно через cmd/migrate.
+	DisableAuto bool `yaml:"disableAuto" env:"MIGRATIONS_DISABLE_AUTO"`
+}
@@ -1,20 +1,26 @@
 package migrations
 
 import (
-	"database/sql"
+	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"go.uber.org/fx"
+	"io/fs"
 	"log/slog"
 )
 
 type Params struct {
 	fx.In
 
-	DB     *sql.DB
+	Config Config
+	Pool   *pgxpool.Pool
 	Logger *slog.Logger
 }
 
@@ -22,41 +28,209 @@ type Params struct {
 var migrationFiles embed.FS
 
 func RunMigrations(params Params) error {
-	sourceDriver, err := iofs.New(migrationFiles, "schema")
+	if params.Config.DisableAuto {
+		params.Logger.Info("auto migrations are disabled")
+		return nil
+	}
+
+	m, err := New(params.Pool)
+	if err != nil {
+		params.Logger.Error("failed to initialize migrations: " + err.Error())
+		return err
+	}
+
+	if err := m.Up(); err != nil {
+		m.Close()
+		params.Logger.Error("failed to run migrations: " + err.Error())
+		return err
+	}
+
+	if err := m.Close(); err != nil {
+		params.Logger.Error("failed to close migrations: " + err.Error())
+		return err
+	}
+
+	params.Logger.Info("migrations done")
+	return nil
+}
+
+// Migrator управляет схемой через golang-migrate поверх встроенных
+// файлов и общего подключения.
+type Migrator struct {
+	m     *migrate.Migrate
+	files fs.FS
+}
+
+func New(pool *pgxpool.Pool) (*Migrator, error) {
+	files, err := fs.Sub(migrationFiles, "schema")
+	if err != nil {
+		return nil, err
+	}
+
+	sourceDriver, err := iofs.New(files, ".")
 	if err != nil {
-		params.Logger.Error("failed to load migration files: ", err.Error())
-		return fmt.Errorf("failed to initialize migrations source driver: %w", err)
+		return nil, fmt.Errorf("failed to initialize migrations source driver: %w", err)
 	}
 
-	dbDriver, err := postgres.WithInstance(params.DB, &postgres.Config{})
+	// Закрытие *sql.DB поверх пула не закрывает сам пул.
+	dbDriver, err := postgres.WithInstance(stdlib.OpenDBFromPool(pool), &postgres.Config{})
 	if err != nil {
-		params.Logger.Error("failed to initialize postgres driver: ", err.Error())
-		return fmt.Errorf("failed to initialize postgres driver: %w", err)
+		sourceDriver.Close()
+		return nil, fmt.Errorf("failed to initialize postgres driver: %w", err)
 	}
 
 	m, err := migrate.NewWithInstance("iofs", sourceDriver, "postgres", dbDriver)
 	if err != nil {
-		params.Logger.Error("failed to initialize migrate instance: ", err.Error())
-		return fmt.Errorf("failed to initialize migrate instance: %w", err)
+		sourceDriver.Close()
+		dbDriver.Close()
+		return nil, fmt.Errorf("failed to initialize migrate instance: %w", err)
 	}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		params.Logger.Error("failed to run migrations: ", err.Error())
-		return fmt.Errorf("failed to run migrations: %w", err)
+	return &Migrator{m: m, files: files}, nil
+}
+
+// Up применяет все новые миграции.
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.m.Up(), "up")
+}
+
+// Down откатывает все миграции.
+func (m *Migrator) Down() error {
+	return ignoreNoChange(m.m.Down(), "down")
+}
+
+// Steps применяет n миграций вперед или -n назад.
+func (m *Migrator) Steps(n int) error {
+	return ignoreNoChange(m.m.Steps(n), "steps")
+}
+
+// Goto переводит схему на версию version вверх или вниз.
+func (m *Migrator) Goto(version uint) error {
+	return ignoreNoChange(m.m.Migrate(version), "goto")
+}
+
+// Force записывает версию без выполнения миграций и снимает флаг dirty.
+// Используется после ручного исправления упавшей миграции;
+// -1 означает "ни одной миграции не применено".
+func (m *Migrator) Force(version int) error {
+	if err := m.m.Force(version); err != nil {
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+	return nil
+}
+
+// Version возвращает текущую версию схемы; 0 - миграции не применялись.
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
 	}
+	return version, dirty, nil
+}
+
+type Status struct {
+	Version uint
+	Applied bool
+	// Dirty выставляется у текущей версии, если ее миграция упала.
+	Dirty bool
+}
 
-	err = sourceDriver.Close()
+// Status перечисляет встроенные миграции и отмечает примененные.
+func (m *Migrator) Status() ([]Status, error) {
+	current, dirty, err := m.Version()
 	if err != nil {
-		params.Logger.Error("failed to close source driver: ", err.Error())
-		return fmt.Errorf("failed to close source driver: %w", err)
+		return nil, err
 	}
 
-	err = dbDriver.Close()
+	versions, err := sourceVersions(m.files)
 	if err != nil {
-		params.Logger.Error("failed to close db driver: ", err.Error())
-		return fmt.Errorf("failed to close db driver: %w", err)
+		return nil, err
 	}
 
-	params.Logger.Info("migrations done")
+	statuses := make([]Status, 0, len(versions))
+	for _, version := range versions {
+		statuses = append(statuses, Status{
+			Version: version,
+			Applied: version <= current,
+			Dirty:   dirty && version == current,
+		})
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	if err := errors.Join(sourceErr, dbErr); err != nil {
+		return fmt.Errorf("failed to close migrate instance: %w", err)
+	}
 	return nil
 }
+
+func ignoreNoChange(err error, op string) error {
+	if err == nil || errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return fmt.Errorf("failed to migrate %s: %w", op, err)
+}
+
+// LatestVersion возвращает номер последней встроенной миграции — версию схемы,
+// которую ожидает текущая сборка.
+func LatestVersion() (uint, error) {
+	files, err := fs.Sub(migrationFiles, "schema")
+	if err != nil {
+		return 0, err
+	}
+
+	versions, err := sourceVersions(files)
+	if err != nil {
+		return 0, err
+	}
+	return versions[len(versions)-1], nil
+}
+
+func sourceVersions(files fs.FS) ([]uint, error) {
+	sourceDriver, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize migrations source driver: %w", err)
+	}
+	defer sourceDriver.Close()
+
+	version, err := sourceDriver.First()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read first migration: %w", err)
+	}
+
+	versions := []uint{version}
+	for {
+		next, err := sourceDriver.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read next migration: %w", err)
+		}
+		versions = append(versions, next)
+		version = next
+	}
+}
+
+// CurrentVersion возвращает версию схемы, записанную golang-migrate в базе.
+func CurrentVersion(ctx context.Context, pool *pgxpool.Pool) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return uint(version), dirty, nil
+}
@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS subscription_event_id_seq;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE SEQUENCE IF NOT EXISTS subscription_event_id_seq;
\ No newline at end of file
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS subscriptions_period_idx;
+DROP INDEX IF EXISTS subscriptions_dates_idx;
+DROP INDEX IF EXISTS subscriptions_service_name_idx;
+DROP INDEX IF EXISTS subscriptions_user_id_idx;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS period,
+    DROP CONSTRAINT IF EXISTS subscriptions_end_date_after_start,
+    DROP CONSTRAINT IF EXISTS subscriptions_price_non_negative;
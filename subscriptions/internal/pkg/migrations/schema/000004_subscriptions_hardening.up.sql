@@ -0,0 +1,11 @@
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_price_non_negative CHECK (price >= 0),
+    ADD CONSTRAINT subscriptions_end_date_after_start CHECK (end_date IS NULL OR end_date >= start_date),
+    ADD COLUMN period DATERANGE GENERATED ALWAYS AS (daterange(start_date, end_date, '[]')) STORED,
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS subscriptions_user_id_idx ON subscriptions (user_id);
+CREATE INDEX IF NOT EXISTS subscriptions_service_name_idx ON subscriptions (service_name);
+CREATE INDEX IF NOT EXISTS subscriptions_dates_idx ON subscriptions (start_date, end_date);
+CREATE INDEX IF NOT EXISTS subscriptions_period_idx ON subscriptions USING GIST (period);
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_user_id_fkey;
+
+DROP TABLE IF EXISTS users;
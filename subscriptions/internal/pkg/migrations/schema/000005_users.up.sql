@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS users(
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    email TEXT,
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    locale TEXT NOT NULL DEFAULT 'ru-RU',
+    time_zone TEXT NOT NULL DEFAULT 'Europe/Moscow',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT users_currency_format CHECK (currency ~ '^[A-Z]{3}$')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx ON users (lower(email)) WHERE email IS NOT NULL;
+
+-- Пользователи уже существующих подписок заводятся с настройками по умолчанию.
+INSERT INTO users (id)
+SELECT DISTINCT user_id FROM subscriptions
+ON CONFLICT (id) DO NOTHING;
+
+-- Пользователя с подписками удалить нельзя: каскад выполняется явно
+-- через DELETE /users/{id}?cascade=true.
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS subscription_split_shares;
+DROP TABLE IF EXISTS subscription_splits;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
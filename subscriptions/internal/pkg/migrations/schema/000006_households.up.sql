@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS households(
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Удаленный пользователь выходит из всех домохозяйств.
+CREATE TABLE IF NOT EXISTS household_members(
+    household_id UUID NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (household_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS household_members_user_id_idx ON household_members (user_id);
+
+-- Подписка делится не больше чем в одном домохозяйстве. Плательщик - ее
+-- владелец, его доля не хранится: это остаток цены.
+CREATE TABLE IF NOT EXISTS subscription_splits(
+    subscription_id UUID PRIMARY KEY REFERENCES subscriptions (id) ON DELETE CASCADE,
+    household_id UUID NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT subscription_splits_rule_valid CHECK (rule IN ('equal', 'percentage', 'fixed'))
+);
+
+CREATE INDEX IF NOT EXISTS subscription_splits_household_id_idx ON subscription_splits (household_id);
+
+-- Доля пропадает вместе с членством участника в домохозяйстве.
+CREATE TABLE IF NOT EXISTS subscription_split_shares(
+    subscription_id UUID NOT NULL REFERENCES subscription_splits (subscription_id) ON DELETE CASCADE,
+    household_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    value INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (subscription_id, user_id),
+    FOREIGN KEY (household_id, user_id) REFERENCES household_members (household_id, user_id) ON DELETE CASCADE,
+    CONSTRAINT subscription_split_shares_value_non_negative CHECK (value >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS subscription_split_shares_member_idx ON subscription_split_shares (household_id, user_id);
@@ -0,0 +1,19 @@
+-- category - категория подписки: по умолчанию категория сервиса каталога,
+-- пользователь может задать свою.
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS category TEXT NOT NULL DEFAULT '';
+
+UPDATE subscriptions s
+SET category = sv.category
+FROM services sv
+WHERE s.service_id = sv.id AND s.category = '';
+
+CREATE INDEX IF NOT EXISTS subscriptions_category_idx ON subscriptions (category);
+
+-- Теги хранятся в нижнем регистре с одиночными пробелами (models.NormalizeLabel).
+CREATE TABLE IF NOT EXISTS subscription_tags(
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (subscription_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS subscription_tags_tag_idx ON subscription_tags (tag);
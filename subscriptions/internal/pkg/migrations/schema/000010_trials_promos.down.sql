@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS subscriptions_trial_end_idx;
+
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS subscriptions_promo_valid,
+    DROP CONSTRAINT IF EXISTS subscriptions_trial_end_after_start,
+    DROP COLUMN IF EXISTS promo_months,
+    DROP COLUMN IF EXISTS promo_value,
+    DROP COLUMN IF EXISTS promo_type,
+    DROP COLUMN IF EXISTS trial_end;
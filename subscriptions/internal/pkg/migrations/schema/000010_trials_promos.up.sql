@@ -0,0 +1,15 @@
+-- trial_end - последний месяц пробного периода; promo_* - вводная цена на
+-- promo_months месяцев после него (models.Promo), promo_type NULL - без промо.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS trial_end DATE,
+    ADD COLUMN IF NOT EXISTS promo_type TEXT,
+    ADD COLUMN IF NOT EXISTS promo_value INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS promo_months INT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT subscriptions_trial_end_after_start CHECK (trial_end IS NULL OR trial_end >= start_date),
+    ADD CONSTRAINT subscriptions_promo_valid CHECK (
+        promo_type IS NULL
+        OR (promo_type = 'percentage' AND promo_value BETWEEN 0 AND 100 AND promo_months > 0)
+        OR (promo_type = 'fixed' AND promo_value >= 0 AND promo_months > 0)
+    );
+
+CREATE INDEX IF NOT EXISTS subscriptions_trial_end_idx ON subscriptions (trial_end) WHERE trial_end IS NOT NULL;
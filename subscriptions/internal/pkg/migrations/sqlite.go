@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"go.uber.org/fx"
+	"io/fs"
+	"log/slog"
+)
+
+type SQLiteParams struct {
+	fx.In
+
+	Config Config
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+// Схема SQLite ведется отдельно: в ней нет daterange и uuid,
+// поэтому номера версий не совпадают с Postgres.
+//
+//go:embed sqlite/*.sql
+var sqliteMigrationFiles embed.FS
+
+func RunSQLiteMigrations(params SQLiteParams) error {
+	if params.Config.DisableAuto {
+		params.Logger.Info("auto migrations are disabled")
+		return nil
+	}
+
+	m, err := NewSQLite(params.DB)
+	if err != nil {
+		params.Logger.Error("failed to initialize migrations: " + err.Error())
+		return err
+	}
+
+	// Close не вызывается: драйвер sqlite закрыл бы общее подключение,
+	// а встроенным файлам закрытие не нужно.
+	if err := m.Up(); err != nil {
+		params.Logger.Error("failed to run migrations: " + err.Error())
+		return err
+	}
+
+	params.Logger.Info("migrations done")
+	return nil
+}
+
+// NewSQLite создает Migrator для базы SQLite. Migrator.Close закрывает db.
+func NewSQLite(db *sql.DB) (*Migrator, error) {
+	files, err := fs.Sub(sqliteMigrationFiles, "sqlite")
+	if err != nil {
+		return nil, err
+	}
+
+	sourceDriver, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize migrations source driver: %w", err)
+	}
+
+	dbDriver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		sourceDriver.Close()
+		return nil, fmt.Errorf("failed to initialize sqlite driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", sourceDriver, "sqlite", dbDriver)
+	if err != nil {
+		sourceDriver.Close()
+		return nil, fmt.Errorf("failed to initialize migrate instance: %w", err)
+	}
+
+	return &Migrator{m: m, files: files}, nil
+}
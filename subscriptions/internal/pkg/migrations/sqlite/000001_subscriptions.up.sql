@@ -0,0 +1,17 @@
+-- Даты хранятся текстом YYYY-MM-DD: такие строки сравниваются так же, как даты.
+CREATE TABLE IF NOT EXISTS subscriptions(
+    id TEXT PRIMARY KEY,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    CONSTRAINT subscriptions_price_non_negative CHECK (price >= 0),
+    CONSTRAINT subscriptions_end_date_after_start CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_user_id_idx ON subscriptions (user_id);
+CREATE INDEX IF NOT EXISTS subscriptions_service_name_idx ON subscriptions (service_name);
+CREATE INDEX IF NOT EXISTS subscriptions_dates_idx ON subscriptions (start_date, end_date);
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS users(
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    email TEXT,
+    currency TEXT NOT NULL DEFAULT 'RUB',
+    locale TEXT NOT NULL DEFAULT 'ru-RU',
+    time_zone TEXT NOT NULL DEFAULT 'Europe/Moscow',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    CONSTRAINT users_currency_format CHECK (length(currency) = 3 AND currency = upper(currency))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx ON users (lower(email)) WHERE email IS NOT NULL;
+
+INSERT OR IGNORE INTO users (id)
+SELECT DISTINCT user_id FROM subscriptions;
+
+-- SQLite не умеет добавлять внешний ключ к существующей таблице,
+-- поэтому subscriptions пересоздается.
+CREATE TABLE subscriptions_new(
+    id TEXT PRIMARY KEY,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    start_date TEXT NOT NULL,
+    end_date TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    CONSTRAINT subscriptions_price_non_negative CHECK (price >= 0),
+    CONSTRAINT subscriptions_end_date_after_start CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+INSERT INTO subscriptions_new SELECT * FROM subscriptions;
+DROP TABLE subscriptions;
+ALTER TABLE subscriptions_new RENAME TO subscriptions;
+
+CREATE INDEX IF NOT EXISTS subscriptions_user_id_idx ON subscriptions (user_id);
+CREATE INDEX IF NOT EXISTS subscriptions_service_name_idx ON subscriptions (service_name);
+CREATE INDEX IF NOT EXISTS subscriptions_dates_idx ON subscriptions (start_date, end_date);
@@ -0,0 +1,7 @@
+-- Канонические названия, записанные в подписки при связывании, остаются.
+DROP INDEX IF EXISTS subscriptions_service_id_idx;
+ALTER TABLE subscriptions DROP COLUMN service_id;
+
+DROP TABLE IF EXISTS service_plans;
+DROP TABLE IF EXISTS service_aliases;
+DROP TABLE IF EXISTS services;
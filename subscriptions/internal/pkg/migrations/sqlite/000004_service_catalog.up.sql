@@ -0,0 +1,184 @@
+CREATE TABLE IF NOT EXISTS services(
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    -- name_key - название в нижнем регистре с одиночными пробелами,
+    -- по нему подписки находят сервис (models.ServiceKey).
+    name_key TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    currency TEXT NOT NULL DEFAULT 'RUB',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    CONSTRAINT services_currency_format CHECK (length(currency) = 3 AND currency = upper(currency))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS services_name_key_idx ON services (name_key);
+CREATE INDEX IF NOT EXISTS services_category_idx ON services (category);
+
+CREATE TABLE IF NOT EXISTS service_aliases(
+    key TEXT PRIMARY KEY,
+    service_id TEXT NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    alias TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS service_aliases_service_id_idx ON service_aliases (service_id);
+
+CREATE TABLE IF NOT EXISTS service_plans(
+    service_id TEXT NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    -- period - период оплаты в месяцах, price - цена за весь период.
+    period INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (service_id, name),
+    CONSTRAINT service_plans_price_non_negative CHECK (price >= 0),
+    CONSTRAINT service_plans_period_positive CHECK (period > 0)
+);
+
+-- Колонка с внешним ключом добавляется без пересоздания subscriptions:
+-- на нее уже ссылаются правила разделения.
+ALTER TABLE subscriptions ADD COLUMN service_id TEXT REFERENCES services (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS subscriptions_service_id_idx ON subscriptions (service_id);
+
+INSERT INTO services (id, name, name_key, category) VALUES
+    ('d2d6addc-cb2f-532a-9735-774327e9bbd5', 'Yandex Plus', 'yandex plus', 'streaming'),
+    ('40c9c4e7-2714-5c5c-86c8-fc148bdc2712', 'Kinopoisk', 'kinopoisk', 'streaming'),
+    ('d071c21e-0270-55a0-85e5-091c086ab76e', 'Okko', 'okko', 'streaming'),
+    ('98506251-caeb-5a5a-8646-9d33bbe18246', 'ivi', 'ivi', 'streaming'),
+    ('56bbfcc2-b7cd-5807-9cd0-0fec7559859f', 'Wink', 'wink', 'streaming'),
+    ('8b08b894-809a-58a5-b769-07dec90cb131', 'START', 'start', 'streaming'),
+    ('efb15e0d-eb9b-5838-af41-d1611c0d18f1', 'Amediateka', 'amediateka', 'streaming'),
+    ('1b51b0c1-3bac-57bb-b962-84cce1331be5', 'Netflix', 'netflix', 'streaming'),
+    ('3b198bf2-4414-5ce0-80d7-f213f73b7d3a', 'YouTube Premium', 'youtube premium', 'streaming'),
+    ('11b49670-c89a-5859-9f7c-9a08eeb86d4a', 'VK Music', 'vk music', 'music'),
+    ('17bb5e6f-c5a7-5752-9d7e-01b9f3f928e2', 'Spotify', 'spotify', 'music'),
+    ('ade39961-6ffc-5609-b1de-3daa33d41c47', 'Telegram Premium', 'telegram premium', 'social'),
+    ('9f2dfc49-b346-5c63-a984-9146c29d2c4d', 'iCloud+', 'icloud+', 'cloud'),
+    ('d9dfb884-ac85-5c62-a81c-4eb66c8b97a0', 'Google One', 'google one', 'cloud'),
+    ('8616b9a3-6d5e-59dd-94df-da3e99c597ca', 'Dropbox', 'dropbox', 'cloud'),
+    ('86e4a39b-f933-5480-923d-e47e205d4ce3', 'Microsoft 365', 'microsoft 365', 'productivity'),
+    ('6b86b4ce-dae2-57b2-bdb5-1bc5cef1c8e7', 'Notion', 'notion', 'productivity'),
+    ('92d04bd0-a91c-5840-8b9f-af3aab3a28a6', 'Figma', 'figma', 'design'),
+    ('3d1518ae-f6aa-512c-9f37-0a65f192a210', 'Adobe Creative Cloud', 'adobe creative cloud', 'design'),
+    ('5343e6c6-3c24-5208-b786-5499f05af49e', 'ChatGPT', 'chatgpt', 'ai'),
+    ('89180b1a-34cf-5dd5-b370-123eb2de9ec1', 'GitHub Copilot', 'github copilot', 'development'),
+    ('fda94074-a011-5840-81d8-25b2ab7bff73', 'JetBrains', 'jetbrains', 'development'),
+    ('d24086e7-cd23-58ba-8453-6098bf405a87', 'Duolingo', 'duolingo', 'education'),
+    ('d3fd17aa-5318-5032-8d55-26a7e003cb9f', 'Litres', 'litres', 'books'),
+    ('5fe4421e-a8d8-5d7b-979d-714d51501ea7', 'Storytel', 'storytel', 'books'),
+    ('22729e06-8a2c-55fb-94a8-0f4ee5b719fd', 'Strava', 'strava', 'fitness');
+
+INSERT INTO service_aliases (key, service_id, alias) VALUES
+    ('яндекс плюс', 'd2d6addc-cb2f-532a-9735-774327e9bbd5', 'Яндекс Плюс'),
+    ('яндекс.плюс', 'd2d6addc-cb2f-532a-9735-774327e9bbd5', 'Яндекс.Плюс'),
+    ('yandex.plus', 'd2d6addc-cb2f-532a-9735-774327e9bbd5', 'Yandex.Plus'),
+    ('плюс мульти', 'd2d6addc-cb2f-532a-9735-774327e9bbd5', 'Плюс Мульти'),
+    ('кинопоиск', '40c9c4e7-2714-5c5c-86c8-fc148bdc2712', 'Кинопоиск'),
+    ('kinopoisk hd', '40c9c4e7-2714-5c5c-86c8-fc148bdc2712', 'Kinopoisk HD'),
+    ('окко', 'd071c21e-0270-55a0-85e5-091c086ab76e', 'Окко'),
+    ('иви', '98506251-caeb-5a5a-8646-9d33bbe18246', 'Иви'),
+    ('ivi.ru', '98506251-caeb-5a5a-8646-9d33bbe18246', 'ivi.ru'),
+    ('винк', '56bbfcc2-b7cd-5807-9cd0-0fec7559859f', 'Винк'),
+    ('старт', '8b08b894-809a-58a5-b769-07dec90cb131', 'Старт'),
+    ('амедиатека', 'efb15e0d-eb9b-5838-af41-d1611c0d18f1', 'Амедиатека'),
+    ('нетфликс', '1b51b0c1-3bac-57bb-b962-84cce1331be5', 'Нетфликс'),
+    ('youtube', '3b198bf2-4414-5ce0-80d7-f213f73b7d3a', 'YouTube'),
+    ('ютуб премиум', '3b198bf2-4414-5ce0-80d7-f213f73b7d3a', 'Ютуб Премиум'),
+    ('youtube premium family', '3b198bf2-4414-5ce0-80d7-f213f73b7d3a', 'YouTube Premium Family'),
+    ('vk музыка', '11b49670-c89a-5859-9f7c-9a08eeb86d4a', 'VK Музыка'),
+    ('вк музыка', '11b49670-c89a-5859-9f7c-9a08eeb86d4a', 'ВК Музыка'),
+    ('boom', '11b49670-c89a-5859-9f7c-9a08eeb86d4a', 'BOOM'),
+    ('спотифай', '17bb5e6f-c5a7-5752-9d7e-01b9f3f928e2', 'Спотифай'),
+    ('spotify family', '17bb5e6f-c5a7-5752-9d7e-01b9f3f928e2', 'Spotify Family'),
+    ('spotify premium', '17bb5e6f-c5a7-5752-9d7e-01b9f3f928e2', 'Spotify Premium'),
+    ('телеграм премиум', 'ade39961-6ffc-5609-b1de-3daa33d41c47', 'Телеграм Премиум'),
+    ('telegram', 'ade39961-6ffc-5609-b1de-3daa33d41c47', 'Telegram'),
+    ('icloud', '9f2dfc49-b346-5c63-a984-9146c29d2c4d', 'iCloud'),
+    ('айклауд', '9f2dfc49-b346-5c63-a984-9146c29d2c4d', 'Айклауд'),
+    ('гугл ван', 'd9dfb884-ac85-5c62-a81c-4eb66c8b97a0', 'Гугл Ван'),
+    ('google drive', 'd9dfb884-ac85-5c62-a81c-4eb66c8b97a0', 'Google Drive'),
+    ('дропбокс', '8616b9a3-6d5e-59dd-94df-da3e99c597ca', 'Дропбокс'),
+    ('office 365', '86e4a39b-f933-5480-923d-e47e205d4ce3', 'Office 365'),
+    ('ms office', '86e4a39b-f933-5480-923d-e47e205d4ce3', 'MS Office'),
+    ('ноушн', '6b86b4ce-dae2-57b2-bdb5-1bc5cef1c8e7', 'Ноушн'),
+    ('фигма', '92d04bd0-a91c-5840-8b9f-af3aab3a28a6', 'Фигма'),
+    ('adobe cc', '3d1518ae-f6aa-512c-9f37-0a65f192a210', 'Adobe CC'),
+    ('adobe', '3d1518ae-f6aa-512c-9f37-0a65f192a210', 'Adobe'),
+    ('chatgpt plus', '5343e6c6-3c24-5208-b786-5499f05af49e', 'ChatGPT Plus'),
+    ('чатгпт', '5343e6c6-3c24-5208-b786-5499f05af49e', 'ЧатГПТ'),
+    ('copilot', '89180b1a-34cf-5dd5-b370-123eb2de9ec1', 'Copilot'),
+    ('jetbrains all products', 'fda94074-a011-5840-81d8-25b2ab7bff73', 'JetBrains All Products'),
+    ('intellij idea', 'fda94074-a011-5840-81d8-25b2ab7bff73', 'IntelliJ IDEA'),
+    ('дуолинго', 'd24086e7-cd23-58ba-8453-6098bf405a87', 'Дуолинго'),
+    ('duolingo super', 'd24086e7-cd23-58ba-8453-6098bf405a87', 'Duolingo Super'),
+    ('литрес', 'd3fd17aa-5318-5032-8d55-26a7e003cb9f', 'ЛитРес'),
+    ('сторител', '5fe4421e-a8d8-5d7b-979d-714d51501ea7', 'Сторител'),
+    ('страва', '22729e06-8a2c-55fb-94a8-0f4ee5b719fd', 'Страва');
+
+INSERT INTO service_plans (service_id, name, price, period) VALUES
+    ('d2d6addc-cb2f-532a-9735-774327e9bbd5', 'Мульти', 399, 1),
+    ('d2d6addc-cb2f-532a-9735-774327e9bbd5', 'Мульти на год', 3990, 12),
+    ('40c9c4e7-2714-5c5c-86c8-fc148bdc2712', 'Базовый', 299, 1),
+    ('d071c21e-0270-55a0-85e5-091c086ab76e', 'Оптимум', 399, 1),
+    ('d071c21e-0270-55a0-85e5-091c086ab76e', 'Премиум', 699, 1),
+    ('98506251-caeb-5a5a-8646-9d33bbe18246', 'Подписка', 399, 1),
+    ('98506251-caeb-5a5a-8646-9d33bbe18246', 'На год', 2990, 12),
+    ('56bbfcc2-b7cd-5807-9cd0-0fec7559859f', 'Трансформер', 349, 1),
+    ('8b08b894-809a-58a5-b769-07dec90cb131', 'Подписка', 299, 1),
+    ('efb15e0d-eb9b-5838-af41-d1611c0d18f1', 'Подписка', 599, 1),
+    ('1b51b0c1-3bac-57bb-b962-84cce1331be5', 'Standard', 999, 1),
+    ('1b51b0c1-3bac-57bb-b962-84cce1331be5', 'Premium', 1499, 1),
+    ('3b198bf2-4414-5ce0-80d7-f213f73b7d3a', 'Individual', 299, 1),
+    ('3b198bf2-4414-5ce0-80d7-f213f73b7d3a', 'Family', 449, 1),
+    ('11b49670-c89a-5859-9f7c-9a08eeb86d4a', 'Подписка', 199, 1),
+    ('11b49670-c89a-5859-9f7c-9a08eeb86d4a', 'Семейная', 299, 1),
+    ('17bb5e6f-c5a7-5752-9d7e-01b9f3f928e2', 'Individual', 169, 1),
+    ('17bb5e6f-c5a7-5752-9d7e-01b9f3f928e2', 'Family', 269, 1),
+    ('ade39961-6ffc-5609-b1de-3daa33d41c47', 'Месяц', 299, 1),
+    ('ade39961-6ffc-5609-b1de-3daa33d41c47', 'Год', 2490, 12),
+    ('9f2dfc49-b346-5c63-a984-9146c29d2c4d', '50 ГБ', 149, 1),
+    ('9f2dfc49-b346-5c63-a984-9146c29d2c4d', '200 ГБ', 299, 1),
+    ('9f2dfc49-b346-5c63-a984-9146c29d2c4d', '2 ТБ', 599, 1),
+    ('d9dfb884-ac85-5c62-a81c-4eb66c8b97a0', '100 ГБ', 139, 1),
+    ('d9dfb884-ac85-5c62-a81c-4eb66c8b97a0', '2 ТБ', 699, 1),
+    ('d9dfb884-ac85-5c62-a81c-4eb66c8b97a0', '2 ТБ на год', 6990, 12),
+    ('8616b9a3-6d5e-59dd-94df-da3e99c597ca', 'Plus', 990, 1),
+    ('86e4a39b-f933-5480-923d-e47e205d4ce3', 'Personal', 549, 1),
+    ('86e4a39b-f933-5480-923d-e47e205d4ce3', 'Family на год', 6990, 12),
+    ('6b86b4ce-dae2-57b2-bdb5-1bc5cef1c8e7', 'Plus', 800, 1),
+    ('92d04bd0-a91c-5840-8b9f-af3aab3a28a6', 'Professional', 1200, 1),
+    ('3d1518ae-f6aa-512c-9f37-0a65f192a210', 'Photography', 990, 1),
+    ('3d1518ae-f6aa-512c-9f37-0a65f192a210', 'All Apps', 5990, 1),
+    ('5343e6c6-3c24-5208-b786-5499f05af49e', 'Plus', 2000, 1),
+    ('89180b1a-34cf-5dd5-b370-123eb2de9ec1', 'Individual', 1000, 1),
+    ('89180b1a-34cf-5dd5-b370-123eb2de9ec1', 'Individual на год', 10000, 12),
+    ('fda94074-a011-5840-81d8-25b2ab7bff73', 'All Products на год', 24900, 12),
+    ('d24086e7-cd23-58ba-8453-6098bf405a87', 'Super', 499, 1),
+    ('d24086e7-cd23-58ba-8453-6098bf405a87', 'Super на год', 3990, 12),
+    ('d3fd17aa-5318-5032-8d55-26a7e003cb9f', 'Абонемент', 399, 1),
+    ('5fe4421e-a8d8-5d7b-979d-714d51501ea7', 'Подписка', 499, 1),
+    ('22729e06-8a2c-55fb-94a8-0f4ee5b719fd', 'Subscription', 449, 1);
+
+-- lower в SQLite знает только латиницу, поэтому кириллические названия
+-- связываются при точном совпадении с псевдонимом.
+UPDATE subscriptions
+SET (service_id, service_name) = (
+    SELECT names.service_id, names.name
+    FROM (
+        SELECT id AS service_id, name, name_key AS key, name AS text FROM services
+        UNION ALL
+        SELECT a.service_id, sv.name, a.key, a.alias FROM service_aliases a JOIN services sv ON sv.id = a.service_id
+    ) names
+    WHERE lower(trim(subscriptions.service_name)) = names.key
+       OR trim(subscriptions.service_name) = names.text
+    LIMIT 1
+)
+WHERE EXISTS (
+    SELECT 1
+    FROM (
+        SELECT name_key AS key, name AS text FROM services
+        UNION ALL
+        SELECT key, alias FROM service_aliases
+    ) names
+    WHERE lower(trim(subscriptions.service_name)) = names.key
+       OR trim(subscriptions.service_name) = names.text
+);
@@ -0,0 +1,19 @@
+-- category - категория подписки: по умолчанию категория сервиса каталога,
+-- пользователь может задать свою.
+ALTER TABLE subscriptions ADD COLUMN category TEXT NOT NULL DEFAULT '';
+
+UPDATE subscriptions
+SET category = (SELECT category FROM services WHERE services.id = subscriptions.service_id)
+WHERE service_id IS NOT NULL
+  AND EXISTS (SELECT 1 FROM services WHERE services.id = subscriptions.service_id);
+
+CREATE INDEX IF NOT EXISTS subscriptions_category_idx ON subscriptions (category);
+
+-- Теги хранятся в нижнем регистре с одиночными пробелами (models.NormalizeLabel).
+CREATE TABLE IF NOT EXISTS subscription_tags(
+    subscription_id TEXT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (subscription_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS subscription_tags_tag_idx ON subscription_tags (tag);
@@ -0,0 +1,9 @@
+-- Изменения цены подписки. Начальная цена остается в subscriptions.price,
+-- в месяце действует последнее изменение не позже него.
+CREATE TABLE IF NOT EXISTS subscription_prices(
+    subscription_id TEXT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    effective_from TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    PRIMARY KEY (subscription_id, effective_from),
+    CONSTRAINT subscription_prices_price_non_negative CHECK (price >= 0)
+);
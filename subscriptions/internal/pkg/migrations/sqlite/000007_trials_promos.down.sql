@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS subscriptions_trial_end_idx;
+
+ALTER TABLE subscriptions DROP COLUMN promo_type;
+ALTER TABLE subscriptions DROP COLUMN promo_months;
+ALTER TABLE subscriptions DROP COLUMN promo_value;
+ALTER TABLE subscriptions DROP COLUMN trial_end;
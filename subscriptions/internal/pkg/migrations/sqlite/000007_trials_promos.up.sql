@@ -0,0 +1,16 @@
+-- trial_end - последний месяц пробного периода; promo_* - вводная цена на
+-- promo_months месяцев после него (models.Promo), promo_type NULL - без промо.
+-- SQLite не добавляет ограничения таблицы, поэтому проверки привязаны
+-- к колонкам.
+ALTER TABLE subscriptions ADD COLUMN trial_end TEXT
+    CONSTRAINT subscriptions_trial_end_after_start CHECK (trial_end IS NULL OR trial_end >= start_date);
+ALTER TABLE subscriptions ADD COLUMN promo_value INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subscriptions ADD COLUMN promo_months INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subscriptions ADD COLUMN promo_type TEXT
+    CONSTRAINT subscriptions_promo_valid CHECK (
+        promo_type IS NULL
+        OR (promo_type = 'percentage' AND promo_value BETWEEN 0 AND 100 AND promo_months > 0)
+        OR (promo_type = 'fixed' AND promo_value >= 0 AND promo_months > 0)
+    );
+
+CREATE INDEX IF NOT EXISTS subscriptions_trial_end_idx ON subscriptions (trial_end) WHERE trial_end IS NOT NULL;
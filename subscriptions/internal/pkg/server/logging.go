@@ -0,0 +1,73 @@
+package server
+
+import (
+	"github.com/ekkserapopova/subscriptions/pkg/logger"
+	"github.com/ekkserapopova/subscriptions/pkg/responser"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+// accessLog оборачивает весь роутер, чтобы в лог попадали и запросы
+// без подходящего маршрута (404, 405).
+func accessLog(log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(requestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(requestIDHeader, requestID)
+
+			ctx := logger.WithRequestID(r.Context(), requestID)
+			rw := responser.NewRecorder(w)
+
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			log.InfoContext(ctx, "http request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rw.Status()),
+				slog.Int64("bytes", rw.Bytes()),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote_addr", r.RemoteAddr),
+			)
+		})
+	}
+}
+
+// routeContext добавляет шаблон маршрута в поля лога запроса;
+// работает внутри mux, где маршрут уже сопоставлен.
+func routeContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				logger.SetRoute(r.Context(), tpl)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validRequestID отбрасывает пустые, слишком длинные и непечатаемые ID,
+// чтобы клиент не мог испортить строку лога.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
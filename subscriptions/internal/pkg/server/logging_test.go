@@ -0,0 +1,35 @@
+package server
+
+import (
+	"bytes"
+	"github.com/ekkserapopova/subscriptions/pkg/responser"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessLogSharesRecorder(t *testing.T) {
+	var logs bytes.Buffer
+	var inner *responser.Recorder
+	handler := accessLog(slog.New(slog.NewTextHandler(&logs, nil)))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Так оборачивает ответ middleware метрик внутри роутера.
+		inner = responser.NewRecorder(w)
+		if inner != w {
+			t.Error("recorder wrapped twice")
+		}
+		inner.WriteHeader(http.StatusTeapot)
+		_, _ = inner.Write([]byte("short"))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions", nil))
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusTeapot)
+	}
+	if line := logs.String(); !strings.Contains(line, "status=418") || !strings.Contains(line, "bytes=5") {
+		t.Errorf("access log = %s", line)
+	}
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"net/http"
+	"time"
+)
+
+// requestTimeout ограничивает время обработки запроса через дедлайн контекста,
+// который учитывают usecase и запросы к базе.
+func requestTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+const readYourWritesHeader = "X-Read-Your-Writes"
+
+// readYourWrites отправляет чтения запроса в primary, если клиент
+// не готов получить данные с отстающей реплики.
+func readYourWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(readYourWritesHeader) == "true" {
+			r = r.WithContext(db.WithPrimary(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
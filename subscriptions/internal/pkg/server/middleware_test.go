@@ -0,0 +1,40 @@
+package server
+
+import (
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		primary bool
+	}{
+		{name: "без заголовка"},
+		{name: "true", header: "true", primary: true},
+		{name: "false", header: "false"},
+		{name: "другое значение", header: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var primary bool
+			handler := readYourWrites(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				primary = db.UsePrimary(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions", nil)
+			if tt.header != "" {
+				r.Header.Set(readYourWritesHeader, tt.header)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if primary != tt.primary {
+				t.Errorf("primary = %v, want %v", primary, tt.primary)
+			}
+		})
+	}
+}
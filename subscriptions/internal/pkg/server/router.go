@@ -1,10 +1,20 @@
 package server
 
 import (
+	"context"
 	_ "github.com/ekkserapopova/subscriptions/docs"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/health"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tracing"
+	catalogHandler "github.com/ekkserapopova/subscriptions/internal/services/catalog/delivery/http"
+	householdHandler "github.com/ekkserapopova/subscriptions/internal/services/households/delivery/http"
+	subscriptionGraphQL "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/graphql"
 	subscriptionHandler "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/http"
+	userHandler "github.com/ekkserapopova/subscriptions/internal/services/users/delivery/http"
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"log/slog"
 	"net/http"
@@ -13,32 +23,113 @@ import (
 type RouterParams struct {
 	fx.In
 
+	Config              Config
+	MetricsConfig       metrics.Config
+	TracingConfig       tracing.Config
 	Logger              *slog.Logger
+	Metrics             *metrics.Metrics
+	TracerProvider      trace.TracerProvider
+	HealthHandler       *health.Handler
 	SubscriptionHandler *subscriptionHandler.Handler
+	UserHandler         *userHandler.Handler
+	HouseholdHandler    *householdHandler.Handler
+	CatalogHandler      *catalogHandler.Handler
+	GraphQLHandler      *subscriptionGraphQL.Handler
 }
 
 type Router struct {
-	handler *mux.Router
+	handler http.Handler
+	// streams отменяется в начале остановки сервера: потоковые ответы
+	// не завершаются сами, и Shutdown ждал бы их до таймаута.
+	streams     context.Context
+	stopStreams context.CancelFunc
 }
 
 func NewRouter(p RouterParams) *Router {
-	api := mux.NewRouter().PathPrefix("/api").Subrouter()
+	router := &Router{}
+	router.streams, router.stopStreams = context.WithCancel(context.Background())
+
+	root := mux.NewRouter()
+	root.Use(otelmux.Middleware(p.TracingConfig.ServiceName, otelmux.WithTracerProvider(p.TracerProvider)))
+	root.Use(routeContext)
+	root.Use(readYourWrites)
+	root.Use(p.Metrics.Middleware)
+	root.Handle(p.MetricsConfig.Path, p.Metrics.Handler()).Methods(http.MethodGet)
+	root.HandleFunc("/healthz", p.HealthHandler.Healthz).Methods(http.MethodGet)
+	root.HandleFunc("/readyz", p.HealthHandler.Readyz).Methods(http.MethodGet)
+
+	api := root.PathPrefix("/api").Subrouter()
 	v1 := api.PathPrefix("/v1").Subrouter()
 
-	v1.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+	// Потоковые обработчики сами управляют временем жизни соединения,
+	// поэтому регистрируются без таймаута запроса.
+	v1.Handle("/subscriptions/events", router.stream(http.HandlerFunc(p.SubscriptionHandler.StreamSubscriptionEvents))).Methods(http.MethodGet)
+
+	routes := v1.NewRoute().Subrouter()
+	routes.Use(requestTimeout(p.Config.Timeout))
+
+	routes.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+
+	routes.HandleFunc("/subscriptions", p.SubscriptionHandler.CreateSubscription).Methods(http.MethodPost, http.MethodOptions)
+	routes.HandleFunc("/subscriptions/sum", p.SubscriptionHandler.GetSumSubscriptions).Methods(http.MethodGet)
+	routes.HandleFunc("/subscriptions/sum/breakdown", p.SubscriptionHandler.GetSumBreakdown).Methods(http.MethodGet)
+	routes.HandleFunc("/subscriptions/sum/groups", p.SubscriptionHandler.GetSumGroups).Methods(http.MethodGet)
+	routes.HandleFunc("/subscriptions/trials", p.SubscriptionHandler.TrialsEnding).Methods(http.MethodGet)
+	routes.HandleFunc("/subscriptions/{id}", p.SubscriptionHandler.UpdateSubscription).Methods(http.MethodPut, http.MethodOptions)
+	routes.HandleFunc("/subscriptions", p.SubscriptionHandler.GetAllSubscriptions).Methods(http.MethodGet)
+	routes.HandleFunc("/subscriptions/{id}", p.SubscriptionHandler.GetSubscriptionByID).Methods(http.MethodGet)
+	routes.HandleFunc("/subscriptions/{id}", p.SubscriptionHandler.DeleteSubscription).Methods(http.MethodDelete)
+	routes.HandleFunc("/subscriptions/{id}/prices/{month}", p.SubscriptionHandler.DeletePrice).Methods(http.MethodDelete)
+
+	routes.HandleFunc("/users", p.UserHandler.CreateUser).Methods(http.MethodPost, http.MethodOptions)
+	routes.HandleFunc("/users", p.UserHandler.ListUsers).Methods(http.MethodGet)
+	routes.HandleFunc("/users/{id}", p.UserHandler.UpdateUser).Methods(http.MethodPut, http.MethodOptions)
+	routes.HandleFunc("/users/{id}", p.UserHandler.GetUserByID).Methods(http.MethodGet)
+	routes.HandleFunc("/users/{id}", p.UserHandler.DeleteUser).Methods(http.MethodDelete)
+	routes.HandleFunc("/users/{id}/subscriptions", p.UserHandler.GetUserSubscriptions).Methods(http.MethodGet)
+	routes.HandleFunc("/users/{id}/summary", p.UserHandler.GetUserSummary).Methods(http.MethodGet)
+
+	routes.HandleFunc("/households", p.HouseholdHandler.CreateHousehold).Methods(http.MethodPost, http.MethodOptions)
+	routes.HandleFunc("/households", p.HouseholdHandler.ListHouseholds).Methods(http.MethodGet)
+	routes.HandleFunc("/households/{id}", p.HouseholdHandler.GetHouseholdByID).Methods(http.MethodGet)
+	routes.HandleFunc("/households/{id}", p.HouseholdHandler.DeleteHousehold).Methods(http.MethodDelete)
+	routes.HandleFunc("/households/{id}/members", p.HouseholdHandler.AddMember).Methods(http.MethodPost, http.MethodOptions)
+	routes.HandleFunc("/households/{id}/members/{user_id}", p.HouseholdHandler.RemoveMember).Methods(http.MethodDelete)
+	routes.HandleFunc("/households/{id}/settlement", p.HouseholdHandler.GetSettlement).Methods(http.MethodGet)
+	routes.HandleFunc("/subscriptions/{id}/split", p.HouseholdHandler.SetSplit).Methods(http.MethodPut, http.MethodOptions)
+	routes.HandleFunc("/subscriptions/{id}/split", p.HouseholdHandler.GetSplit).Methods(http.MethodGet)
+	routes.HandleFunc("/subscriptions/{id}/split", p.HouseholdHandler.DeleteSplit).Methods(http.MethodDelete)
 
-	v1.HandleFunc("/subscriptions", p.SubscriptionHandler.CreateSubscription).Methods(http.MethodPost, http.MethodOptions)
-	v1.HandleFunc("/subscriptions/sum", p.SubscriptionHandler.GetSumSubscriptions).Methods(http.MethodGet)
-	v1.HandleFunc("/subscriptions/{id}", p.SubscriptionHandler.UpdateSubscription).Methods(http.MethodPut, http.MethodOptions)
-	v1.HandleFunc("/subscriptions", p.SubscriptionHandler.GetAllSubscriptions).Methods(http.MethodGet)
-	v1.HandleFunc("/subscriptions/{id}", p.SubscriptionHandler.GetSubscriptionByID).Methods(http.MethodGet)
-	v1.HandleFunc("/subscriptions/{id}", p.SubscriptionHandler.DeleteSubscription).Methods(http.MethodDelete)
+	routes.HandleFunc("/tags", p.SubscriptionHandler.ListTags).Methods(http.MethodGet)
+	routes.HandleFunc("/tags/merge", p.SubscriptionHandler.MergeTags).Methods(http.MethodPost, http.MethodOptions)
+	routes.HandleFunc("/tags/{tag}", p.SubscriptionHandler.RenameTag).Methods(http.MethodPut, http.MethodOptions)
 
-	router := &Router{
-		handler: api,
-	}
+	routes.HandleFunc("/services", p.CatalogHandler.CreateService).Methods(http.MethodPost, http.MethodOptions)
+	routes.HandleFunc("/services", p.CatalogHandler.ListServices).Methods(http.MethodGet)
+	routes.HandleFunc("/services/match", p.CatalogHandler.MatchServices).Methods(http.MethodGet)
+	routes.HandleFunc("/services/{id}", p.CatalogHandler.UpdateService).Methods(http.MethodPut, http.MethodOptions)
+	routes.HandleFunc("/services/{id}", p.CatalogHandler.GetServiceByID).Methods(http.MethodGet)
+	routes.HandleFunc("/services/{id}", p.CatalogHandler.DeleteService).Methods(http.MethodDelete)
+
+	routes.HandleFunc("/graphql", p.GraphQLHandler.Query).Methods(http.MethodGet, http.MethodPost)
+	routes.HandleFunc("/graphql/playground", p.GraphQLHandler.Playground).Methods(http.MethodGet)
+
+	router.handler = accessLog(p.Logger)(root)
 
 	p.Logger.Info("registered router")
 
 	return router
 }
+
+// stream отменяет контекст потокового запроса при остановке сервера.
+func (r *Router) stream(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+
+		stop := context.AfterFunc(r.streams, cancel)
+		defer stop()
+
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
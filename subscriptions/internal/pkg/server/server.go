@@ -1,15 +1,22 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"go.uber.org/fx"
+	"log/slog"
+	"net"
 	"net/http"
 )
 
 type Params struct {
 	fx.In
 
-	Config Config
-	Router *Router
+	Lifecycle fx.Lifecycle
+	Config    Config
+	Logger    *slog.Logger
+	Router    *Router
 }
 
 func RunServer(params Params) {
@@ -17,11 +24,39 @@ func RunServer(params Params) {
 		Addr:              params.Config.Address,
 		Handler:           params.Router.handler,
 		ReadHeaderTimeout: params.Config.ReadHeaderTimeout,
+		WriteTimeout:      params.Config.Timeout,
 		IdleTimeout:       params.Config.IdleTimeout,
 	}
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			panic(err)
-		}
-	}()
+	srv.RegisterOnShutdown(params.Router.stopStreams)
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			listener, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen http address %s: %w", srv.Addr, err)
+			}
+
+			go func() {
+				if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					params.Logger.Error("http server: " + err.Error())
+				}
+			}()
+
+			params.Logger.Info("http server started on " + listener.Addr().String())
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, params.Config.ShutdownTimeout)
+			defer cancel()
+
+			if err := srv.Shutdown(ctx); err != nil {
+				params.Logger.Error("http server shutdown: " + err.Error())
+				_ = srv.Close()
+				return fmt.Errorf("failed to shutdown http server: %w", err)
+			}
+
+			params.Logger.Info("http server stopped")
+			return nil
+		},
+	})
 }
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"go.uber.org/fx/fxtest"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRunServerStopsStreams(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	streams, stopStreams := context.WithCancel(context.Background())
+	router := &Router{streams: streams, stopStreams: stopStreams}
+	router.handler = router.stream(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, ": connected\n\n")
+		_ = http.NewResponseController(w).Flush()
+		<-r.Context().Done()
+	}))
+
+	lc := fxtest.NewLifecycle(t)
+	RunServer(Params{
+		Lifecycle: lc,
+		Config:    Config{Address: addr, ShutdownTimeout: 5 * time.Second},
+		Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Router:    router,
+	})
+	lc.RequireStart()
+
+	resp, err := http.Get("http://" + addr + "/api/v1/subscriptions/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body := bufio.NewReader(resp.Body)
+	if _, err := body.ReadString('\n'); err != nil {
+		t.Fatalf("stream not started: %v", err)
+	}
+
+	start := time.Now()
+	if err := lc.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("shutdown took %s with an open stream", elapsed)
+	}
+	if _, err := io.ReadAll(body); err != nil {
+		t.Errorf("stream not closed cleanly: %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package tracing
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+type Config struct {
+	// Exporter: none, stdout или otlp.
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" env-default:"localhost:4317"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+	ServiceName string  `yaml:"serviceName" env-default:"subscriptions"`
+	SampleRatio float64 `yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
@@ -0,0 +1,10 @@
+package tx
+
+import "time"
+
+type Config struct {
+	// MaxRetries - сколько раз повторить транзакцию после конфликта
+	// сериализации; 0 отключает повторы.
+	MaxRetries int           `yaml:"maxRetries" env:"TX_MAX_RETRIES" env-default:"3"`
+	RetryDelay time.Duration `yaml:"retryDelay" env:"TX_RETRY_DELAY" env-default:"10ms"`
+}
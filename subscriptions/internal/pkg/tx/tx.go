@@ -0,0 +1,138 @@
+// Package tx описывает транзакции независимо от хранилища. Реализация
+// Manager открывает транзакцию и кладет ее в контекст, репозитории того же
+// хранилища достают ее через From и выполняют запросы в ней.
+package tx
+
+import (
+	"context"
+	"time"
+)
+
+type Isolation int
+
+const (
+	// Default оставляет уровень изоляции хранилища.
+	Default Isolation = iota
+	ReadCommitted
+	RepeatableRead
+	Serializable
+)
+
+type Options struct {
+	Isolation Isolation
+	ReadOnly  bool
+}
+
+// Manager выполняет fn в одной транзакции. Если fn вернула ошибку или
+// паниковала, транзакция откатывается. Вложенный вызов присоединяется к
+// внешней транзакции, его Options игнорируются.
+type Manager interface {
+	Do(ctx context.Context, opts Options, fn func(ctx context.Context) error) error
+}
+
+// Tx - открытая транзакция конкретного хранилища.
+type Tx interface {
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+type stateKey struct{}
+
+type state struct {
+	tx          Tx
+	afterCommit []func(context.Context)
+	onRollback  []func()
+}
+
+// From возвращает транзакцию из контекста.
+func From(ctx context.Context) (Tx, bool) {
+	s, ok := ctx.Value(stateKey{}).(*state)
+	if !ok {
+		return nil, false
+	}
+	return s.tx, true
+}
+
+// Active сообщает, выполняется ли код внутри транзакции.
+func Active(ctx context.Context) bool {
+	_, ok := From(ctx)
+	return ok
+}
+
+// AfterCommit откладывает fn до фиксации транзакции, а вне транзакции
+// вызывает сразу. При откате fn не вызывается.
+func AfterCommit(ctx context.Context, fn func(context.Context)) {
+	s, ok := ctx.Value(stateKey{}).(*state)
+	if !ok {
+		fn(ctx)
+		return
+	}
+	s.afterCommit = append(s.afterCommit, fn)
+}
+
+// OnRollback регистрирует отмену изменения, сделанного вне хранилища
+// транзакции, например в памяти процесса. Отмены выполняются в обратном
+// порядке; вне транзакции OnRollback ничего не делает.
+func OnRollback(ctx context.Context, undo func()) {
+	if s, ok := ctx.Value(stateKey{}).(*state); ok {
+		s.onRollback = append(s.onRollback, undo)
+	}
+}
+
+// Run - общая часть реализаций Manager: открывает транзакцию через begin,
+// выполняет fn и повторяет все заново, пока retryable считает ошибку
+// конфликтом и не исчерпан cfg.MaxRetries.
+func Run(ctx context.Context, cfg Config, begin func(ctx context.Context) (Tx, error), retryable func(error) bool, fn func(ctx context.Context) error) error {
+	if Active(ctx) {
+		return fn(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := runOnce(ctx, begin, fn)
+		if err == nil || attempt >= cfg.MaxRetries || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(cfg.RetryDelay * time.Duration(attempt+1)):
+		}
+	}
+}
+
+func runOnce(ctx context.Context, begin func(ctx context.Context) (Tx, error), fn func(ctx context.Context) error) error {
+	t, err := begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	s := &state{tx: t}
+	rollback := func() {
+		_ = t.Rollback(context.WithoutCancel(ctx))
+		for i := len(s.onRollback) - 1; i >= 0; i-- {
+			s.onRollback[i]()
+		}
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, stateKey{}, s)); err != nil {
+		rollback()
+		return err
+	}
+
+	if err := t.Commit(ctx); err != nil {
+		rollback()
+		return err
+	}
+
+	for _, hook := range s.afterCommit {
+		hook(ctx)
+	}
+	return nil
+}
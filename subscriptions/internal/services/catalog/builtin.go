@@ -0,0 +1,219 @@
+package catalog
+
+import (
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/google/uuid"
+)
+
+// Builtin - встроенный каталог. Те же записи с теми же id добавляют
+// миграции Postgres и SQLite; хранилище в памяти загружает их при старте.
+var Builtin = []models.Service{
+	{
+		ID:       uuid.MustParse("d2d6addc-cb2f-532a-9735-774327e9bbd5"),
+		Name:     "Yandex Plus",
+		Aliases:  []string{"Яндекс Плюс", "Яндекс.Плюс", "Yandex.Plus", "Плюс Мульти"},
+		Category: "streaming",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Мульти", Price: 399, Period: 1}, {Name: "Мульти на год", Price: 3990, Period: 12}},
+	},
+	{
+		ID:       uuid.MustParse("40c9c4e7-2714-5c5c-86c8-fc148bdc2712"),
+		Name:     "Kinopoisk",
+		Aliases:  []string{"Кинопоиск", "Kinopoisk HD"},
+		Category: "streaming",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Базовый", Price: 299, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("d071c21e-0270-55a0-85e5-091c086ab76e"),
+		Name:     "Okko",
+		Aliases:  []string{"Окко"},
+		Category: "streaming",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Оптимум", Price: 399, Period: 1}, {Name: "Премиум", Price: 699, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("98506251-caeb-5a5a-8646-9d33bbe18246"),
+		Name:     "ivi",
+		Aliases:  []string{"Иви", "ivi.ru"},
+		Category: "streaming",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Подписка", Price: 399, Period: 1}, {Name: "На год", Price: 2990, Period: 12}},
+	},
+	{
+		ID:       uuid.MustParse("56bbfcc2-b7cd-5807-9cd0-0fec7559859f"),
+		Name:     "Wink",
+		Aliases:  []string{"Винк"},
+		Category: "streaming",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Трансформер", Price: 349, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("8b08b894-809a-58a5-b769-07dec90cb131"),
+		Name:     "START",
+		Aliases:  []string{"Старт"},
+		Category: "streaming",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Подписка", Price: 299, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("efb15e0d-eb9b-5838-af41-d1611c0d18f1"),
+		Name:     "Amediateka",
+		Aliases:  []string{"Амедиатека"},
+		Category: "streaming",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Подписка", Price: 599, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("1b51b0c1-3bac-57bb-b962-84cce1331be5"),
+		Name:     "Netflix",
+		Aliases:  []string{"Нетфликс"},
+		Category: "streaming",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Standard", Price: 999, Period: 1}, {Name: "Premium", Price: 1499, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("3b198bf2-4414-5ce0-80d7-f213f73b7d3a"),
+		Name:     "YouTube Premium",
+		Aliases:  []string{"YouTube", "Ютуб Премиум", "YouTube Premium Family"},
+		Category: "streaming",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Individual", Price: 299, Period: 1}, {Name: "Family", Price: 449, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("11b49670-c89a-5859-9f7c-9a08eeb86d4a"),
+		Name:     "VK Music",
+		Aliases:  []string{"VK Музыка", "ВК Музыка", "BOOM"},
+		Category: "music",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Подписка", Price: 199, Period: 1}, {Name: "Семейная", Price: 299, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("17bb5e6f-c5a7-5752-9d7e-01b9f3f928e2"),
+		Name:     "Spotify",
+		Aliases:  []string{"Спотифай", "Spotify Family", "Spotify Premium"},
+		Category: "music",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Individual", Price: 169, Period: 1}, {Name: "Family", Price: 269, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("ade39961-6ffc-5609-b1de-3daa33d41c47"),
+		Name:     "Telegram Premium",
+		Aliases:  []string{"Телеграм Премиум", "Telegram"},
+		Category: "social",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Месяц", Price: 299, Period: 1}, {Name: "Год", Price: 2490, Period: 12}},
+	},
+	{
+		ID:       uuid.MustParse("9f2dfc49-b346-5c63-a984-9146c29d2c4d"),
+		Name:     "iCloud+",
+		Aliases:  []string{"iCloud", "Айклауд"},
+		Category: "cloud",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "50 ГБ", Price: 149, Period: 1}, {Name: "200 ГБ", Price: 299, Period: 1}, {Name: "2 ТБ", Price: 599, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("d9dfb884-ac85-5c62-a81c-4eb66c8b97a0"),
+		Name:     "Google One",
+		Aliases:  []string{"Гугл Ван", "Google Drive"},
+		Category: "cloud",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "100 ГБ", Price: 139, Period: 1}, {Name: "2 ТБ", Price: 699, Period: 1}, {Name: "2 ТБ на год", Price: 6990, Period: 12}},
+	},
+	{
+		ID:       uuid.MustParse("8616b9a3-6d5e-59dd-94df-da3e99c597ca"),
+		Name:     "Dropbox",
+		Aliases:  []string{"Дропбокс"},
+		Category: "cloud",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Plus", Price: 990, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("86e4a39b-f933-5480-923d-e47e205d4ce3"),
+		Name:     "Microsoft 365",
+		Aliases:  []string{"Office 365", "MS Office"},
+		Category: "productivity",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Personal", Price: 549, Period: 1}, {Name: "Family на год", Price: 6990, Period: 12}},
+	},
+	{
+		ID:       uuid.MustParse("6b86b4ce-dae2-57b2-bdb5-1bc5cef1c8e7"),
+		Name:     "Notion",
+		Aliases:  []string{"Ноушн"},
+		Category: "productivity",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Plus", Price: 800, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("92d04bd0-a91c-5840-8b9f-af3aab3a28a6"),
+		Name:     "Figma",
+		Aliases:  []string{"Фигма"},
+		Category: "design",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Professional", Price: 1200, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("3d1518ae-f6aa-512c-9f37-0a65f192a210"),
+		Name:     "Adobe Creative Cloud",
+		Aliases:  []string{"Adobe CC", "Adobe"},
+		Category: "design",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Photography", Price: 990, Period: 1}, {Name: "All Apps", Price: 5990, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("5343e6c6-3c24-5208-b786-5499f05af49e"),
+		Name:     "ChatGPT",
+		Aliases:  []string{"ChatGPT Plus", "ЧатГПТ"},
+		Category: "ai",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Plus", Price: 2000, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("89180b1a-34cf-5dd5-b370-123eb2de9ec1"),
+		Name:     "GitHub Copilot",
+		Aliases:  []string{"Copilot"},
+		Category: "development",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Individual", Price: 1000, Period: 1}, {Name: "Individual на год", Price: 10000, Period: 12}},
+	},
+	{
+		ID:       uuid.MustParse("fda94074-a011-5840-81d8-25b2ab7bff73"),
+		Name:     "JetBrains",
+		Aliases:  []string{"JetBrains All Products", "IntelliJ IDEA"},
+		Category: "development",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "All Products на год", Price: 24900, Period: 12}},
+	},
+	{
+		ID:       uuid.MustParse("d24086e7-cd23-58ba-8453-6098bf405a87"),
+		Name:     "Duolingo",
+		Aliases:  []string{"Дуолинго", "Duolingo Super"},
+		Category: "education",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Super", Price: 499, Period: 1}, {Name: "Super на год", Price: 3990, Period: 12}},
+	},
+	{
+		ID:       uuid.MustParse("d3fd17aa-5318-5032-8d55-26a7e003cb9f"),
+		Name:     "Litres",
+		Aliases:  []string{"ЛитРес"},
+		Category: "books",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Абонемент", Price: 399, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("5fe4421e-a8d8-5d7b-979d-714d51501ea7"),
+		Name:     "Storytel",
+		Aliases:  []string{"Сторител"},
+		Category: "books",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Подписка", Price: 499, Period: 1}},
+	},
+	{
+		ID:       uuid.MustParse("22729e06-8a2c-55fb-94a8-0f4ee5b719fd"),
+		Name:     "Strava",
+		Aliases:  []string{"Страва"},
+		Category: "fitness",
+		Currency: models.DefaultCurrency,
+		Plans:    []models.ServicePlan{{Name: "Subscription", Price: 449, Period: 1}},
+	},
+}
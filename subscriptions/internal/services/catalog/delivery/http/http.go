@@ -0,0 +1,222 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/catalog"
+	"github.com/ekkserapopova/subscriptions/pkg/reader"
+	"github.com/ekkserapopova/subscriptions/pkg/responser"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/fx"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Params struct {
+	fx.In
+
+	Logger  *slog.Logger
+	UseCase catalog.UseCase
+}
+
+type Handler struct {
+	logger  *slog.Logger
+	usecase catalog.UseCase
+}
+
+func NewHandler(params Params) *Handler {
+	return &Handler{
+		logger:  params.Logger,
+		usecase: params.UseCase,
+	}
+}
+
+// CreateService godoc
+// @Summary Добавить сервис в каталог
+// @Description Название и псевдонимы сравниваются без учета регистра и должны быть уникальны в каталоге. Валюта по умолчанию RUB
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param service body models.Service true "Сервис"
+// @Success 201 {object} models.Service
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /services [post]
+func (h *Handler) CreateService(w http.ResponseWriter, r *http.Request) {
+	service := &models.Service{}
+	if err := reader.ReadResponseData(r, service); err != nil {
+		h.logger.ErrorContext(r.Context(), "create service request err: "+err.Error())
+		responser.SendErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.usecase.CreateService(r.Context(), service)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusCreated, created)
+}
+
+// @Summary Изменить сервис
+// @Description Изменяет переданные поля; псевдонимы и тарифы заменяются целиком. Новое название записывается и в подписки сервиса
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Param service body models.ServiceUpdate true "Изменяемые поля"
+// @Success 200 {object} models.Service
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /services/{id} [put]
+func (h *Handler) UpdateService(w http.ResponseWriter, r *http.Request) {
+	id, ok := serviceID(w, r)
+	if !ok {
+		return
+	}
+
+	update := models.ServiceUpdate{}
+	if err := reader.ReadResponseData(r, &update); err != nil {
+		h.logger.ErrorContext(r.Context(), "update service request err: "+err.Error())
+		responser.SendErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updated, err := h.usecase.UpdateService(r.Context(), id, update)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, updated)
+}
+
+// @Summary Получить сервис
+// @Tags services
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Success 200 {object} models.Service
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /services/{id} [get]
+func (h *Handler) GetServiceByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := serviceID(w, r)
+	if !ok {
+		return
+	}
+
+	service, err := h.usecase.GetServiceByID(r.Context(), id)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, service)
+}
+
+// @Summary Каталог сервисов
+// @Tags services
+// @Produce json
+// @Param category query string false "Категория"
+// @Success 200 {array} models.Service
+// @Failure 500 {object} map[string]string
+// @Router /services [get]
+func (h *Handler) ListServices(w http.ResponseWriter, r *http.Request) {
+	list, err := h.usecase.ListServices(r.Context(), r.URL.Query().Get("category"))
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+	if list == nil {
+		list = []*models.Service{}
+	}
+
+	responser.SendOK(w, http.StatusOK, list)
+}
+
+// @Summary Удалить сервис из каталога
+// @Description Подписки сервиса отвязываются от каталога и сохраняют название
+// @Tags services
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /services/{id} [delete]
+func (h *Handler) DeleteService(w http.ResponseWriter, r *http.Request) {
+	id, ok := serviceID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.usecase.DeleteService(r.Context(), id); err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusNoContent, map[string]string{"msg": "service deleted"})
+}
+
+// @Summary Подобрать сервис по названию
+// @Description Нечеткий поиск по названиям и псевдонимам каталога, кириллица сравнивается с латиницей по транслитерации. Кандидаты по убыванию score от 0 до 1
+// @Tags services
+// @Produce json
+// @Param name query string true "Название в свободной форме"
+// @Param limit query int false "Максимум кандидатов, по умолчанию 5"
+// @Success 200 {array} models.ServiceMatch
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /services/match [get]
+func (h *Handler) MatchServices(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			responser.SendErr(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	matches, err := h.usecase.MatchServices(r.Context(), r.URL.Query().Get("name"), limit)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, matches)
+}
+
+func serviceID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responser.SendErr(w, http.StatusBadRequest, "invalid id format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, catalog.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, catalog.ErrAlreadyExists),
+		errors.Is(err, catalog.ErrNameTaken):
+		return http.StatusConflict
+	case errors.Is(err, catalog.ErrInvalidArgument):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
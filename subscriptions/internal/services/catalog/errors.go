@@ -0,0 +1,24 @@
+package catalog
+
+import (
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/errs"
+)
+
+var (
+	ErrNotFound        = errors.New("service not found")
+	ErrAlreadyExists   = errors.New("service with this id already exists")
+	ErrNameTaken       = errors.New("service name or alias is already used by another service")
+	ErrInvalidArgument = errs.ErrInvalidArgument
+)
+
+var (
+	ErrNameRequired      = errs.InvalidArgument("name is required")
+	ErrInvalidCurrency   = errs.InvalidArgument("currency must be an ISO 4217 code")
+	ErrPlanNameRequired  = errs.InvalidArgument("plan name is required")
+	ErrDuplicatePlan     = errs.InvalidArgument("plan names must be unique")
+	ErrNegativePlanPrice = errs.InvalidArgument("plan price must not be negative")
+	ErrInvalidPlanPeriod = errs.InvalidArgument("plan period must be a positive number of months")
+	ErrNoFieldsToUpdate  = errs.InvalidArgument("no fields to update")
+	ErrQueryRequired     = errs.InvalidArgument("name query parameter is required")
+)
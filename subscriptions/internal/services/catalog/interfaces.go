@@ -0,0 +1,30 @@
+package catalog
+
+import (
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	CreateService(ctx context.Context, service *models.Service) (*models.Service, error)
+	UpdateService(ctx context.Context, id uuid.UUID, update models.ServiceUpdate) (*models.Service, error)
+	GetServiceByID(ctx context.Context, id uuid.UUID) (*models.Service, error)
+	ListServices(ctx context.Context, category string) ([]*models.Service, error)
+	DeleteService(ctx context.Context, id uuid.UUID) error
+	MatchServices(ctx context.Context, name string, limit int) ([]*models.ServiceMatch, error)
+}
+
+// Repository хранит сервис вместе с псевдонимами и тарифами. Запись
+// занимает несколько запросов, поэтому вызывается внутри tx.Manager.Do.
+type Repository interface {
+	CreateService(ctx context.Context, service *models.Service) (*models.Service, error)
+	// UpdateService сохраняет сервис целиком, заменяя псевдонимы и тарифы.
+	UpdateService(ctx context.Context, service *models.Service) (*models.Service, error)
+	GetServiceByID(ctx context.Context, id uuid.UUID) (*models.Service, error)
+	// FindServiceByName ищет сервис по названию или псевдониму с точностью
+	// до models.ServiceKey.
+	FindServiceByName(ctx context.Context, name string) (*models.Service, error)
+	ListServices(ctx context.Context, category string) ([]*models.Service, error)
+	DeleteService(ctx context.Context, id uuid.UUID) error
+}
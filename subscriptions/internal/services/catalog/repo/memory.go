@@ -0,0 +1,204 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/catalog"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+type MemoryParams struct {
+	fx.In
+
+	Logger *slog.Logger
+}
+
+// MemoryRepository хранит каталог в памяти процесса и при создании
+// загружает встроенные сервисы, как это делают миграции. Изменения внутри
+// tx.Manager.Do отменяются через tx.OnRollback.
+type MemoryRepository struct {
+	log *slog.Logger
+
+	mu       sync.RWMutex
+	services map[uuid.UUID]*models.Service
+}
+
+func NewMemoryRepository(params MemoryParams) *MemoryRepository {
+	repo := &MemoryRepository{
+		log:      params.Logger,
+		services: make(map[uuid.UUID]*models.Service, len(catalog.Builtin)),
+	}
+
+	now := time.Now().UTC()
+	for _, builtin := range catalog.Builtin {
+		service := clone(&builtin)
+		service.CreatedAt, service.UpdatedAt = now, now
+		repo.services[service.ID] = service
+	}
+
+	return repo
+}
+
+func (repo *MemoryRepository) CreateService(ctx context.Context, service *models.Service) (*models.Service, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.services[service.ID]; ok {
+		return nil, catalog.ErrAlreadyExists
+	}
+	if repo.nameTaken(service) {
+		return nil, catalog.ErrNameTaken
+	}
+
+	created := clone(service)
+	now := time.Now().UTC()
+	created.CreatedAt, created.UpdatedAt = now, now
+	repo.put(ctx, created)
+
+	return clone(created), nil
+}
+
+func (repo *MemoryRepository) UpdateService(ctx context.Context, service *models.Service) (*models.Service, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	current, ok := repo.services[service.ID]
+	if !ok {
+		return nil, catalog.ErrNotFound
+	}
+	if repo.nameTaken(service) {
+		return nil, catalog.ErrNameTaken
+	}
+
+	updated := clone(service)
+	updated.CreatedAt = current.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+	repo.put(ctx, updated)
+
+	return clone(updated), nil
+}
+
+func (repo *MemoryRepository) GetServiceByID(_ context.Context, id uuid.UUID) (*models.Service, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	service, ok := repo.services[id]
+	if !ok {
+		return nil, catalog.ErrNotFound
+	}
+	return clone(service), nil
+}
+
+func (repo *MemoryRepository) FindServiceByName(_ context.Context, name string) (*models.Service, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	key := models.ServiceKey(name)
+	for _, service := range repo.services {
+		if slices.Contains(keys(service), key) {
+			return clone(service), nil
+		}
+	}
+	return nil, catalog.ErrNotFound
+}
+
+func (repo *MemoryRepository) ListServices(_ context.Context, category string) ([]*models.Service, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	list := make([]*models.Service, 0, len(repo.services))
+	for _, service := range repo.services {
+		if category != "" && service.Category != category {
+			continue
+		}
+		list = append(list, clone(service))
+	}
+	slices.SortFunc(list, func(a, b *models.Service) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID.String(), b.ID.String()))
+	})
+
+	return list, nil
+}
+
+func (repo *MemoryRepository) DeleteService(ctx context.Context, id uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	service, ok := repo.services[id]
+	if !ok {
+		return catalog.ErrNotFound
+	}
+	delete(repo.services, id)
+	tx.OnRollback(ctx, func() {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		repo.services[id] = service
+	})
+
+	return nil
+}
+
+// put сохраняет сервис и при откате транзакции возвращает прежнее
+// состояние записи.
+func (repo *MemoryRepository) put(ctx context.Context, service *models.Service) {
+	previous, existed := repo.services[service.ID]
+	repo.services[service.ID] = service
+	tx.OnRollback(ctx, func() {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		if existed {
+			repo.services[service.ID] = previous
+		} else {
+			delete(repo.services, service.ID)
+		}
+	})
+}
+
+// nameTaken повторяет уникальные индексы по названию и псевдонимам.
+func (repo *MemoryRepository) nameTaken(service *models.Service) bool {
+	taken := keys(service)
+	for _, other := range repo.services {
+		if other.ID == service.ID {
+			continue
+		}
+		for _, key := range keys(other) {
+			if slices.Contains(taken, key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func keys(service *models.Service) []string {
+	list := []string{models.ServiceKey(service.Name)}
+	for _, alias := range service.Aliases {
+		list = append(list, models.ServiceKey(alias))
+	}
+	return list
+}
+
+// clone копирует сервис и упорядочивает псевдонимы и тарифы так же,
+// как их отдают SQL-хранилища.
+func clone(service *models.Service) *models.Service {
+	copied := *service
+	copied.Aliases = slices.Sorted(slices.Values(service.Aliases))
+	if copied.Aliases == nil {
+		copied.Aliases = []string{}
+	}
+	copied.Plans = slices.Clone(service.Plans)
+	if copied.Plans == nil {
+		copied.Plans = []models.ServicePlan{}
+	}
+	slices.SortFunc(copied.Plans, func(a, b models.ServicePlan) int {
+		return cmp.Or(cmp.Compare(a.Period, b.Period), cmp.Compare(a.Price, b.Price), cmp.Compare(a.Name, b.Name))
+	})
+	return &copied
+}
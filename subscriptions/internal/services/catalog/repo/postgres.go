@@ -0,0 +1,353 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/services/catalog"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/fx"
+	"log/slog"
+	"time"
+)
+
+var serviceColumns = []string{"id", "name", "category", "currency", "created_at", "updated_at"}
+
+type Params struct {
+	fx.In
+
+	Logger  *slog.Logger
+	Cluster *db.Cluster
+	Builder squirrel.StatementBuilderType
+	Metrics *metrics.Metrics
+}
+
+// Repository хранит каталог сервисов: сам сервис, его псевдонимы и тарифы
+// лежат в отдельных таблицах. Название и псевдонимы уникальны по ключу
+// models.ServiceKey.
+type Repository struct {
+	cluster *db.Cluster
+	log     *slog.Logger
+	builder squirrel.StatementBuilderType
+	metrics *metrics.Metrics
+}
+
+func NewRepository(params Params) *Repository {
+	return &Repository{
+		cluster: params.Cluster,
+		log:     params.Logger,
+		builder: params.Builder,
+		metrics: params.Metrics,
+	}
+}
+
+func (repo *Repository) CreateService(ctx context.Context, service *models.Service) (*models.Service, error) {
+	defer repo.metrics.ObserveQuery("CreateService", time.Now())
+
+	query, args, err := repo.builder.
+		Insert("services").
+		Columns("id", "name", "name_key", "category", "currency").
+		Values(service.ID, service.Name, models.ServiceKey(service.Name), service.Category, service.Currency).
+		Suffix("RETURNING created_at, updated_at").
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	saved := *service
+	if err := repo.cluster.Writer(ctx).QueryRow(ctx, query, args...).Scan(&saved.CreatedAt, &saved.UpdatedAt); err != nil {
+		if domainErr := violation(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "create service: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to create service: "+err.Error())
+		return nil, err
+	}
+
+	if err := repo.replaceDetails(ctx, &saved); err != nil {
+		return nil, err
+	}
+
+	return repo.GetServiceByID(ctx, service.ID)
+}
+
+func (repo *Repository) UpdateService(ctx context.Context, service *models.Service) (*models.Service, error) {
+	defer repo.metrics.ObserveQuery("UpdateService", time.Now())
+
+	query, args, err := repo.builder.
+		Update("services").
+		Set("name", service.Name).
+		Set("name_key", models.ServiceKey(service.Name)).
+		Set("category", service.Category).
+		Set("currency", service.Currency).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": service.ID}).
+		Suffix("RETURNING created_at, updated_at").
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	saved := *service
+	if err := repo.cluster.Writer(ctx).QueryRow(ctx, query, args...).Scan(&saved.CreatedAt, &saved.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, catalog.ErrNotFound
+		}
+		if domainErr := violation(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "update service: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to update service: "+err.Error())
+		return nil, err
+	}
+
+	if err := repo.replaceDetails(ctx, &saved); err != nil {
+		return nil, err
+	}
+
+	return repo.GetServiceByID(ctx, service.ID)
+}
+
+func (repo *Repository) GetServiceByID(ctx context.Context, id uuid.UUID) (*models.Service, error) {
+	defer repo.metrics.ObserveQuery("GetServiceByID", time.Now())
+
+	return repo.getService(ctx, squirrel.Eq{"id": id})
+}
+
+func (repo *Repository) FindServiceByName(ctx context.Context, name string) (*models.Service, error) {
+	defer repo.metrics.ObserveQuery("FindServiceByName", time.Now())
+
+	key := models.ServiceKey(name)
+	return repo.getService(ctx, squirrel.Or{
+		squirrel.Eq{"name_key": key},
+		squirrel.Expr("id IN (SELECT service_id FROM service_aliases WHERE key = ?)", key),
+	})
+}
+
+func (repo *Repository) ListServices(ctx context.Context, category string) ([]*models.Service, error) {
+	defer repo.metrics.ObserveQuery("ListServices", time.Now())
+
+	builder := repo.builder.
+		Select(serviceColumns...).
+		From("services").
+		OrderBy("name", "id")
+	if category != "" {
+		builder = builder.Where(squirrel.Eq{"category": category})
+	}
+
+	return repo.queryServices(ctx, builder)
+}
+
+// DeleteService удаляет и псевдонимы с тарифами; у подписок сервиса
+// внешний ключ обнуляется.
+func (repo *Repository) DeleteService(ctx context.Context, id uuid.UUID) error {
+	defer repo.metrics.ObserveQuery("DeleteService", time.Now())
+
+	tag, err := repo.cluster.Writer(ctx).Exec(ctx, "DELETE FROM services WHERE id = $1", id)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to delete service: "+err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return catalog.ErrNotFound
+	}
+
+	return nil
+}
+
+// replaceDetails заменяет псевдонимы и тарифы сервиса.
+func (repo *Repository) replaceDetails(ctx context.Context, service *models.Service) error {
+	writer := repo.cluster.Writer(ctx)
+	for _, table := range []string{"service_aliases", "service_plans"} {
+		if _, err := writer.Exec(ctx, "DELETE FROM "+table+" WHERE service_id = $1", service.ID); err != nil {
+			repo.log.ErrorContext(ctx, "failed to replace service details: "+err.Error())
+			return err
+		}
+	}
+
+	var inserts []squirrel.InsertBuilder
+	if len(service.Aliases) > 0 {
+		insert := repo.builder.Insert("service_aliases").Columns("key", "service_id", "alias")
+		for _, alias := range service.Aliases {
+			insert = insert.Values(models.ServiceKey(alias), service.ID, alias)
+		}
+		inserts = append(inserts, insert)
+	}
+	if len(service.Plans) > 0 {
+		insert := repo.builder.Insert("service_plans").Columns("service_id", "name", "price", "period")
+		for _, plan := range service.Plans {
+			insert = insert.Values(service.ID, plan.Name, plan.Price, plan.Period)
+		}
+		inserts = append(inserts, insert)
+	}
+
+	for _, insert := range inserts {
+		query, args, err := insert.ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Exec(ctx, query, args...); err != nil {
+			if domainErr := violation(err); domainErr != nil {
+				repo.log.WarnContext(ctx, "save service: "+domainErr.Error())
+				return domainErr
+			}
+			repo.log.ErrorContext(ctx, "failed to replace service details: "+err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *Repository) getService(ctx context.Context, where squirrel.Sqlizer) (*models.Service, error) {
+	list, err := repo.queryServices(ctx, repo.builder.
+		Select(serviceColumns...).
+		From("services").
+		Where(where))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, catalog.ErrNotFound
+	}
+
+	return list[0], nil
+}
+
+func (repo *Repository) queryServices(ctx context.Context, builder squirrel.SelectBuilder) ([]*models.Service, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := repo.cluster.Reader(ctx)
+	rows, err := reader.Query(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch services: "+err.Error())
+		return nil, err
+	}
+
+	var (
+		list []*models.Service
+		ids  []uuid.UUID
+	)
+	byID := make(map[uuid.UUID]*models.Service)
+	for rows.Next() {
+		service := &models.Service{Aliases: []string{}, Plans: []models.ServicePlan{}}
+		if err := rows.Scan(&service.ID, &service.Name, &service.Category, &service.Currency, &service.CreatedAt, &service.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, service)
+		ids = append(ids, service.ID)
+		byID[service.ID] = service
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	query, args, err = repo.builder.
+		Select("service_id", "alias").
+		From("service_aliases").
+		Where(squirrel.Eq{"service_id": ids}).
+		OrderBy("alias").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	aliases, err := reader.Query(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch service aliases: "+err.Error())
+		return nil, err
+	}
+	for aliases.Next() {
+		var (
+			serviceID uuid.UUID
+			alias     string
+		)
+		if err := aliases.Scan(&serviceID, &alias); err != nil {
+			aliases.Close()
+			return nil, err
+		}
+		byID[serviceID].Aliases = append(byID[serviceID].Aliases, alias)
+	}
+	aliases.Close()
+	if err := aliases.Err(); err != nil {
+		return nil, err
+	}
+
+	query, args, err = repo.builder.
+		Select("service_id", "name", "price", "period").
+		From("service_plans").
+		Where(squirrel.Eq{"service_id": ids}).
+		OrderBy("period", "price", "name").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := reader.Query(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch service plans: "+err.Error())
+		return nil, err
+	}
+	defer plans.Close()
+
+	for plans.Next() {
+		var (
+			serviceID uuid.UUID
+			plan      models.ServicePlan
+		)
+		if err := plans.Scan(&serviceID, &plan.Name, &plan.Price, &plan.Period); err != nil {
+			return nil, err
+		}
+		byID[serviceID].Plans = append(byID[serviceID].Plans, plan)
+	}
+
+	return list, plans.Err()
+}
+
+// violation переводит нарушения ограничений каталога в доменные ошибки.
+func violation(err error) error {
+	pgErr := &pgconn.PgError{}
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	switch pgErr.Code {
+	case "23505":
+		switch pgErr.ConstraintName {
+		case "services_pkey":
+			return catalog.ErrAlreadyExists
+		case "service_plans_pkey":
+			return catalog.ErrDuplicatePlan
+		default:
+			return catalog.ErrNameTaken
+		}
+	case "23514":
+		switch pgErr.ConstraintName {
+		case "services_currency_format":
+			return catalog.ErrInvalidCurrency
+		case "service_plans_price_non_negative":
+			return catalog.ErrNegativePlanPrice
+		case "service_plans_period_positive":
+			return catalog.ErrInvalidPlanPeriod
+		default:
+			return catalog.ErrInvalidArgument
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,347 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/services/catalog"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"log/slog"
+	sqlite3 "modernc.org/sqlite/lib"
+	"time"
+)
+
+type SQLiteParams struct {
+	fx.In
+
+	Logger  *slog.Logger
+	DB      *sql.DB
+	Metrics *metrics.Metrics
+}
+
+type SQLiteRepository struct {
+	db      *sql.DB
+	log     *slog.Logger
+	builder squirrel.StatementBuilderType
+	metrics *metrics.Metrics
+}
+
+func NewSQLiteRepository(params SQLiteParams) *SQLiteRepository {
+	return &SQLiteRepository{
+		db:      params.DB,
+		log:     params.Logger,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		metrics: params.Metrics,
+	}
+}
+
+func (repo *SQLiteRepository) CreateService(ctx context.Context, service *models.Service) (*models.Service, error) {
+	defer repo.metrics.ObserveQuery("CreateService", time.Now())
+
+	query, args, err := repo.builder.
+		Insert("services").
+		Columns("id", "name", "name_key", "category", "currency").
+		Values(service.ID.String(), service.Name, models.ServiceKey(service.Name), service.Category, service.Currency).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	if _, err := db.SQLiteConn(ctx, repo.db).ExecContext(ctx, query, args...); err != nil {
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "create service: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to create service: "+err.Error())
+		return nil, err
+	}
+
+	if err := repo.replaceDetails(ctx, service); err != nil {
+		return nil, err
+	}
+
+	return repo.GetServiceByID(ctx, service.ID)
+}
+
+func (repo *SQLiteRepository) UpdateService(ctx context.Context, service *models.Service) (*models.Service, error) {
+	defer repo.metrics.ObserveQuery("UpdateService", time.Now())
+
+	query, args, err := repo.builder.
+		Update("services").
+		Set("name", service.Name).
+		Set("name_key", models.ServiceKey(service.Name)).
+		Set("category", service.Category).
+		Set("currency", service.Currency).
+		Set("updated_at", squirrel.Expr(db.SQLiteNow)).
+		Where(squirrel.Eq{"id": service.ID.String()}).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	result, err := db.SQLiteConn(ctx, repo.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "update service: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to update service: "+err.Error())
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if affected == 0 {
+		return nil, catalog.ErrNotFound
+	}
+
+	if err := repo.replaceDetails(ctx, service); err != nil {
+		return nil, err
+	}
+
+	return repo.GetServiceByID(ctx, service.ID)
+}
+
+func (repo *SQLiteRepository) GetServiceByID(ctx context.Context, id uuid.UUID) (*models.Service, error) {
+	defer repo.metrics.ObserveQuery("GetServiceByID", time.Now())
+
+	return repo.getService(ctx, squirrel.Eq{"id": id.String()})
+}
+
+func (repo *SQLiteRepository) FindServiceByName(ctx context.Context, name string) (*models.Service, error) {
+	defer repo.metrics.ObserveQuery("FindServiceByName", time.Now())
+
+	key := models.ServiceKey(name)
+	return repo.getService(ctx, squirrel.Or{
+		squirrel.Eq{"name_key": key},
+		squirrel.Expr("id IN (SELECT service_id FROM service_aliases WHERE key = ?)", key),
+	})
+}
+
+func (repo *SQLiteRepository) ListServices(ctx context.Context, category string) ([]*models.Service, error) {
+	defer repo.metrics.ObserveQuery("ListServices", time.Now())
+
+	builder := repo.builder.
+		Select(serviceColumns...).
+		From("services").
+		OrderBy("name", "id")
+	if category != "" {
+		builder = builder.Where(squirrel.Eq{"category": category})
+	}
+
+	return repo.queryServices(ctx, builder)
+}
+
+func (repo *SQLiteRepository) DeleteService(ctx context.Context, id uuid.UUID) error {
+	defer repo.metrics.ObserveQuery("DeleteService", time.Now())
+
+	result, err := db.SQLiteConn(ctx, repo.db).ExecContext(ctx, "DELETE FROM services WHERE id = ?", id.String())
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to delete service: "+err.Error())
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return catalog.ErrNotFound
+	}
+
+	return nil
+}
+
+func (repo *SQLiteRepository) replaceDetails(ctx context.Context, service *models.Service) error {
+	conn := db.SQLiteConn(ctx, repo.db)
+	for _, table := range []string{"service_aliases", "service_plans"} {
+		if _, err := conn.ExecContext(ctx, "DELETE FROM "+table+" WHERE service_id = ?", service.ID.String()); err != nil {
+			repo.log.ErrorContext(ctx, "failed to replace service details: "+err.Error())
+			return err
+		}
+	}
+
+	var inserts []squirrel.InsertBuilder
+	if len(service.Aliases) > 0 {
+		insert := repo.builder.Insert("service_aliases").Columns("key", "service_id", "alias")
+		for _, alias := range service.Aliases {
+			insert = insert.Values(models.ServiceKey(alias), service.ID.String(), alias)
+		}
+		inserts = append(inserts, insert)
+	}
+	if len(service.Plans) > 0 {
+		insert := repo.builder.Insert("service_plans").Columns("service_id", "name", "price", "period")
+		for _, plan := range service.Plans {
+			insert = insert.Values(service.ID.String(), plan.Name, plan.Price, plan.Period)
+		}
+		inserts = append(inserts, insert)
+	}
+
+	for _, insert := range inserts {
+		query, args, err := insert.ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+			if domainErr := sqliteViolations.Map(err); domainErr != nil {
+				repo.log.WarnContext(ctx, "save service: "+domainErr.Error())
+				return domainErr
+			}
+			repo.log.ErrorContext(ctx, "failed to replace service details: "+err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *SQLiteRepository) getService(ctx context.Context, where squirrel.Sqlizer) (*models.Service, error) {
+	list, err := repo.queryServices(ctx, repo.builder.
+		Select(serviceColumns...).
+		From("services").
+		Where(where))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, catalog.ErrNotFound
+	}
+
+	return list[0], nil
+}
+
+func (repo *SQLiteRepository) queryServices(ctx context.Context, builder squirrel.SelectBuilder) ([]*models.Service, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn := db.SQLiteConn(ctx, repo.db)
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch services: "+err.Error())
+		return nil, err
+	}
+
+	var (
+		list []*models.Service
+		ids  []string
+	)
+	byID := make(map[uuid.UUID]*models.Service)
+	for rows.Next() {
+		service := &models.Service{Aliases: []string{}, Plans: []models.ServicePlan{}}
+		var createdAt, updatedAt string
+		if err := rows.Scan(&service.ID, &service.Name, &service.Category, &service.Currency, &createdAt, &updatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if service.CreatedAt, service.UpdatedAt, err = parseTimestamps(createdAt, updatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, service)
+		ids = append(ids, service.ID.String())
+		byID[service.ID] = service
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	query, args, err = repo.builder.
+		Select("service_id", "alias").
+		From("service_aliases").
+		Where(squirrel.Eq{"service_id": ids}).
+		OrderBy("alias").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	aliases, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch service aliases: "+err.Error())
+		return nil, err
+	}
+	for aliases.Next() {
+		var (
+			serviceID uuid.UUID
+			alias     string
+		)
+		if err := aliases.Scan(&serviceID, &alias); err != nil {
+			aliases.Close()
+			return nil, err
+		}
+		byID[serviceID].Aliases = append(byID[serviceID].Aliases, alias)
+	}
+	aliases.Close()
+	if err := aliases.Err(); err != nil {
+		return nil, err
+	}
+
+	query, args, err = repo.builder.
+		Select("service_id", "name", "price", "period").
+		From("service_plans").
+		Where(squirrel.Eq{"service_id": ids}).
+		OrderBy("period", "price", "name").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch service plans: "+err.Error())
+		return nil, err
+	}
+	defer plans.Close()
+
+	for plans.Next() {
+		var (
+			serviceID uuid.UUID
+			plan      models.ServicePlan
+		)
+		if err := plans.Scan(&serviceID, &plan.Name, &plan.Price, &plan.Period); err != nil {
+			return nil, err
+		}
+		byID[serviceID].Plans = append(byID[serviceID].Plans, plan)
+	}
+
+	return list, plans.Err()
+}
+
+func parseTimestamps(createdAt, updatedAt string) (time.Time, time.Time, error) {
+	created, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid created_at %q: %w", createdAt, err)
+	}
+	updated, err := time.Parse(time.RFC3339Nano, updatedAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid updated_at %q: %w", updatedAt, err)
+	}
+	return created, updated, nil
+}
+
+// sqliteViolations переводят нарушения ограничений в доменные ошибки.
+// SQLite называет в сообщении таблицу и колонку нарушенного индекса.
+var sqliteViolations = db.SQLiteViolations{
+	{Code: sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, Constraint: "services.id", Err: catalog.ErrAlreadyExists},
+	{Code: sqlite3.SQLITE_CONSTRAINT_UNIQUE, Constraint: "services.id", Err: catalog.ErrAlreadyExists},
+	{Code: sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, Constraint: "service_plans", Err: catalog.ErrDuplicatePlan},
+	{Code: sqlite3.SQLITE_CONSTRAINT_UNIQUE, Constraint: "service_plans", Err: catalog.ErrDuplicatePlan},
+	{Code: sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, Err: catalog.ErrNameTaken},
+	{Code: sqlite3.SQLITE_CONSTRAINT_UNIQUE, Err: catalog.ErrNameTaken},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Constraint: "services_currency_format", Err: catalog.ErrInvalidCurrency},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Constraint: "service_plans_price_non_negative", Err: catalog.ErrNegativePlanPrice},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Constraint: "service_plans_period_positive", Err: catalog.ErrInvalidPlanPeriod},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Err: catalog.ErrInvalidArgument},
+}
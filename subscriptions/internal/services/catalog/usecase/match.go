@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/catalog"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultMatchLimit = 5
+	maxMatchLimit     = 20
+	// minMatchScore отсекает случайные совпадения коротких названий.
+	minMatchScore = 0.6
+)
+
+// MatchServices подбирает сервисы каталога для названия в свободной форме.
+// Названия сравниваются в латинской транслитерации без знаков препинания,
+// так что "Яндекс Плюс" находит "Yandex Plus" и без псевдонима.
+func (u *UseCase) MatchServices(ctx context.Context, name string, limit int) ([]*models.ServiceMatch, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.MatchServices")
+	defer span.End()
+
+	query := fuzzyKey(name)
+	if query == "" {
+		return nil, recordError(span, catalog.ErrQueryRequired)
+	}
+	if limit <= 0 {
+		limit = defaultMatchLimit
+	}
+	limit = min(limit, maxMatchLimit)
+
+	services, err := u.repo.ListServices(ctx, "")
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	matches := make([]*models.ServiceMatch, 0, limit)
+	for _, service := range services {
+		best := &models.ServiceMatch{Service: service}
+		for _, candidate := range append([]string{service.Name}, service.Aliases...) {
+			if score := similarity(query, fuzzyKey(candidate)); score > best.Score {
+				best.Score, best.Matched = score, candidate
+			}
+		}
+		if best.Score >= minMatchScore {
+			matches = append(matches, best)
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b *models.ServiceMatch) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Service.Name, b.Service.Name)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
+	'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f",
+	'х': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y",
+	'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// fuzzyKey транслитерирует название и оставляет только буквы и цифры.
+// "кс" передается как x: так пишутся Yandex, Xbox и подобные.
+func fuzzyKey(name string) string {
+	key := strings.ReplaceAll(models.ServiceKey(name), "кс", "x")
+
+	var b strings.Builder
+	for _, r := range key {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// similarity оценивает сходство от 0 до 1: по расстоянию Левенштейна
+// или, если одно название содержит другое, по доле общей части.
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	la, lb := utf8.RuneCountInString(a), utf8.RuneCountInString(b)
+	longest := max(la, lb)
+	score := 1 - float64(levenshtein(a, b))/float64(longest)
+
+	shortest := min(la, lb)
+	if shortest >= 3 && (strings.Contains(a, b) || strings.Contains(b, a)) {
+		score = max(score, 0.8+0.2*float64(shortest)/float64(longest))
+	}
+	return score
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/catalog"
+	"go.opentelemetry.io/otel/trace/noop"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"testing"
+)
+
+// staticCatalog отдает заданный список сервисов.
+type staticCatalog struct {
+	catalog.Repository
+
+	services []*models.Service
+}
+
+func (c staticCatalog) ListServices(context.Context, string) ([]*models.Service, error) {
+	return c.services, nil
+}
+
+func newMatchUseCase(services ...*models.Service) *UseCase {
+	return &UseCase{
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		repo:   staticCatalog{services: services},
+		tracer: noop.NewTracerProvider().Tracer(tracerName),
+	}
+}
+
+func TestMatchServices(t *testing.T) {
+	u := newMatchUseCase(
+		&models.Service{Name: "Yandex Plus", Aliases: []string{"Плюс"}},
+		&models.Service{Name: "Netflix"},
+		&models.Service{Name: "Spotify"},
+		&models.Service{Name: "Spotify Family"},
+		&models.Service{Name: "Apple Music", Aliases: []string{"iTunes"}},
+	)
+
+	tests := []struct {
+		name  string
+		query string
+		// want - совпавшие названия или псевдонимы по убыванию сходства.
+		want []string
+		err  error
+	}{
+		{name: "транслитерация", query: "Яндекс Плюс", want: []string{"Yandex Plus"}},
+		{name: "регистр и знаки препинания", query: "  NETFLIX!! ", want: []string{"Netflix"}},
+		{name: "опечатка", query: "Netflx", want: []string{"Netflix"}},
+		{name: "псевдоним", query: "itunes", want: []string{"iTunes"}},
+		{name: "точное совпадение выше вхождения", query: "spotify", want: []string{"Spotify", "Spotify Family"}},
+		{name: "ниже порога", query: "Nfx"},
+		{name: "пустой запрос", query: " !! ", err: catalog.ErrQueryRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := u.MatchServices(context.Background(), tt.query, 0)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.Matched)
+				if m.Score < minMatchScore || m.Score > 1 {
+					t.Errorf("%s score = %v", m.Matched, m.Score)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchServicesLimit(t *testing.T) {
+	var services []*models.Service
+	for i := range maxMatchLimit + 5 {
+		services = append(services, &models.Service{Name: fmt.Sprintf("Music %d", i+1)})
+	}
+	u := newMatchUseCase(services...)
+
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{name: "по умолчанию", limit: 0, want: defaultMatchLimit},
+		{name: "заданный", limit: 2, want: 2},
+		{name: "больше максимума", limit: 100, want: maxMatchLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := u.MatchServices(context.Background(), "music", tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(matches) != tt.want {
+				t.Fatalf("len(matches) = %d, want %d", len(matches), tt.want)
+			}
+			// Короткие названия ближе к запросу, при равенстве - по алфавиту.
+			if got := matches[0].Service.Name; got != "Music 1" {
+				t.Errorf("first match = %s, want Music 1", got)
+			}
+		})
+	}
+}
+
+func TestFuzzyKey(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "латиница", in: "Yandex Plus", want: "yandexplus"},
+		{name: "кириллица", in: "Яндекс Плюс", want: "yandexplyus"},
+		{name: "ё как е", in: "Ёлка", want: "elka"},
+		{name: "многобуквенные замены", in: "Щука Чай Жук", want: "schukachayzhuk"},
+		{name: "твердый и мягкий знак", in: "Подъезд Соль", want: "podezdsol"},
+		{name: "знаки препинания и пробелы", in: "  Disney+ / Hulu! ", want: "disneyhulu"},
+		{name: "цифры", in: "Кинопоиск HD 2", want: "kinopoiskhd2"},
+		{name: "только знаки", in: "+-!", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fuzzyKey(tt.in); got != tt.want {
+				t.Errorf("fuzzyKey(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{name: "пустая строка", a: "", b: "netflix", want: 0},
+		{name: "совпадение", a: "netflix", b: "netflix", want: 1},
+		{name: "одна замена", a: "netflix", b: "netflux", want: 1 - 1.0/7},
+		{name: "вхождение", a: "spotify", b: "spotifyfamily", want: 0.8 + 0.2*7/13},
+		{name: "вхождение короче трех символов", a: "hb", b: "hbomax", want: 1 - 4.0/6},
+		{name: "ничего общего", a: "abc", b: "xyz", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, args := range [][2]string{{tt.a, tt.b}, {tt.b, tt.a}} {
+				if got := similarity(args[0], args[1]); math.Abs(got-tt.want) > 1e-9 {
+					t.Errorf("similarity(%q, %q) = %v, want %v", args[0], args[1], got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "", b: "abc", want: 3},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "flaw", b: "lawn", want: 2},
+		// Расстояние считается по символам, а не по байтам.
+		{a: "абв", b: "абг", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := levenshtein(tt.a, tt.b); got != tt.want {
+				t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := levenshtein(tt.b, tt.a); got != tt.want {
+				t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
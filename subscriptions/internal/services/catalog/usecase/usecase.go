@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/catalog"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type Params struct {
+	fx.In
+
+	Logger         *slog.Logger
+	Repo           catalog.Repository
+	Subscriptions  subscriptions.Repository
+	Events         events.Publisher
+	Tx             tx.Manager
+	TracerProvider trace.TracerProvider
+}
+
+type UseCase struct {
+	log           *slog.Logger
+	repo          catalog.Repository
+	subscriptions subscriptions.Repository
+	events        events.Publisher
+	tx            tx.Manager
+	tracer        trace.Tracer
+}
+
+func NewUseCase(params Params) *UseCase {
+	return &UseCase{
+		log:           params.Logger,
+		repo:          params.Repo,
+		subscriptions: params.Subscriptions,
+		events:        params.Events,
+		tx:            params.Tx,
+		tracer:        params.TracerProvider.Tracer(tracerName),
+	}
+}
+
+func (u *UseCase) CreateService(ctx context.Context, service *models.Service) (*models.Service, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.CreateService")
+	defer span.End()
+
+	if service.ID == uuid.Nil {
+		service.ID = uuid.New()
+	}
+	if service.Currency == "" {
+		service.Currency = models.DefaultCurrency
+	}
+
+	normalize(service)
+	if err := validate(service); err != nil {
+		u.log.WarnContext(ctx, "create service: "+err.Error())
+		return nil, recordError(span, err)
+	}
+
+	var created *models.Service
+	err := u.tx.Do(ctx, tx.Options{}, func(ctx context.Context) error {
+		if err := u.checkNames(ctx, service); err != nil {
+			return err
+		}
+
+		var err error
+		created, err = u.repo.CreateService(ctx, service)
+		return err
+	})
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	return created, nil
+}
+
+// UpdateService при смене названия переписывает его и в подписках сервиса,
+// чтобы отчеты с фильтром по названию продолжали их находить. Новая
+// категория переходит к подпискам, где категория не задана вручную.
+func (u *UseCase) UpdateService(ctx context.Context, id uuid.UUID, update models.ServiceUpdate) (*models.Service, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.UpdateService", trace.WithAttributes(serviceIDAttr(id)))
+	defer span.End()
+
+	if update == (models.ServiceUpdate{}) {
+		return nil, catalog.ErrNoFieldsToUpdate
+	}
+
+	var updated *models.Service
+	err := u.tx.Do(ctx, tx.Options{Isolation: tx.RepeatableRead}, func(ctx context.Context) error {
+		current, err := u.repo.GetServiceByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		service := *current
+		update.Apply(&service)
+		normalize(&service)
+		if err := validate(&service); err != nil {
+			u.log.WarnContext(ctx, "update service: "+err.Error())
+			return err
+		}
+		if err := u.checkNames(ctx, &service); err != nil {
+			return err
+		}
+
+		if updated, err = u.repo.UpdateService(ctx, &service); err != nil {
+			return err
+		}
+		renamed := updated.Name != current.Name
+		recategorized := updated.Category != current.Category
+		if !renamed && !recategorized {
+			return nil
+		}
+		return u.relink(ctx, current, func(sub *models.Subscription) map[string]interface{} {
+			updates := make(map[string]interface{})
+			if renamed {
+				updates["service_name"] = updated.Name
+			}
+			if recategorized && sub.Category == current.Category {
+				updates["category"] = updated.Category
+			}
+			return updates
+		})
+	})
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	return updated, nil
+}
+
+func (u *UseCase) GetServiceByID(ctx context.Context, id uuid.UUID) (*models.Service, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetServiceByID", trace.WithAttributes(serviceIDAttr(id)))
+	defer span.End()
+
+	service, err := u.repo.GetServiceByID(ctx, id)
+	return service, recordError(span, err)
+}
+
+func (u *UseCase) ListServices(ctx context.Context, category string) ([]*models.Service, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.ListServices")
+	defer span.End()
+
+	result, err := u.repo.ListServices(ctx, models.NormalizeLabel(category))
+	return result, recordError(span, err)
+}
+
+// DeleteService отвязывает подписки сервиса от каталога: они остаются
+// с прежним названием как свободный текст.
+func (u *UseCase) DeleteService(ctx context.Context, id uuid.UUID) error {
+	ctx, span := u.tracer.Start(ctx, "UseCase.DeleteService", trace.WithAttributes(serviceIDAttr(id)))
+	defer span.End()
+
+	err := u.tx.Do(ctx, tx.Options{}, func(ctx context.Context) error {
+		service, err := u.repo.GetServiceByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		unlink := func(*models.Subscription) map[string]interface{} {
+			return map[string]interface{}{"service_id": nil}
+		}
+		if err := u.relink(ctx, service, unlink); err != nil {
+			return err
+		}
+		return u.repo.DeleteService(ctx, id)
+	})
+	return recordError(span, err)
+}
+
+// relink применяет к подпискам, связанным с service, изменения, которые
+// для каждой из них возвращает updates; пустые изменения пропускаются.
+// Связанные подписки всегда хранят каноническое название, поэтому выборка
+// идет по нему.
+func (u *UseCase) relink(ctx context.Context, service *models.Service, updates func(*models.Subscription) map[string]interface{}) error {
+	subs, err := u.subscriptions.ListSubscriptions(ctx, models.SubscriptionFilter{ServiceName: service.Name})
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		if sub.ServiceID == nil || *sub.ServiceID != service.ID {
+			continue
+		}
+		changes := updates(sub)
+		if len(changes) == 0 {
+			continue
+		}
+		updated, err := u.subscriptions.UpdateSubscription(ctx, sub.ID, changes)
+		if err != nil {
+			return err
+		}
+		u.publish(ctx, updated)
+	}
+	return nil
+}
+
+// checkNames не дает занять название или псевдоним другого сервиса:
+// по ним подписки связываются с каталогом.
+func (u *UseCase) checkNames(ctx context.Context, service *models.Service) error {
+	for _, name := range append([]string{service.Name}, service.Aliases...) {
+		found, err := u.repo.FindServiceByName(ctx, name)
+		if errors.Is(err, catalog.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if found.ID != service.ID {
+			u.log.WarnContext(ctx, "service name "+name+" is used by "+found.Name)
+			return catalog.ErrNameTaken
+		}
+	}
+	return nil
+}
+
+func (u *UseCase) publish(ctx context.Context, sub *models.Subscription) {
+	tx.AfterCommit(ctx, func(ctx context.Context) {
+		if err := u.events.Publish(ctx, events.NewEvent(events.TypeUpdated, sub)); err != nil {
+			u.log.WarnContext(ctx, "failed to publish "+string(events.TypeUpdated)+" event: "+err.Error())
+		}
+	})
+}
+
+// normalize убирает лишние пробелы и повторяющиеся псевдонимы, в том числе
+// совпадающие с названием.
+func normalize(service *models.Service) {
+	service.Name = strings.Join(strings.Fields(service.Name), " ")
+	service.Category = models.NormalizeLabel(service.Category)
+
+	seen := map[string]bool{models.ServiceKey(service.Name): true}
+	aliases := make([]string, 0, len(service.Aliases))
+	for _, alias := range service.Aliases {
+		alias = strings.Join(strings.Fields(alias), " ")
+		key := models.ServiceKey(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		aliases = append(aliases, alias)
+	}
+	service.Aliases = aliases
+
+	if service.Plans == nil {
+		service.Plans = []models.ServicePlan{}
+	}
+	for i := range service.Plans {
+		service.Plans[i].Name = strings.TrimSpace(service.Plans[i].Name)
+	}
+}
+
+func validate(service *models.Service) error {
+	if service.Name == "" {
+		return catalog.ErrNameRequired
+	}
+	if !currencyPattern.MatchString(service.Currency) {
+		return catalog.ErrInvalidCurrency
+	}
+
+	plans := make(map[string]bool, len(service.Plans))
+	for _, plan := range service.Plans {
+		switch {
+		case plan.Name == "":
+			return catalog.ErrPlanNameRequired
+		case plans[plan.Name]:
+			return catalog.ErrDuplicatePlan
+		case plan.Price < 0:
+			return catalog.ErrNegativePlanPrice
+		case plan.Period <= 0:
+			return catalog.ErrInvalidPlanPeriod
+		}
+		plans[plan.Name] = true
+	}
+	return nil
+}
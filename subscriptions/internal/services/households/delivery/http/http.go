@@ -0,0 +1,345 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/households"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/pkg/reader"
+	"github.com/ekkserapopova/subscriptions/pkg/responser"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/fx"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type Params struct {
+	fx.In
+
+	Logger  *slog.Logger
+	UseCase households.UseCase
+}
+
+type Handler struct {
+	logger  *slog.Logger
+	usecase households.UseCase
+}
+
+func NewHandler(params Params) *Handler {
+	return &Handler{
+		logger:  params.Logger,
+		usecase: params.UseCase,
+	}
+}
+
+type memberRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// CreateHousehold godoc
+// @Summary Создать домохозяйство
+// @Description Создает домохозяйство с начальным списком участников
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param household body models.Household true "Домохозяйство"
+// @Success 201 {object} models.Household
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households [post]
+func (h *Handler) CreateHousehold(w http.ResponseWriter, r *http.Request) {
+	household := &models.Household{}
+	if err := reader.ReadResponseData(r, household); err != nil {
+		h.logger.ErrorContext(r.Context(), "create household request err: "+err.Error())
+		responser.SendErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.usecase.CreateHousehold(r.Context(), household)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusCreated, created)
+}
+
+// @Summary Получить домохозяйство
+// @Tags households
+// @Produce json
+// @Param id path string true "ID домохозяйства"
+// @Success 200 {object} models.Household
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/{id} [get]
+func (h *Handler) GetHouseholdByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	household, err := h.usecase.GetHouseholdByID(r.Context(), id)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, household)
+}
+
+// @Summary Получить все домохозяйства
+// @Tags households
+// @Produce json
+// @Success 200 {array} models.Household
+// @Failure 500 {object} map[string]string
+// @Router /households [get]
+func (h *Handler) ListHouseholds(w http.ResponseWriter, r *http.Request) {
+	list, err := h.usecase.ListHouseholds(r.Context())
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+	if list == nil {
+		list = []*models.Household{}
+	}
+
+	responser.SendOK(w, http.StatusOK, list)
+}
+
+// @Summary Удалить домохозяйство
+// @Description Удаляет домохозяйство и правила разделения его подписок; сами подписки остаются у владельцев
+// @Tags households
+// @Produce json
+// @Param id path string true "ID домохозяйства"
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/{id} [delete]
+func (h *Handler) DeleteHousehold(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.usecase.DeleteHousehold(r.Context(), id); err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusNoContent, map[string]string{"msg": "household deleted"})
+}
+
+// @Summary Добавить участника
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "ID домохозяйства"
+// @Param member body memberRequest true "Пользователь"
+// @Success 200 {object} models.Household
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/{id}/members [post]
+func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	member := memberRequest{}
+	if err := reader.ReadResponseData(r, &member); err != nil {
+		responser.SendErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	household, err := h.usecase.AddMember(r.Context(), id, member.UserID)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, household)
+}
+
+// @Summary Исключить участника
+// @Description Исключает участника и удаляет его доли. Участника, который платит за общую подписку, исключить нельзя
+// @Tags households
+// @Produce json
+// @Param id path string true "ID домохозяйства"
+// @Param user_id path string true "ID пользователя"
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/{id}/members/{user_id} [delete]
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, ok := pathID(w, r, "user_id")
+	if !ok {
+		return
+	}
+
+	if err := h.usecase.RemoveMember(r.Context(), id, userID); err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusNoContent, map[string]string{"msg": "member removed"})
+}
+
+// @Summary Взаиморасчеты домохозяйства
+// @Description Кто сколько заплатил за общие подписки, действующие в месяце, какова его доля и кто кому должен перевести
+// @Tags households
+// @Produce json
+// @Param id path string true "ID домохозяйства"
+// @Param month query string false "Месяц в формате MM-YYYY, по умолчанию текущий"
+// @Success 200 {object} models.Settlement
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /households/{id}/settlement [get]
+func (h *Handler) GetSettlement(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	month := time.Now().UTC()
+	if value := r.URL.Query().Get("month"); value != "" {
+		parsed, err := time.Parse("01-2006", value)
+		if err != nil {
+			responser.SendErr(w, http.StatusBadRequest, households.ErrInvalidMonth.Error())
+			return
+		}
+		month = parsed
+	}
+
+	settlement, err := h.usecase.GetSettlement(r.Context(), id, month)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, settlement)
+}
+
+// @Summary Сделать подписку общей
+// @Description Задает или заменяет правило разделения подписки в домохозяйстве. Платит владелец подписки, ему достается остаток цены. Для equal без shares цена делится на всех участников
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param split body models.Split true "Правило разделения"
+// @Success 200 {object} models.Split
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/split [put]
+func (h *Handler) SetSplit(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	split := &models.Split{}
+	if err := reader.ReadResponseData(r, split); err != nil {
+		responser.SendErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	split.SubscriptionID = id
+
+	saved, err := h.usecase.SetSplit(r.Context(), split)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, saved)
+}
+
+// @Summary Правило разделения подписки
+// @Tags households
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} models.Split
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/split [get]
+func (h *Handler) GetSplit(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	split, err := h.usecase.GetSplit(r.Context(), id)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, split)
+}
+
+// @Summary Перестать делить подписку
+// @Tags households
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/split [delete]
+func (h *Handler) DeleteSplit(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.usecase.DeleteSplit(r.Context(), id); err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusNoContent, map[string]string{"msg": "split deleted"})
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)[name])
+	if err != nil {
+		responser.SendErr(w, http.StatusBadRequest, "invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, households.ErrNotFound),
+		errors.Is(err, households.ErrSplitNotFound),
+		errors.Is(err, households.ErrMemberNotFound),
+		errors.Is(err, subscriptions.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, households.ErrAlreadyExists),
+		errors.Is(err, households.ErrAlreadyMember),
+		errors.Is(err, households.ErrMemberIsPayer):
+		return http.StatusConflict
+	case errors.Is(err, households.ErrInvalidArgument):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,34 @@
+package households
+
+import (
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/errs"
+)
+
+var (
+	ErrNotFound        = errors.New("household not found")
+	ErrAlreadyExists   = errors.New("household with this id already exists")
+	ErrSplitNotFound   = errors.New("subscription is not shared")
+	ErrMemberNotFound  = errors.New("user is not a member of the household")
+	ErrAlreadyMember   = errors.New("user is already a member of the household")
+	ErrInvalidArgument = errs.ErrInvalidArgument
+	// ErrMemberIsPayer возвращается при исключении участника, который платит
+	// за общую подписку домохозяйства.
+	ErrMemberIsPayer = errors.New("member pays for a shared subscription, remove its split first")
+)
+
+var (
+	ErrNameRequired    = errs.InvalidArgument("name is required")
+	ErrUserNotFound    = errs.InvalidArgument("user not found")
+	ErrInvalidRule     = errs.InvalidArgument("rule must be equal, percentage or fixed")
+	ErrPayerNotMember  = errs.InvalidArgument("subscription owner is not a member of the household")
+	ErrShareNotMember  = errs.InvalidArgument("share user is not a member of the household")
+	ErrDuplicateShare  = errs.InvalidArgument("duplicate share user")
+	ErrPayerShare      = errs.InvalidArgument("payer share is the remainder and cannot be set")
+	ErrNegativeShare   = errs.InvalidArgument("share must not be negative")
+	ErrUnexpectedValue = errs.InvalidArgument("equal split does not take share values")
+	ErrPercentageTotal = errs.InvalidArgument("percentage shares exceed 100")
+	ErrFixedTotal      = errs.InvalidArgument("fixed shares exceed subscription price")
+	ErrNoParticipants  = errs.InvalidArgument("split needs at least one participant besides the payer")
+	ErrInvalidMonth    = errs.InvalidArgument("month must be in MM-YYYY format")
+)
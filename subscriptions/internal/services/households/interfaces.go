@@ -0,0 +1,35 @@
+package households
+
+import (
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/google/uuid"
+	"time"
+)
+
+type UseCase interface {
+	CreateHousehold(ctx context.Context, household *models.Household) (*models.Household, error)
+	GetHouseholdByID(ctx context.Context, id uuid.UUID) (*models.Household, error)
+	ListHouseholds(ctx context.Context) ([]*models.Household, error)
+	DeleteHousehold(ctx context.Context, id uuid.UUID) error
+	AddMember(ctx context.Context, householdID, userID uuid.UUID) (*models.Household, error)
+	RemoveMember(ctx context.Context, householdID, userID uuid.UUID) error
+	SetSplit(ctx context.Context, split *models.Split) (*models.Split, error)
+	GetSplit(ctx context.Context, subscriptionID uuid.UUID) (*models.Split, error)
+	DeleteSplit(ctx context.Context, subscriptionID uuid.UUID) error
+	GetSettlement(ctx context.Context, householdID uuid.UUID, month time.Time) (*models.Settlement, error)
+}
+
+type Repository interface {
+	CreateHousehold(ctx context.Context, household *models.Household) (*models.Household, error)
+	GetHouseholdByID(ctx context.Context, id uuid.UUID) (*models.Household, error)
+	ListHouseholds(ctx context.Context) ([]*models.Household, error)
+	DeleteHousehold(ctx context.Context, id uuid.UUID) error
+	AddMember(ctx context.Context, householdID, userID uuid.UUID) error
+	RemoveMember(ctx context.Context, householdID, userID uuid.UUID) error
+	SetSplit(ctx context.Context, split *models.Split) (*models.Split, error)
+	GetSplit(ctx context.Context, subscriptionID uuid.UUID) (*models.Split, error)
+	DeleteSplit(ctx context.Context, subscriptionID uuid.UUID) error
+	ListSplits(ctx context.Context, userIDs []uuid.UUID) ([]*models.Split, error)
+	ListHouseholdSplits(ctx context.Context, householdID uuid.UUID) ([]*models.Split, error)
+}
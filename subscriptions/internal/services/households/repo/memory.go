@@ -0,0 +1,323 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/households"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// UserChecker заменяет внешние ключи на пользователя в памяти.
+type UserChecker interface {
+	UserExists(id uuid.UUID) bool
+}
+
+type MemoryParams struct {
+	fx.In
+
+	Logger *slog.Logger
+	Users  UserChecker `optional:"true"`
+}
+
+// MemoryRepository хранит домохозяйства в памяти процесса. Изменения внутри
+// tx.Manager.Do отменяются через tx.OnRollback. Удаленные пользователи не
+// видны среди участников и долей, как после ON DELETE CASCADE.
+type MemoryRepository struct {
+	log   *slog.Logger
+	users UserChecker
+
+	mu         sync.RWMutex
+	households map[uuid.UUID]*models.Household
+	splits     map[uuid.UUID]*models.Split
+}
+
+func NewMemoryRepository(params MemoryParams) *MemoryRepository {
+	return &MemoryRepository{
+		log:        params.Logger,
+		users:      params.Users,
+		households: make(map[uuid.UUID]*models.Household),
+		splits:     make(map[uuid.UUID]*models.Split),
+	}
+}
+
+func (repo *MemoryRepository) CreateHousehold(ctx context.Context, household *models.Household) (*models.Household, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.households[household.ID]; ok {
+		return nil, households.ErrAlreadyExists
+	}
+
+	now := time.Now().UTC()
+	created := &models.Household{ID: household.ID, Name: household.Name, Members: []models.HouseholdMember{}, CreatedAt: now, UpdatedAt: now}
+	for _, member := range household.Members {
+		if !repo.userExists(member.UserID) {
+			return nil, households.ErrUserNotFound
+		}
+		created.Members = append(created.Members, models.HouseholdMember{UserID: member.UserID, JoinedAt: now})
+	}
+
+	repo.putHousehold(ctx, created)
+	return repo.cloneHousehold(created), nil
+}
+
+func (repo *MemoryRepository) GetHouseholdByID(_ context.Context, id uuid.UUID) (*models.Household, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	household, ok := repo.households[id]
+	if !ok {
+		return nil, households.ErrNotFound
+	}
+	return repo.cloneHousehold(household), nil
+}
+
+func (repo *MemoryRepository) ListHouseholds(context.Context) ([]*models.Household, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	list := make([]*models.Household, 0, len(repo.households))
+	for _, household := range repo.households {
+		list = append(list, repo.cloneHousehold(household))
+	}
+	slices.SortFunc(list, func(a, b *models.Household) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	return list, nil
+}
+
+// DeleteHousehold удаляет и правила разделения домохозяйства.
+func (repo *MemoryRepository) DeleteHousehold(ctx context.Context, id uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	household, ok := repo.households[id]
+	if !ok {
+		return households.ErrNotFound
+	}
+
+	for subscriptionID, split := range repo.splits {
+		if split.HouseholdID == id {
+			repo.putSplit(ctx, subscriptionID, nil)
+		}
+	}
+	delete(repo.households, id)
+	tx.OnRollback(ctx, func() {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		repo.households[id] = household
+	})
+
+	return nil
+}
+
+func (repo *MemoryRepository) AddMember(ctx context.Context, householdID, userID uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	household, ok := repo.households[householdID]
+	if !ok {
+		return households.ErrNotFound
+	}
+	if !repo.userExists(userID) {
+		return households.ErrUserNotFound
+	}
+	if household.HasMember(userID) {
+		return households.ErrAlreadyMember
+	}
+
+	updated := *household
+	updated.Members = append(slices.Clone(household.Members), models.HouseholdMember{UserID: userID, JoinedAt: time.Now().UTC()})
+	repo.putHousehold(ctx, &updated)
+
+	return nil
+}
+
+// RemoveMember удаляет и доли участника в правилах домохозяйства.
+func (repo *MemoryRepository) RemoveMember(ctx context.Context, householdID, userID uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	household, ok := repo.households[householdID]
+	if !ok || !household.HasMember(userID) {
+		return households.ErrMemberNotFound
+	}
+
+	updated := *household
+	updated.Members = slices.DeleteFunc(slices.Clone(household.Members), func(m models.HouseholdMember) bool {
+		return m.UserID == userID
+	})
+	repo.putHousehold(ctx, &updated)
+
+	for subscriptionID, split := range repo.splits {
+		if split.HouseholdID != householdID {
+			continue
+		}
+		trimmed := cloneSplit(split)
+		trimmed.Shares = slices.DeleteFunc(trimmed.Shares, func(s models.SplitShare) bool {
+			return s.UserID == userID
+		})
+		repo.putSplit(ctx, subscriptionID, trimmed)
+	}
+
+	return nil
+}
+
+func (repo *MemoryRepository) SetSplit(ctx context.Context, split *models.Split) (*models.Split, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	household, ok := repo.households[split.HouseholdID]
+	if !ok {
+		return nil, households.ErrNotFound
+	}
+	for _, share := range split.Shares {
+		if !household.HasMember(share.UserID) {
+			return nil, households.ErrShareNotMember
+		}
+	}
+
+	saved := cloneSplit(split)
+	now := time.Now().UTC()
+	saved.CreatedAt, saved.UpdatedAt = now, now
+	if current, ok := repo.splits[split.SubscriptionID]; ok {
+		saved.CreatedAt = current.CreatedAt
+	}
+	repo.putSplit(ctx, split.SubscriptionID, saved)
+
+	return repo.visibleSplit(saved), nil
+}
+
+func (repo *MemoryRepository) GetSplit(_ context.Context, subscriptionID uuid.UUID) (*models.Split, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	split, ok := repo.splits[subscriptionID]
+	if !ok {
+		return nil, households.ErrSplitNotFound
+	}
+	return repo.visibleSplit(split), nil
+}
+
+func (repo *MemoryRepository) DeleteSplit(ctx context.Context, subscriptionID uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.splits[subscriptionID]; !ok {
+		return households.ErrSplitNotFound
+	}
+	repo.putSplit(ctx, subscriptionID, nil)
+
+	return nil
+}
+
+func (repo *MemoryRepository) ListSplits(_ context.Context, userIDs []uuid.UUID) ([]*models.Split, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.listSplits(func(split *models.Split) bool {
+		if len(userIDs) == 0 {
+			return true
+		}
+		household := repo.households[split.HouseholdID]
+		return slices.ContainsFunc(userIDs, func(id uuid.UUID) bool {
+			return household.HasMember(id) && repo.userExists(id)
+		})
+	}), nil
+}
+
+func (repo *MemoryRepository) ListHouseholdSplits(_ context.Context, householdID uuid.UUID) ([]*models.Split, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.listSplits(func(split *models.Split) bool {
+		return split.HouseholdID == householdID
+	}), nil
+}
+
+func (repo *MemoryRepository) listSplits(match func(*models.Split) bool) []*models.Split {
+	var list []*models.Split
+	for _, split := range repo.splits {
+		if match(split) {
+			list = append(list, repo.visibleSplit(split))
+		}
+	}
+	slices.SortFunc(list, func(a, b *models.Split) int {
+		return bytes.Compare(a.SubscriptionID[:], b.SubscriptionID[:])
+	})
+	return list
+}
+
+// putHousehold и putSplit сохраняют запись (nil - удаляют) и при откате
+// транзакции возвращают прежнее значение.
+func (repo *MemoryRepository) putHousehold(ctx context.Context, household *models.Household) {
+	previous, existed := repo.households[household.ID]
+	repo.households[household.ID] = household
+	tx.OnRollback(ctx, func() {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		if existed {
+			repo.households[household.ID] = previous
+		} else {
+			delete(repo.households, household.ID)
+		}
+	})
+}
+
+func (repo *MemoryRepository) putSplit(ctx context.Context, subscriptionID uuid.UUID, split *models.Split) {
+	previous, existed := repo.splits[subscriptionID]
+	if split == nil {
+		delete(repo.splits, subscriptionID)
+	} else {
+		repo.splits[subscriptionID] = split
+	}
+	tx.OnRollback(ctx, func() {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		if existed {
+			repo.splits[subscriptionID] = previous
+		} else {
+			delete(repo.splits, subscriptionID)
+		}
+	})
+}
+
+func (repo *MemoryRepository) userExists(id uuid.UUID) bool {
+	return repo.users == nil || repo.users.UserExists(id)
+}
+
+func (repo *MemoryRepository) cloneHousehold(household *models.Household) *models.Household {
+	clone := *household
+	clone.Members = make([]models.HouseholdMember, 0, len(household.Members))
+	for _, member := range household.Members {
+		if repo.userExists(member.UserID) {
+			clone.Members = append(clone.Members, member)
+		}
+	}
+	return &clone
+}
+
+func (repo *MemoryRepository) visibleSplit(split *models.Split) *models.Split {
+	clone := cloneSplit(split)
+	clone.Shares = slices.DeleteFunc(clone.Shares, func(s models.SplitShare) bool {
+		return !repo.userExists(s.UserID)
+	})
+	return clone
+}
+
+func cloneSplit(split *models.Split) *models.Split {
+	clone := *split
+	clone.Shares = slices.Clone(split.Shares)
+	if clone.Shares == nil {
+		clone.Shares = []models.SplitShare{}
+	}
+	return &clone
+}
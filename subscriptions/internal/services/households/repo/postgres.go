@@ -0,0 +1,450 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/services/households"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/fx"
+	"log/slog"
+	"time"
+)
+
+var (
+	householdColumns = []string{"id", "name", "created_at", "updated_at"}
+	splitColumns     = []string{"subscription_id", "household_id", "rule", "created_at", "updated_at"}
+)
+
+type Params struct {
+	fx.In
+
+	Logger  *slog.Logger
+	Cluster *db.Cluster
+	Builder squirrel.StatementBuilderType
+	Metrics *metrics.Metrics
+}
+
+// Repository хранит домохозяйства и правила разделения подписок. Запись
+// домохозяйства с участниками и правила с долями занимает несколько
+// запросов, поэтому такие методы вызываются внутри tx.Manager.Do.
+type Repository struct {
+	cluster *db.Cluster
+	log     *slog.Logger
+	builder squirrel.StatementBuilderType
+	metrics *metrics.Metrics
+}
+
+func NewRepository(params Params) *Repository {
+	return &Repository{
+		cluster: params.Cluster,
+		log:     params.Logger,
+		builder: params.Builder,
+		metrics: params.Metrics,
+	}
+}
+
+func (repo *Repository) CreateHousehold(ctx context.Context, household *models.Household) (*models.Household, error) {
+	defer repo.metrics.ObserveQuery("CreateHousehold", time.Now())
+
+	query, args, err := repo.builder.
+		Insert("households").
+		Columns("id", "name").
+		Values(household.ID, household.Name).
+		Suffix("RETURNING created_at, updated_at").
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	created := &models.Household{ID: household.ID, Name: household.Name, Members: []models.HouseholdMember{}}
+	if err := repo.cluster.Writer(ctx).QueryRow(ctx, query, args...).Scan(&created.CreatedAt, &created.UpdatedAt); err != nil {
+		if domainErr := violation(err); domainErr != nil {
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to create household: "+err.Error())
+		return nil, err
+	}
+
+	for _, member := range household.Members {
+		if err := repo.AddMember(ctx, household.ID, member.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return repo.GetHouseholdByID(ctx, household.ID)
+}
+
+func (repo *Repository) GetHouseholdByID(ctx context.Context, id uuid.UUID) (*models.Household, error) {
+	defer repo.metrics.ObserveQuery("GetHouseholdByID", time.Now())
+
+	list, err := repo.queryHouseholds(ctx, repo.builder.
+		Select(householdColumns...).
+		From("households").
+		Where(squirrel.Eq{"id": id}))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, households.ErrNotFound
+	}
+
+	return list[0], nil
+}
+
+func (repo *Repository) ListHouseholds(ctx context.Context) ([]*models.Household, error) {
+	defer repo.metrics.ObserveQuery("ListHouseholds", time.Now())
+
+	return repo.queryHouseholds(ctx, repo.builder.
+		Select(householdColumns...).
+		From("households").
+		OrderBy("id"))
+}
+
+func (repo *Repository) DeleteHousehold(ctx context.Context, id uuid.UUID) error {
+	defer repo.metrics.ObserveQuery("DeleteHousehold", time.Now())
+
+	query, args, err := repo.builder.
+		Delete("households").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := repo.cluster.Writer(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to delete household: "+err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return households.ErrNotFound
+	}
+
+	return nil
+}
+
+func (repo *Repository) AddMember(ctx context.Context, householdID, userID uuid.UUID) error {
+	defer repo.metrics.ObserveQuery("AddMember", time.Now())
+
+	query, args, err := repo.builder.
+		Insert("household_members").
+		Columns("household_id", "user_id").
+		Values(householdID, userID).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := repo.cluster.Writer(ctx).Exec(ctx, query, args...); err != nil {
+		if domainErr := violation(err); domainErr != nil {
+			return domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to add household member: "+err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// RemoveMember удаляет и доли участника: на них ссылается внешний ключ
+// с ON DELETE CASCADE.
+func (repo *Repository) RemoveMember(ctx context.Context, householdID, userID uuid.UUID) error {
+	defer repo.metrics.ObserveQuery("RemoveMember", time.Now())
+
+	query, args, err := repo.builder.
+		Delete("household_members").
+		Where(squirrel.Eq{"household_id": householdID, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := repo.cluster.Writer(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to remove household member: "+err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return households.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+// SetSplit заменяет правило подписки и все ее доли.
+func (repo *Repository) SetSplit(ctx context.Context, split *models.Split) (*models.Split, error) {
+	defer repo.metrics.ObserveQuery("SetSplit", time.Now())
+
+	query, args, err := repo.builder.
+		Insert("subscription_splits").
+		Columns("subscription_id", "household_id", "rule").
+		Values(split.SubscriptionID, split.HouseholdID, string(split.Rule)).
+		Suffix("ON CONFLICT (subscription_id) DO UPDATE SET household_id = EXCLUDED.household_id, rule = EXCLUDED.rule, updated_at = now() RETURNING created_at, updated_at").
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	saved := &models.Split{SubscriptionID: split.SubscriptionID, HouseholdID: split.HouseholdID, Rule: split.Rule, Shares: split.Shares}
+	writer := repo.cluster.Writer(ctx)
+	if err := writer.QueryRow(ctx, query, args...).Scan(&saved.CreatedAt, &saved.UpdatedAt); err != nil {
+		if domainErr := violation(err); domainErr != nil {
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to set split: "+err.Error())
+		return nil, err
+	}
+
+	if _, err := writer.Exec(ctx, "DELETE FROM subscription_split_shares WHERE subscription_id = $1", split.SubscriptionID); err != nil {
+		repo.log.ErrorContext(ctx, "failed to set split: "+err.Error())
+		return nil, err
+	}
+
+	insert := repo.builder.
+		Insert("subscription_split_shares").
+		Columns("subscription_id", "household_id", "user_id", "value")
+	for _, share := range split.Shares {
+		insert = insert.Values(split.SubscriptionID, split.HouseholdID, share.UserID, share.Value)
+	}
+	query, args, err = insert.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Exec(ctx, query, args...); err != nil {
+		if domainErr := violation(err); domainErr != nil {
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to set split: "+err.Error())
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (repo *Repository) GetSplit(ctx context.Context, subscriptionID uuid.UUID) (*models.Split, error) {
+	defer repo.metrics.ObserveQuery("GetSplit", time.Now())
+
+	splits, err := repo.querySplits(ctx, squirrel.Eq{"subscription_id": subscriptionID})
+	if err != nil {
+		return nil, err
+	}
+	if len(splits) == 0 {
+		return nil, households.ErrSplitNotFound
+	}
+
+	return splits[0], nil
+}
+
+func (repo *Repository) DeleteSplit(ctx context.Context, subscriptionID uuid.UUID) error {
+	defer repo.metrics.ObserveQuery("DeleteSplit", time.Now())
+
+	tag, err := repo.cluster.Writer(ctx).Exec(ctx, "DELETE FROM subscription_splits WHERE subscription_id = $1", subscriptionID)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to delete split: "+err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return households.ErrSplitNotFound
+	}
+
+	return nil
+}
+
+func (repo *Repository) ListSplits(ctx context.Context, userIDs []uuid.UUID) ([]*models.Split, error) {
+	defer repo.metrics.ObserveQuery("ListSplits", time.Now())
+
+	var where squirrel.Sqlizer = squirrel.Expr("TRUE")
+	if len(userIDs) > 0 {
+		where = squirrel.Expr("household_id IN (SELECT household_id FROM household_members WHERE user_id = ANY(?))", userIDs)
+	}
+
+	return repo.querySplits(ctx, where)
+}
+
+func (repo *Repository) ListHouseholdSplits(ctx context.Context, householdID uuid.UUID) ([]*models.Split, error) {
+	defer repo.metrics.ObserveQuery("ListHouseholdSplits", time.Now())
+
+	return repo.querySplits(ctx, squirrel.Eq{"household_id": householdID})
+}
+
+func (repo *Repository) queryHouseholds(ctx context.Context, builder squirrel.SelectBuilder) ([]*models.Household, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := repo.cluster.Reader(ctx)
+	rows, err := reader.Query(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch households: "+err.Error())
+		return nil, err
+	}
+
+	var (
+		list []*models.Household
+		ids  []uuid.UUID
+	)
+	byID := make(map[uuid.UUID]*models.Household)
+	for rows.Next() {
+		household := &models.Household{Members: []models.HouseholdMember{}}
+		if err := rows.Scan(&household.ID, &household.Name, &household.CreatedAt, &household.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, household)
+		ids = append(ids, household.ID)
+		byID[household.ID] = household
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	query, args, err = repo.builder.
+		Select("household_id", "user_id", "joined_at").
+		From("household_members").
+		Where(squirrel.Eq{"household_id": ids}).
+		OrderBy("joined_at", "user_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := reader.Query(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch household members: "+err.Error())
+		return nil, err
+	}
+	defer members.Close()
+
+	for members.Next() {
+		var (
+			householdID uuid.UUID
+			member      models.HouseholdMember
+		)
+		if err := members.Scan(&householdID, &member.UserID, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		byID[householdID].Members = append(byID[householdID].Members, member)
+	}
+
+	return list, members.Err()
+}
+
+func (repo *Repository) querySplits(ctx context.Context, where squirrel.Sqlizer) ([]*models.Split, error) {
+	query, args, err := repo.builder.
+		Select(splitColumns...).
+		From("subscription_splits").
+		Where(where).
+		OrderBy("subscription_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := repo.cluster.Reader(ctx)
+	rows, err := reader.Query(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch splits: "+err.Error())
+		return nil, err
+	}
+
+	var (
+		splits []*models.Split
+		ids    []uuid.UUID
+	)
+	byID := make(map[uuid.UUID]*models.Split)
+	for rows.Next() {
+		split := &models.Split{Shares: []models.SplitShare{}}
+		var rule string
+		if err := rows.Scan(&split.SubscriptionID, &split.HouseholdID, &rule, &split.CreatedAt, &split.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		split.Rule = models.SplitRule(rule)
+		splits = append(splits, split)
+		ids = append(ids, split.SubscriptionID)
+		byID[split.SubscriptionID] = split
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return splits, nil
+	}
+
+	query, args, err = repo.builder.
+		Select("subscription_id", "user_id", "value").
+		From("subscription_split_shares").
+		Where(squirrel.Eq{"subscription_id": ids}).
+		OrderBy("user_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := reader.Query(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch split shares: "+err.Error())
+		return nil, err
+	}
+	defer shares.Close()
+
+	for shares.Next() {
+		var (
+			subscriptionID uuid.UUID
+			share          models.SplitShare
+		)
+		if err := shares.Scan(&subscriptionID, &share.UserID, &share.Value); err != nil {
+			return nil, err
+		}
+		byID[subscriptionID].Shares = append(byID[subscriptionID].Shares, share)
+	}
+
+	return splits, shares.Err()
+}
+
+// violation переводит нарушения ограничений в доменные ошибки.
+func violation(err error) error {
+	pgErr := &pgconn.PgError{}
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	switch pgErr.Code {
+	case "23505":
+		if pgErr.ConstraintName == "household_members_pkey" {
+			return households.ErrAlreadyMember
+		}
+		return households.ErrAlreadyExists
+	case "23503":
+		switch pgErr.ConstraintName {
+		case "household_members_user_id_fkey":
+			return households.ErrUserNotFound
+		case "household_members_household_id_fkey", "subscription_splits_household_id_fkey":
+			return households.ErrNotFound
+		case "subscription_split_shares_household_id_user_id_fkey":
+			return households.ErrShareNotMember
+		default:
+			return households.ErrInvalidArgument
+		}
+	case "23514":
+		if pgErr.ConstraintName == "subscription_splits_rule_valid" {
+			return households.ErrInvalidRule
+		}
+		return households.ErrNegativeShare
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,428 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/services/households"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"log/slog"
+	sqlite3 "modernc.org/sqlite/lib"
+	"time"
+)
+
+type SQLiteParams struct {
+	fx.In
+
+	Logger  *slog.Logger
+	DB      *sql.DB
+	Metrics *metrics.Metrics
+}
+
+type SQLiteRepository struct {
+	db      *sql.DB
+	log     *slog.Logger
+	builder squirrel.StatementBuilderType
+	metrics *metrics.Metrics
+}
+
+func NewSQLiteRepository(params SQLiteParams) *SQLiteRepository {
+	return &SQLiteRepository{
+		db:      params.DB,
+		log:     params.Logger,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		metrics: params.Metrics,
+	}
+}
+
+func (repo *SQLiteRepository) CreateHousehold(ctx context.Context, household *models.Household) (*models.Household, error) {
+	defer repo.metrics.ObserveQuery("CreateHousehold", time.Now())
+
+	query, args, err := repo.builder.
+		Insert("households").
+		Columns("id", "name").
+		Values(household.ID.String(), household.Name).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	if _, err := db.SQLiteConn(ctx, repo.db).ExecContext(ctx, query, args...); err != nil {
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to create household: "+err.Error())
+		return nil, err
+	}
+
+	for _, member := range household.Members {
+		if err := repo.AddMember(ctx, household.ID, member.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return repo.GetHouseholdByID(ctx, household.ID)
+}
+
+func (repo *SQLiteRepository) GetHouseholdByID(ctx context.Context, id uuid.UUID) (*models.Household, error) {
+	defer repo.metrics.ObserveQuery("GetHouseholdByID", time.Now())
+
+	list, err := repo.queryHouseholds(ctx, repo.builder.
+		Select(householdColumns...).
+		From("households").
+		Where(squirrel.Eq{"id": id.String()}))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, households.ErrNotFound
+	}
+
+	return list[0], nil
+}
+
+func (repo *SQLiteRepository) ListHouseholds(ctx context.Context) ([]*models.Household, error) {
+	defer repo.metrics.ObserveQuery("ListHouseholds", time.Now())
+
+	return repo.queryHouseholds(ctx, repo.builder.
+		Select(householdColumns...).
+		From("households").
+		OrderBy("id"))
+}
+
+func (repo *SQLiteRepository) DeleteHousehold(ctx context.Context, id uuid.UUID) error {
+	defer repo.metrics.ObserveQuery("DeleteHousehold", time.Now())
+
+	return repo.exec(ctx, households.ErrNotFound, "DELETE FROM households WHERE id = ?", id.String())
+}
+
+func (repo *SQLiteRepository) AddMember(ctx context.Context, householdID, userID uuid.UUID) error {
+	defer repo.metrics.ObserveQuery("AddMember", time.Now())
+
+	_, err := db.SQLiteConn(ctx, repo.db).ExecContext(ctx,
+		"INSERT INTO household_members (household_id, user_id) VALUES (?, ?)", householdID.String(), userID.String())
+	if err != nil {
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			return domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to add household member: "+err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (repo *SQLiteRepository) RemoveMember(ctx context.Context, householdID, userID uuid.UUID) error {
+	defer repo.metrics.ObserveQuery("RemoveMember", time.Now())
+
+	return repo.exec(ctx, households.ErrMemberNotFound,
+		"DELETE FROM household_members WHERE household_id = ? AND user_id = ?", householdID.String(), userID.String())
+}
+
+func (repo *SQLiteRepository) SetSplit(ctx context.Context, split *models.Split) (*models.Split, error) {
+	defer repo.metrics.ObserveQuery("SetSplit", time.Now())
+
+	conn := db.SQLiteConn(ctx, repo.db)
+
+	saved := &models.Split{SubscriptionID: split.SubscriptionID, HouseholdID: split.HouseholdID, Rule: split.Rule, Shares: split.Shares}
+	var createdAt, updatedAt string
+	err := conn.QueryRowContext(ctx,
+		"INSERT INTO subscription_splits (subscription_id, household_id, rule) VALUES (?, ?, ?) "+
+			"ON CONFLICT (subscription_id) DO UPDATE SET household_id = excluded.household_id, rule = excluded.rule, updated_at = "+db.SQLiteNow+" "+
+			"RETURNING created_at, updated_at",
+		split.SubscriptionID.String(), split.HouseholdID.String(), string(split.Rule),
+	).Scan(&createdAt, &updatedAt)
+	if err != nil {
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to set split: "+err.Error())
+		return nil, err
+	}
+	if saved.CreatedAt, saved.UpdatedAt, err = parseTimestamps(createdAt, updatedAt); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "DELETE FROM subscription_split_shares WHERE subscription_id = ?", split.SubscriptionID.String()); err != nil {
+		repo.log.ErrorContext(ctx, "failed to set split: "+err.Error())
+		return nil, err
+	}
+
+	insert := repo.builder.
+		Insert("subscription_split_shares").
+		Columns("subscription_id", "household_id", "user_id", "value")
+	for _, share := range split.Shares {
+		insert = insert.Values(split.SubscriptionID.String(), split.HouseholdID.String(), share.UserID.String(), share.Value)
+	}
+	query, args, err := insert.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to set split: "+err.Error())
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (repo *SQLiteRepository) GetSplit(ctx context.Context, subscriptionID uuid.UUID) (*models.Split, error) {
+	defer repo.metrics.ObserveQuery("GetSplit", time.Now())
+
+	splits, err := repo.querySplits(ctx, squirrel.Eq{"subscription_id": subscriptionID.String()})
+	if err != nil {
+		return nil, err
+	}
+	if len(splits) == 0 {
+		return nil, households.ErrSplitNotFound
+	}
+
+	return splits[0], nil
+}
+
+func (repo *SQLiteRepository) DeleteSplit(ctx context.Context, subscriptionID uuid.UUID) error {
+	defer repo.metrics.ObserveQuery("DeleteSplit", time.Now())
+
+	return repo.exec(ctx, households.ErrSplitNotFound,
+		"DELETE FROM subscription_splits WHERE subscription_id = ?", subscriptionID.String())
+}
+
+func (repo *SQLiteRepository) ListSplits(ctx context.Context, userIDs []uuid.UUID) ([]*models.Split, error) {
+	defer repo.metrics.ObserveQuery("ListSplits", time.Now())
+
+	var where squirrel.Sqlizer = squirrel.Expr("1 = 1")
+	if len(userIDs) > 0 {
+		members, args, err := repo.builder.
+			Select("household_id").
+			From("household_members").
+			Where(squirrel.Eq{"user_id": uuidStrings(userIDs)}).
+			ToSql()
+		if err != nil {
+			return nil, err
+		}
+		where = squirrel.Expr("household_id IN ("+members+")", args...)
+	}
+
+	return repo.querySplits(ctx, where)
+}
+
+func (repo *SQLiteRepository) ListHouseholdSplits(ctx context.Context, householdID uuid.UUID) ([]*models.Split, error) {
+	defer repo.metrics.ObserveQuery("ListHouseholdSplits", time.Now())
+
+	return repo.querySplits(ctx, squirrel.Eq{"household_id": householdID.String()})
+}
+
+// exec выполняет удаление и возвращает notFound, если ничего не удалено.
+func (repo *SQLiteRepository) exec(ctx context.Context, notFound error, query string, args ...any) error {
+	result, err := db.SQLiteConn(ctx, repo.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to delete: "+err.Error())
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+func (repo *SQLiteRepository) queryHouseholds(ctx context.Context, builder squirrel.SelectBuilder) ([]*models.Household, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn := db.SQLiteConn(ctx, repo.db)
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch households: "+err.Error())
+		return nil, err
+	}
+
+	var (
+		list []*models.Household
+		ids  []string
+	)
+	byID := make(map[uuid.UUID]*models.Household)
+	for rows.Next() {
+		household := &models.Household{Members: []models.HouseholdMember{}}
+		var createdAt, updatedAt string
+		if err := rows.Scan(&household.ID, &household.Name, &createdAt, &updatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if household.CreatedAt, household.UpdatedAt, err = parseTimestamps(createdAt, updatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, household)
+		ids = append(ids, household.ID.String())
+		byID[household.ID] = household
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	query, args, err = repo.builder.
+		Select("household_id", "user_id", "joined_at").
+		From("household_members").
+		Where(squirrel.Eq{"household_id": ids}).
+		OrderBy("joined_at", "user_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch household members: "+err.Error())
+		return nil, err
+	}
+	defer members.Close()
+
+	for members.Next() {
+		var (
+			householdID uuid.UUID
+			member      models.HouseholdMember
+			joinedAt    string
+		)
+		if err := members.Scan(&householdID, &member.UserID, &joinedAt); err != nil {
+			return nil, err
+		}
+		if member.JoinedAt, err = time.Parse(time.RFC3339Nano, joinedAt); err != nil {
+			return nil, fmt.Errorf("invalid joined_at %q: %w", joinedAt, err)
+		}
+		byID[householdID].Members = append(byID[householdID].Members, member)
+	}
+
+	return list, members.Err()
+}
+
+func (repo *SQLiteRepository) querySplits(ctx context.Context, where squirrel.Sqlizer) ([]*models.Split, error) {
+	query, args, err := repo.builder.
+		Select(splitColumns...).
+		From("subscription_splits").
+		Where(where).
+		OrderBy("subscription_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	conn := db.SQLiteConn(ctx, repo.db)
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch splits: "+err.Error())
+		return nil, err
+	}
+
+	var (
+		splits []*models.Split
+		ids    []string
+	)
+	byID := make(map[uuid.UUID]*models.Split)
+	for rows.Next() {
+		split := &models.Split{Shares: []models.SplitShare{}}
+		var rule, createdAt, updatedAt string
+		if err := rows.Scan(&split.SubscriptionID, &split.HouseholdID, &rule, &createdAt, &updatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if split.CreatedAt, split.UpdatedAt, err = parseTimestamps(createdAt, updatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		split.Rule = models.SplitRule(rule)
+		splits = append(splits, split)
+		ids = append(ids, split.SubscriptionID.String())
+		byID[split.SubscriptionID] = split
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return splits, nil
+	}
+
+	query, args, err = repo.builder.
+		Select("subscription_id", "user_id", "value").
+		From("subscription_split_shares").
+		Where(squirrel.Eq{"subscription_id": ids}).
+		OrderBy("user_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch split shares: "+err.Error())
+		return nil, err
+	}
+	defer shares.Close()
+
+	for shares.Next() {
+		var (
+			subscriptionID uuid.UUID
+			share          models.SplitShare
+		)
+		if err := shares.Scan(&subscriptionID, &share.UserID, &share.Value); err != nil {
+			return nil, err
+		}
+		byID[subscriptionID].Shares = append(byID[subscriptionID].Shares, share)
+	}
+
+	return splits, shares.Err()
+}
+
+func parseTimestamps(createdAt, updatedAt string) (time.Time, time.Time, error) {
+	created, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid created_at %q: %w", createdAt, err)
+	}
+	updated, err := time.Parse(time.RFC3339Nano, updatedAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid updated_at %q: %w", updatedAt, err)
+	}
+	return created, updated, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, id.String())
+	}
+	return strs
+}
+
+// sqliteViolations переводят нарушения ограничений в доменные ошибки.
+// SQLite не называет нарушенный внешний ключ, поэтому UseCase заранее
+// проверяет домохозяйство и подписку, и остается только пользователь.
+var sqliteViolations = db.SQLiteViolations{
+	{Code: sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, Constraint: "household_members", Err: households.ErrAlreadyMember},
+	{Code: sqlite3.SQLITE_CONSTRAINT_UNIQUE, Constraint: "household_members", Err: households.ErrAlreadyMember},
+	{Code: sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, Err: households.ErrAlreadyExists},
+	{Code: sqlite3.SQLITE_CONSTRAINT_UNIQUE, Err: households.ErrAlreadyExists},
+	{Code: sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY, Err: households.ErrUserNotFound},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Constraint: "subscription_splits_rule_valid", Err: households.ErrInvalidRule},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Err: households.ErrNegativeShare},
+}
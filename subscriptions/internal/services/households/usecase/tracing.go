@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ekkserapopova/subscriptions/internal/services/households/usecase"
+
+func recordError(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func householdIDAttr(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("household.id", id.String())
+}
@@ -0,0 +1,406 @@
+package usecase
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/households"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Params struct {
+	fx.In
+
+	Logger         *slog.Logger
+	Repo           households.Repository
+	Subscriptions  subscriptions.Repository
+	Tx             tx.Manager
+	TracerProvider trace.TracerProvider
+}
+
+type UseCase struct {
+	log           *slog.Logger
+	repo          households.Repository
+	subscriptions subscriptions.Repository
+	tx            tx.Manager
+	tracer        trace.Tracer
+}
+
+func NewUseCase(params Params) *UseCase {
+	return &UseCase{
+		log:           params.Logger,
+		repo:          params.Repo,
+		subscriptions: params.Subscriptions,
+		tx:            params.Tx,
+		tracer:        params.TracerProvider.Tracer(tracerName),
+	}
+}
+
+func (u *UseCase) CreateHousehold(ctx context.Context, household *models.Household) (*models.Household, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.CreateHousehold")
+	defer span.End()
+
+	if household.ID == uuid.Nil {
+		household.ID = uuid.New()
+	}
+	household.Name = strings.TrimSpace(household.Name)
+	if household.Name == "" {
+		return nil, households.ErrNameRequired
+	}
+
+	members := make([]models.HouseholdMember, 0, len(household.Members))
+	for _, member := range household.Members {
+		if !slices.ContainsFunc(members, func(m models.HouseholdMember) bool { return m.UserID == member.UserID }) {
+			members = append(members, models.HouseholdMember{UserID: member.UserID})
+		}
+	}
+	household.Members = members
+
+	var created *models.Household
+	err := u.tx.Do(ctx, tx.Options{}, func(ctx context.Context) error {
+		var err error
+		created, err = u.repo.CreateHousehold(ctx, household)
+		return err
+	})
+	if err != nil {
+		u.log.WarnContext(ctx, "create household: "+err.Error())
+		return nil, recordError(span, err)
+	}
+
+	return created, nil
+}
+
+func (u *UseCase) GetHouseholdByID(ctx context.Context, id uuid.UUID) (*models.Household, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetHouseholdByID", trace.WithAttributes(householdIDAttr(id)))
+	defer span.End()
+
+	household, err := u.repo.GetHouseholdByID(ctx, id)
+	return household, recordError(span, err)
+}
+
+func (u *UseCase) ListHouseholds(ctx context.Context) ([]*models.Household, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.ListHouseholds")
+	defer span.End()
+
+	result, err := u.repo.ListHouseholds(ctx)
+	return result, recordError(span, err)
+}
+
+// DeleteHousehold удаляет домохозяйство вместе с правилами разделения:
+// его общие подписки снова целиком оплачивает владелец.
+func (u *UseCase) DeleteHousehold(ctx context.Context, id uuid.UUID) error {
+	ctx, span := u.tracer.Start(ctx, "UseCase.DeleteHousehold", trace.WithAttributes(householdIDAttr(id)))
+	defer span.End()
+
+	return recordError(span, u.repo.DeleteHousehold(ctx, id))
+}
+
+func (u *UseCase) AddMember(ctx context.Context, householdID, userID uuid.UUID) (*models.Household, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.AddMember", trace.WithAttributes(householdIDAttr(householdID)))
+	defer span.End()
+
+	var household *models.Household
+	err := u.tx.Do(ctx, tx.Options{}, func(ctx context.Context) error {
+		// Домохозяйство проверяется заранее: SQLite не сообщает, какой из
+		// внешних ключей нарушен, и ошибка указывала бы на пользователя.
+		if _, err := u.repo.GetHouseholdByID(ctx, householdID); err != nil {
+			return err
+		}
+		if err := u.repo.AddMember(ctx, householdID, userID); err != nil {
+			return err
+		}
+
+		var err error
+		household, err = u.repo.GetHouseholdByID(ctx, householdID)
+		return err
+	})
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	return household, nil
+}
+
+// RemoveMember исключает участника и его доли в общих подписках. Участника,
+// который сам платит за общую подписку, исключить нельзя.
+func (u *UseCase) RemoveMember(ctx context.Context, householdID, userID uuid.UUID) error {
+	ctx, span := u.tracer.Start(ctx, "UseCase.RemoveMember", trace.WithAttributes(householdIDAttr(householdID)))
+	defer span.End()
+
+	err := u.tx.Do(ctx, tx.Options{Isolation: tx.RepeatableRead}, func(ctx context.Context) error {
+		household, err := u.repo.GetHouseholdByID(ctx, householdID)
+		if err != nil {
+			return err
+		}
+		if !household.HasMember(userID) {
+			return households.ErrMemberNotFound
+		}
+
+		splits, err := u.repo.ListHouseholdSplits(ctx, householdID)
+		if err != nil {
+			return err
+		}
+		subs, err := u.splitSubscriptions(ctx, splits)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			if sub.UserID == userID {
+				return households.ErrMemberIsPayer
+			}
+		}
+
+		return u.repo.RemoveMember(ctx, householdID, userID)
+	})
+	return recordError(span, err)
+}
+
+// SetSplit делает подписку общей или заменяет ее правило. Подписка может
+// делиться только в одном домохозяйстве.
+func (u *UseCase) SetSplit(ctx context.Context, split *models.Split) (*models.Split, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.SetSplit", trace.WithAttributes(householdIDAttr(split.HouseholdID)))
+	defer span.End()
+
+	switch split.Rule {
+	case models.SplitEqual, models.SplitPercentage, models.SplitFixed:
+	default:
+		return nil, households.ErrInvalidRule
+	}
+
+	var saved *models.Split
+	err := u.tx.Do(ctx, tx.Options{Isolation: tx.RepeatableRead}, func(ctx context.Context) error {
+		household, err := u.repo.GetHouseholdByID(ctx, split.HouseholdID)
+		if err != nil {
+			return err
+		}
+
+		sub, err := u.subscriptions.GetSubscriptionByID(ctx, split.SubscriptionID)
+		if err != nil {
+			return err
+		}
+
+		if err := normalizeShares(split, household, sub); err != nil {
+			return err
+		}
+
+		saved, err = u.repo.SetSplit(ctx, split)
+		return err
+	})
+	if err != nil {
+		u.log.WarnContext(ctx, "set split: "+err.Error())
+		return nil, recordError(span, err)
+	}
+
+	return saved, nil
+}
+
+func (u *UseCase) GetSplit(ctx context.Context, subscriptionID uuid.UUID) (*models.Split, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetSplit")
+	defer span.End()
+
+	split, err := u.repo.GetSplit(ctx, subscriptionID)
+	return split, recordError(span, err)
+}
+
+func (u *UseCase) DeleteSplit(ctx context.Context, subscriptionID uuid.UUID) error {
+	ctx, span := u.tracer.Start(ctx, "UseCase.DeleteSplit")
+	defer span.End()
+
+	return recordError(span, u.repo.DeleteSplit(ctx, subscriptionID))
+}
+
+// GetSettlement сводит общие подписки домохозяйства, действующие в month:
+// плательщик вносит полную цену, каждый участник должен ему свою долю.
+// Переводы подбираются так, чтобы долги закрывались наименьшим их числом
+// в простых случаях: крупнейший должник платит крупнейшему кредитору.
+func (u *UseCase) GetSettlement(ctx context.Context, householdID uuid.UUID, month time.Time) (*models.Settlement, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetSettlement", trace.WithAttributes(householdIDAttr(householdID)))
+	defer span.End()
+
+	household, err := u.repo.GetHouseholdByID(ctx, householdID)
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	splits, err := u.repo.ListHouseholdSplits(ctx, householdID)
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	balances := make(map[uuid.UUID]*models.MemberBalance, len(household.Members))
+	balance := func(userID uuid.UUID) *models.MemberBalance {
+		if _, ok := balances[userID]; !ok {
+			balances[userID] = &models.MemberBalance{UserID: userID}
+		}
+		return balances[userID]
+	}
+	for _, member := range household.Members {
+		balance(member.UserID)
+	}
+
+	subs, err := u.splitSubscriptions(ctx, splits)
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	for _, split := range splits {
+		sub, ok := subs[split.SubscriptionID]
+		if !ok || !sub.ActiveIn(month) {
+			continue
+		}
+
+		price := sub.ChargeIn(month)
+		balance(sub.UserID).Paid += price
+		for userID, amount := range split.Amounts(price, sub.UserID) {
+			balance(userID).Share += amount
+		}
+	}
+
+	settlement := &models.Settlement{
+		HouseholdID: householdID,
+		Month:       models.MonthYear(time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)),
+		Balances:    make([]models.MemberBalance, 0, len(balances)),
+	}
+	for _, b := range balances {
+		b.Balance = b.Paid - b.Share
+		settlement.Balances = append(settlement.Balances, *b)
+	}
+	slices.SortFunc(settlement.Balances, func(a, b models.MemberBalance) int {
+		return bytes.Compare(a.UserID[:], b.UserID[:])
+	})
+	settlement.Transfers = transfers(settlement.Balances)
+
+	return settlement, nil
+}
+
+// normalizeShares проверяет правило и приводит его к хранимому виду: доля
+// плательщика не хранится, equal без участников делится на всех членов
+// домохозяйства.
+func normalizeShares(split *models.Split, household *models.Household, sub *models.Subscription) error {
+	payer := sub.UserID
+	if !household.HasMember(payer) {
+		return households.ErrPayerNotMember
+	}
+
+	if split.Rule == models.SplitEqual && len(split.Shares) == 0 {
+		for _, member := range household.Members {
+			split.Shares = append(split.Shares, models.SplitShare{UserID: member.UserID})
+		}
+	}
+
+	shares := make([]models.SplitShare, 0, len(split.Shares))
+	seen := make(map[uuid.UUID]bool, len(split.Shares))
+	total := 0
+	for _, share := range split.Shares {
+		if seen[share.UserID] {
+			return households.ErrDuplicateShare
+		}
+		seen[share.UserID] = true
+
+		if !household.HasMember(share.UserID) {
+			return households.ErrShareNotMember
+		}
+		if share.Value < 0 {
+			return households.ErrNegativeShare
+		}
+		if split.Rule == models.SplitEqual && share.Value != 0 {
+			return households.ErrUnexpectedValue
+		}
+		if share.UserID == payer {
+			// В equal плательщика можно перечислить среди участников.
+			if split.Rule != models.SplitEqual {
+				return households.ErrPayerShare
+			}
+			continue
+		}
+
+		total += share.Value
+		shares = append(shares, share)
+	}
+
+	if len(shares) == 0 {
+		return households.ErrNoParticipants
+	}
+	if split.Rule == models.SplitPercentage && total > 100 {
+		return households.ErrPercentageTotal
+	}
+	if split.Rule == models.SplitFixed && total > *sub.Price {
+		return households.ErrFixedTotal
+	}
+
+	split.Shares = shares
+	return nil
+}
+
+// splitSubscriptions загружает общие подписки одним запросом. Удаленных
+// подписок в результате нет.
+func (u *UseCase) splitSubscriptions(ctx context.Context, splits []*models.Split) (map[uuid.UUID]*models.Subscription, error) {
+	if len(splits) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(splits))
+	for _, split := range splits {
+		ids = append(ids, split.SubscriptionID)
+	}
+
+	subs, err := u.subscriptions.ListSubscriptions(ctx, models.SubscriptionFilter{IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID]*models.Subscription, len(subs))
+	for _, sub := range subs {
+		result[sub.ID] = sub
+	}
+	return result, nil
+}
+
+// transfers гасит отрицательные балансы за счет положительных.
+func transfers(balances []models.MemberBalance) []models.Transfer {
+	var debtors, creditors []models.MemberBalance
+	for _, b := range balances {
+		switch {
+		case b.Balance < 0:
+			b.Balance = -b.Balance
+			debtors = append(debtors, b)
+		case b.Balance > 0:
+			creditors = append(creditors, b)
+		}
+	}
+
+	byAmount := func(a, b models.MemberBalance) int {
+		if c := cmp.Compare(b.Balance, a.Balance); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.UserID[:], b.UserID[:])
+	}
+	slices.SortFunc(debtors, byAmount)
+	slices.SortFunc(creditors, byAmount)
+
+	result := []models.Transfer{}
+	for i, j := 0, 0; i < len(debtors) && j < len(creditors); {
+		amount := min(debtors[i].Balance, creditors[j].Balance)
+		result = append(result, models.Transfer{From: debtors[i].UserID, To: creditors[j].UserID, Amount: amount})
+
+		debtors[i].Balance -= amount
+		creditors[j].Balance -= amount
+		if debtors[i].Balance == 0 {
+			i++
+		}
+		if creditors[j].Balance == 0 {
+			j++
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/households"
+	householdRepo "github.com/ekkserapopova/subscriptions/internal/services/households/repo"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	subscriptionRepo "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/repo"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace/noop"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+// user возвращает ID с заданным последним байтом: порядок ID в тестах
+// совпадает с порядком n.
+func user(n byte) uuid.UUID {
+	var id uuid.UUID
+	id[15] = n
+	return id
+}
+
+// countingSubscriptions считает чтения подписок.
+type countingSubscriptions struct {
+	subscriptions.Repository
+
+	gets, lists int
+}
+
+func (r *countingSubscriptions) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	r.gets++
+	return r.Repository.GetSubscriptionByID(ctx, id)
+}
+
+func (r *countingSubscriptions) ListSubscriptions(ctx context.Context, filter models.SubscriptionFilter) ([]*models.Subscription, error) {
+	r.lists++
+	return r.Repository.ListSubscriptions(ctx, filter)
+}
+
+func TestGetSettlement(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	subs := subscriptionRepo.NewMemoryRepository(subscriptionRepo.MemoryParams{Logger: log})
+	counting := &countingSubscriptions{Repository: subs}
+	u := NewUseCase(Params{
+		Logger:         log,
+		Repo:           householdRepo.NewMemoryRepository(householdRepo.MemoryParams{Logger: log}),
+		Subscriptions:  counting,
+		Tx:             subs,
+		TracerProvider: noop.NewTracerProvider(),
+	})
+
+	a, b, c := user(1), user(2), user(3)
+	household, err := u.CreateHousehold(ctx, &models.Household{
+		Name:    "Дом",
+		Members: []models.HouseholdMember{{UserID: a}, {UserID: b}, {UserID: c}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	share := func(payer uuid.UUID, price int, end *models.MonthYear, rule models.SplitRule, shares ...models.SplitShare) {
+		t.Helper()
+		sub, err := subs.CreateSubscription(ctx, &models.Subscription{
+			ID:          uuid.New(),
+			ServiceName: "Yandex Plus",
+			Price:       &price,
+			UserID:      payer,
+			StartDate:   models.MonthYear(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+			EndDate:     end,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := u.SetSplit(ctx, &models.Split{SubscriptionID: sub.ID, HouseholdID: household.ID, Rule: rule, Shares: shares}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ended := models.MonthYear(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	share(a, 900, nil, models.SplitEqual)
+	share(b, 1000, nil, models.SplitPercentage, models.SplitShare{UserID: c, Value: 50})
+	share(c, 600, nil, models.SplitFixed, models.SplitShare{UserID: a, Value: 100})
+	share(a, 5000, &ended, models.SplitEqual)
+
+	counting.gets, counting.lists = 0, 0
+	settlement, err := u.GetSettlement(ctx, household.ID, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if counting.gets != 0 || counting.lists != 1 {
+		t.Errorf("subscription reads: %d by id, %d lists, want a single list", counting.gets, counting.lists)
+	}
+
+	wantBalances := []models.MemberBalance{
+		{UserID: a, Paid: 900, Share: 400, Balance: 500},
+		{UserID: b, Paid: 1000, Share: 800, Balance: 200},
+		{UserID: c, Paid: 600, Share: 1300, Balance: -700},
+	}
+	if !slices.Equal(settlement.Balances, wantBalances) {
+		t.Errorf("balances = %+v, want %+v", settlement.Balances, wantBalances)
+	}
+
+	wantTransfers := []models.Transfer{{From: c, To: a, Amount: 500}, {From: c, To: b, Amount: 200}}
+	if !slices.Equal(settlement.Transfers, wantTransfers) {
+		t.Errorf("transfers = %+v, want %+v", settlement.Transfers, wantTransfers)
+	}
+}
+
+func TestNormalizeShares(t *testing.T) {
+	payer, a, b, outsider := user(1), user(2), user(3), user(9)
+	household := &models.Household{Members: []models.HouseholdMember{{UserID: payer}, {UserID: a}, {UserID: b}}}
+
+	tests := []struct {
+		name   string
+		rule   models.SplitRule
+		payer  uuid.UUID
+		shares []models.SplitShare
+		want   []models.SplitShare
+		err    error
+	}{
+		{name: "equal без участников делится на всех", rule: models.SplitEqual, want: []models.SplitShare{{UserID: a}, {UserID: b}}},
+		{name: "плательщик в equal пропускается", rule: models.SplitEqual, shares: []models.SplitShare{{UserID: payer}, {UserID: a}}, want: []models.SplitShare{{UserID: a}}},
+		{name: "equal со значением", rule: models.SplitEqual, shares: []models.SplitShare{{UserID: a, Value: 10}}, err: households.ErrUnexpectedValue},
+		{name: "ровно сто процентов", rule: models.SplitPercentage, shares: []models.SplitShare{{UserID: a, Value: 60}, {UserID: b, Value: 40}}, want: []models.SplitShare{{UserID: a, Value: 60}, {UserID: b, Value: 40}}},
+		{name: "больше ста процентов", rule: models.SplitPercentage, shares: []models.SplitShare{{UserID: a, Value: 60}, {UserID: b, Value: 41}}, err: households.ErrPercentageTotal},
+		{name: "доля плательщика в percentage", rule: models.SplitPercentage, shares: []models.SplitShare{{UserID: payer, Value: 10}}, err: households.ErrPayerShare},
+		{name: "фиксированные доли в пределах цены", rule: models.SplitFixed, shares: []models.SplitShare{{UserID: a, Value: 1000}}, want: []models.SplitShare{{UserID: a, Value: 1000}}},
+		{name: "фиксированные доли больше цены", rule: models.SplitFixed, shares: []models.SplitShare{{UserID: a, Value: 600}, {UserID: b, Value: 401}}, err: households.ErrFixedTotal},
+		{name: "отрицательная доля", rule: models.SplitFixed, shares: []models.SplitShare{{UserID: a, Value: -1}}, err: households.ErrNegativeShare},
+		{name: "повтор участника", rule: models.SplitEqual, shares: []models.SplitShare{{UserID: a}, {UserID: a}}, err: households.ErrDuplicateShare},
+		{name: "участник не из домохозяйства", rule: models.SplitEqual, shares: []models.SplitShare{{UserID: outsider}}, err: households.ErrShareNotMember},
+		{name: "только плательщик", rule: models.SplitEqual, shares: []models.SplitShare{{UserID: payer}}, err: households.ErrNoParticipants},
+		{name: "плательщик не из домохозяйства", rule: models.SplitEqual, payer: outsider, err: households.ErrPayerNotMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := 1000
+			sub := &models.Subscription{UserID: payer, Price: &price}
+			if tt.payer != uuid.Nil {
+				sub.UserID = tt.payer
+			}
+			split := &models.Split{Rule: tt.rule, Shares: tt.shares}
+
+			err := normalizeShares(split, household, sub)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if err == nil && !slices.Equal(split.Shares, tt.want) {
+				t.Errorf("shares = %+v, want %+v", split.Shares, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransfers(t *testing.T) {
+	a, b, c, d := user(1), user(2), user(3), user(4)
+
+	tests := []struct {
+		name     string
+		balances map[uuid.UUID]int
+		want     []models.Transfer
+	}{
+		{name: "долгов нет", balances: map[uuid.UUID]int{a: 0, b: 0}, want: []models.Transfer{}},
+		{name: "один должник", balances: map[uuid.UUID]int{a: 300, b: -300}, want: []models.Transfer{{From: b, To: a, Amount: 300}}},
+		{
+			name:     "крупнейший кредитор получает первым",
+			balances: map[uuid.UUID]int{a: 200, b: 500, c: -700},
+			want:     []models.Transfer{{From: c, To: b, Amount: 500}, {From: c, To: a, Amount: 200}},
+		},
+		{
+			name:     "крупнейший должник платит первым",
+			balances: map[uuid.UUID]int{a: -200, b: -500, c: 700},
+			want:     []models.Transfer{{From: b, To: c, Amount: 500}, {From: a, To: c, Amount: 200}},
+		},
+		{
+			name:     "равные долги закрываются одним переводом",
+			balances: map[uuid.UUID]int{a: 300, b: 100, c: -300, d: -100},
+			want:     []models.Transfer{{From: c, To: a, Amount: 300}, {From: d, To: b, Amount: 100}},
+		},
+		{
+			name:     "равные суммы упорядочены по ID",
+			balances: map[uuid.UUID]int{a: 100, b: 100, c: -100, d: -100},
+			want:     []models.Transfer{{From: c, To: a, Amount: 100}, {From: d, To: b, Amount: 100}},
+		},
+		{
+			name:     "долг делится между кредиторами",
+			balances: map[uuid.UUID]int{a: 250, b: 150, c: -100, d: -300},
+			want: []models.Transfer{
+				{From: d, To: a, Amount: 250},
+				{From: d, To: b, Amount: 50},
+				{From: c, To: b, Amount: 100},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var balances []models.MemberBalance
+			for _, id := range []uuid.UUID{a, b, c, d} {
+				if balance, ok := tt.balances[id]; ok {
+					balances = append(balances, models.MemberBalance{UserID: id, Balance: balance})
+				}
+			}
+
+			if got := transfers(balances); !slices.Equal(got, tt.want) {
+				t.Errorf("transfers = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package graphql
+
+type Config struct {
+	MaxDepth      int  `yaml:"maxDepth" env-default:"8"`
+	MaxComplexity int  `yaml:"maxComplexity" env-default:"1000"`
+	Playground    bool `yaml:"playground"`
+}
@@ -0,0 +1,66 @@
+package graphql
+
+import (
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/graphql/generated"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.uber.org/fx"
+	"log/slog"
+	"net/http"
+)
+
+type Params struct {
+	fx.In
+
+	Config  Config
+	Logger  *slog.Logger
+	UseCase subscriptions.UseCase
+}
+
+type Handler struct {
+	query      http.Handler
+	playground http.Handler
+}
+
+func NewHandler(params Params) *Handler {
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{
+		Resolvers: &Resolver{
+			logger:  params.Logger,
+			usecase: params.UseCase,
+		},
+		Complexity: complexity(),
+	}))
+
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+	srv.Use(extension.Introspection{})
+	srv.Use(depthLimit{limit: params.Config.MaxDepth})
+	srv.Use(extension.FixedComplexityLimit(params.Config.MaxComplexity))
+
+	h := &Handler{
+		query: withLoaders(params.UseCase, srv),
+	}
+	if params.Config.Playground {
+		h.playground = playground.Handler("Subscriptions GraphQL", "/api/v1/graphql")
+	}
+
+	return h
+}
+
+func (h *Handler) Query(w http.ResponseWriter, r *http.Request) {
+	h.query.ServeHTTP(w, r)
+}
+
+func (h *Handler) Playground(w http.ResponseWriter, r *http.Request) {
+	if h.playground == nil {
+		http.NotFound(w, r)
+		return
+	}
+	h.playground.ServeHTTP(w, r)
+}
@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/graphql/generated"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/graphql/model"
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// depthLimit отклоняет запросы с вложенностью полей больше limit.
+type depthLimit struct {
+	limit int
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = depthLimit{}
+
+func (d depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (d depthLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d depthLimit) MutateOperationContext(_ context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if rc.Operation == nil {
+		return nil
+	}
+
+	depth, err := selectionDepth(rc.Operation.SelectionSet, 0)
+	if err != nil {
+		return gqlerror.Errorf("%s", err.Error())
+	}
+	if depth > d.limit {
+		return gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.limit)
+	}
+
+	return nil
+}
+
+func selectionDepth(set ast.SelectionSet, depth int) (int, error) {
+	maxDepth := depth
+	for _, selection := range set {
+		childDepth := depth
+		var err error
+		switch s := selection.(type) {
+		case *ast.Field:
+			childDepth, err = selectionDepth(s.SelectionSet, depth+1)
+		case *ast.InlineFragment:
+			childDepth, err = selectionDepth(s.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				childDepth, err = selectionDepth(s.Definition.SelectionSet, depth)
+			}
+		default:
+			err = fmt.Errorf("unexpected selection %T", selection)
+		}
+		if err != nil {
+			return 0, err
+		}
+		maxDepth = max(maxDepth, childDepth)
+	}
+	return maxDepth, nil
+}
+
+// complexity учитывает, что списочные поля возвращают до first элементов.
+func complexity() generated.ComplexityRoot {
+	var c generated.ComplexityRoot
+
+	c.Query.Subscriptions = func(childComplexity int, first *int, _ *string, _ *model.SubscriptionFilter) int {
+		return 1 + childComplexity*pageLimit(first)
+	}
+	c.Query.Users = func(childComplexity int, ids []uuid.UUID) int {
+		return 1 + childComplexity*len(ids)
+	}
+	c.User.Subscriptions = func(childComplexity int, first *int, _ *string) int {
+		return 1 + childComplexity*pageLimit(first)
+	}
+
+	return c
+}
+
+func pageLimit(first *int) int {
+	size, err := pageSize(first)
+	if err != nil {
+		return maxPageSize
+	}
+	return max(size, 1)
+}
@@ -0,0 +1,38 @@
+package graphql
+
+import (
+	"context"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"testing"
+)
+
+func TestDepthLimit(t *testing.T) {
+	field := func(children ...ast.Selection) *ast.Field {
+		return &ast.Field{SelectionSet: children}
+	}
+
+	tests := []struct {
+		name    string
+		set     ast.SelectionSet
+		wantErr bool
+	}{
+		{name: "в пределах лимита", set: ast.SelectionSet{field(field())}},
+		{name: "фрагменты не добавляют уровень", set: ast.SelectionSet{
+			&ast.InlineFragment{SelectionSet: ast.SelectionSet{field(field())}},
+			&ast.FragmentSpread{Definition: &ast.FragmentDefinition{SelectionSet: ast.SelectionSet{field()}}},
+		}},
+		{name: "глубже лимита", set: ast.SelectionSet{field(field(field()))}, wantErr: true},
+		{name: "неизвестный элемент", set: ast.SelectionSet{field(nil)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &graphql.OperationContext{Operation: &ast.OperationDefinition{SelectionSet: tt.set}}
+			err := depthLimit{limit: 2}.MutateOperationContext(context.Background(), rc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MutateOperationContext() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
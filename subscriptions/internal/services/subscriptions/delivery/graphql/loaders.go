@@ -0,0 +1,57 @@
+package graphql
+
+import (
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"github.com/vikstrous/dataloadgen"
+	"net/http"
+	"time"
+)
+
+const loaderWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+// loaders создаются на каждый запрос, чтобы подписки всех пользователей из
+// запроса загружались одним обращением к репозиторию.
+type loaders struct {
+	subscriptionsByUser *dataloadgen.Loader[uuid.UUID, []*models.Subscription]
+}
+
+func newLoaders(uc subscriptions.UseCase) *loaders {
+	return &loaders{
+		subscriptionsByUser: dataloadgen.NewLoader(
+			func(ctx context.Context, userIDs []uuid.UUID) ([][]*models.Subscription, []error) {
+				subs, err := uc.ListSubscriptions(ctx, models.SubscriptionFilter{UserIDs: userIDs})
+				if err != nil {
+					return nil, []error{err}
+				}
+
+				byUser := make(map[uuid.UUID][]*models.Subscription, len(userIDs))
+				for _, sub := range subs {
+					byUser[sub.UserID] = append(byUser[sub.UserID], sub)
+				}
+
+				result := make([][]*models.Subscription, len(userIDs))
+				for i, userID := range userIDs {
+					result[i] = byUser[userID]
+				}
+				return result, nil
+			},
+			dataloadgen.WithWait(loaderWait),
+		),
+	}
+}
+
+func withLoaders(uc subscriptions.UseCase, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), loadersKey{}, newLoaders(uc))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,44 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/google/uuid"
+)
+
+type PageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor,omitempty"`
+}
+
+type Query struct {
+}
+
+type ServiceTotal struct {
+	ServiceName string `json:"serviceName"`
+	Total       int    `json:"total"`
+	Count       int    `json:"count"`
+}
+
+type SubscriptionConnection struct {
+	Edges    []*SubscriptionEdge `json:"edges"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+}
+
+type SubscriptionEdge struct {
+	Cursor string               `json:"cursor"`
+	Node   *models.Subscription `json:"node"`
+}
+
+type SubscriptionFilter struct {
+	UserIds     []uuid.UUID `json:"userIds,omitempty"`
+	ServiceName *string     `json:"serviceName,omitempty"`
+}
+
+type SumFilter struct {
+	StartDate   *models.MonthYear `json:"startDate,omitempty"`
+	EndDate     *models.MonthYear `json:"endDate,omitempty"`
+	ServiceName *string           `json:"serviceName,omitempty"`
+	UserIds     []uuid.UUID       `json:"userIds,omitempty"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"errors"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/google/uuid"
+	"io"
+	"strconv"
+	"time"
+)
+
+type User struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func MarshalMonthYear(m models.MonthYear) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Quote(m.Time().Format("01-2006")))
+	})
+}
+
+func UnmarshalMonthYear(v any) (models.MonthYear, error) {
+	s, ok := v.(string)
+	if !ok {
+		return models.MonthYear{}, errors.New("MonthYear must be a string in MM-YYYY format")
+	}
+
+	t, err := time.Parse("01-2006", s)
+	if err != nil {
+		return models.MonthYear{}, errors.New("MonthYear must be in MM-YYYY format")
+	}
+
+	return models.MonthYear(t), nil
+}
@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/graphql/model"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+func pageSize(first *int) (int, error) {
+	if first == nil {
+		return defaultPageSize, nil
+	}
+	if *first < 0 || *first > maxPageSize {
+		return 0, errors.New("first must be between 0 and 100")
+	}
+	return *first, nil
+}
+
+func encodeCursor(id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+func decodeCursor(after *string) (uuid.UUID, error) {
+	if after == nil || *after == "" {
+		return uuid.Nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(*after)
+	if err != nil {
+		return uuid.Nil, errInvalidCursor
+	}
+
+	id, err := uuid.FromBytes(raw)
+	if err != nil {
+		return uuid.Nil, errInvalidCursor
+	}
+	return id, nil
+}
+
+// newConnection строит страницу из подписок, отсортированных по ID. В subs
+// может быть на одну запись больше limit — по ней определяется hasNextPage.
+func newConnection(subs []*models.Subscription, limit int) *model.SubscriptionConnection {
+	hasNextPage := len(subs) > limit
+	if hasNextPage {
+		subs = subs[:limit]
+	}
+
+	conn := &model.SubscriptionConnection{
+		Edges:    make([]*model.SubscriptionEdge, 0, len(subs)),
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage},
+	}
+
+	for _, sub := range subs {
+		conn.Edges = append(conn.Edges, &model.SubscriptionEdge{
+			Cursor: encodeCursor(sub.ID),
+			Node:   sub,
+		})
+	}
+
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.EndCursor = &conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn
+}
+
+// paginate выбирает страницу из уже загруженного списка, отсортированного по ID.
+func paginate(subs []*models.Subscription, afterID uuid.UUID, limit int) *model.SubscriptionConnection {
+	start := 0
+	if afterID != uuid.Nil {
+		for start < len(subs) && uuidLessOrEqual(subs[start].ID, afterID) {
+			start++
+		}
+	}
+
+	end := min(start+limit+1, len(subs))
+	return newConnection(subs[start:end], limit)
+}
+
+func uuidLessOrEqual(a, b uuid.UUID) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return true
+}
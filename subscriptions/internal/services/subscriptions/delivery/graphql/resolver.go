@@ -0,0 +1,13 @@
+package graphql
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+import (
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"log/slog"
+)
+
+type Resolver struct {
+	logger  *slog.Logger
+	usecase subscriptions.UseCase
+}
@@ -0,0 +1,193 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/graphql/generated"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/delivery/graphql/model"
+	"github.com/google/uuid"
+)
+
+// Subscription is the resolver for the subscription field.
+func (r *queryResolver) Subscription(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	sub, err := r.usecase.GetSubscriptionByID(ctx, id)
+	if errors.Is(err, subscriptions.ErrNotFound) {
+		return nil, nil
+	}
+	return sub, err
+}
+
+// Subscriptions is the resolver for the subscriptions field.
+func (r *queryResolver) Subscriptions(ctx context.Context, first *int, after *string, filter *model.SubscriptionFilter) (*model.SubscriptionConnection, error) {
+	limit, err := pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+
+	afterID, err := decodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionFilter := models.SubscriptionFilter{
+		AfterID: afterID,
+		Limit:   uint64(limit) + 1,
+	}
+	if filter != nil {
+		subscriptionFilter.UserIDs = filter.UserIds
+		if filter.ServiceName != nil {
+			subscriptionFilter.ServiceName = *filter.ServiceName
+		}
+	}
+
+	subs, err := r.usecase.ListSubscriptions(ctx, subscriptionFilter)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "graphql list subscriptions err: "+err.Error())
+		return nil, err
+	}
+
+	return newConnection(subs, limit), nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	return &model.User{ID: id}, nil
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, ids []uuid.UUID) ([]*model.User, error) {
+	users := make([]*model.User, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, &model.User{ID: id})
+	}
+	return users, nil
+}
+
+// Total is the resolver for the total field.
+func (r *queryResolver) Total(ctx context.Context, filter *model.SumFilter) (int, error) {
+	var startDate, endDate, serviceName, usersIds string
+
+	if filter != nil {
+		if filter.StartDate != nil {
+			startDate = filter.StartDate.Time().Format("01-2006")
+		}
+		if filter.EndDate != nil {
+			endDate = filter.EndDate.Time().Format("01-2006")
+		}
+		if filter.ServiceName != nil {
+			serviceName = *filter.ServiceName
+		}
+
+		ids := make([]string, 0, len(filter.UserIds))
+		for _, id := range filter.UserIds {
+			ids = append(ids, id.String())
+		}
+		usersIds = strings.Join(ids, ",")
+	}
+
+	sum, err := r.usecase.GetSumSubscriptions(ctx, models.CostFilter{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		ServiceName: serviceName,
+		UsersIDs:    usersIds,
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "graphql get sum subscriptions err: "+err.Error())
+		return 0, err
+	}
+
+	return sum, nil
+}
+
+// Subscriptions is the resolver for the subscriptions field.
+func (r *userResolver) Subscriptions(ctx context.Context, obj *model.User, first *int, after *string) (*model.SubscriptionConnection, error) {
+	limit, err := pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+
+	afterID, err := decodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+
+	subs, err := loadersFromContext(ctx).subscriptionsByUser.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(subs, afterID, limit), nil
+}
+
+// MonthlyTotal is the resolver for the monthlyTotal field.
+func (r *userResolver) MonthlyTotal(ctx context.Context, obj *model.User, month models.MonthYear) (int, error) {
+	subs, err := loadersFromContext(ctx).subscriptionsByUser.Load(ctx, obj.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, sub := range subs {
+		if sub.Price != nil && sub.ActiveIn(month.Time()) {
+			total += sub.ChargeIn(month.Time())
+		}
+	}
+
+	return total, nil
+}
+
+// Services is the resolver for the services field.
+func (r *userResolver) Services(ctx context.Context, obj *model.User, month *models.MonthYear) ([]*model.ServiceTotal, error) {
+	subs, err := loadersFromContext(ctx).subscriptionsByUser.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byService := make(map[string]*model.ServiceTotal)
+	for _, sub := range subs {
+		if month != nil && !sub.ActiveIn(month.Time()) {
+			continue
+		}
+
+		total, ok := byService[sub.ServiceName]
+		if !ok {
+			total = &model.ServiceTotal{ServiceName: sub.ServiceName}
+			byService[sub.ServiceName] = total
+		}
+		total.Count++
+		if month != nil {
+			total.Total += sub.ChargeIn(month.Time())
+		} else {
+			// Как в сумме usecase и хранилища: пробный и промо-период, история цен.
+			total.Total += sub.ReportPrice()
+		}
+	}
+
+	services := make([]*model.ServiceTotal, 0, len(byService))
+	for _, total := range byService {
+		services = append(services, total)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].ServiceName < services[j].ServiceName
+	})
+
+	return services, nil
+}
+
+// Query returns generated.QueryResolver implementation.
+func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
+
+// User returns generated.UserResolver implementation.
+func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
+
+type queryResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
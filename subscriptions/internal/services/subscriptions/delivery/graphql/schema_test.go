@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// staticUseCase отдает заданные подписки.
+type staticUseCase struct {
+	subscriptions.UseCase
+
+	subs []*models.Subscription
+}
+
+func (u staticUseCase) GetSubscriptionByID(_ context.Context, id uuid.UUID) (*models.Subscription, error) {
+	for _, sub := range u.subs {
+		if sub.ID == id {
+			return sub, nil
+		}
+	}
+	return nil, subscriptions.ErrNotFound
+}
+
+func (u staticUseCase) ListSubscriptions(context.Context, models.SubscriptionFilter) ([]*models.Subscription, error) {
+	return u.subs, nil
+}
+
+func month(year int, m time.Month) *models.MonthYear {
+	month := models.MonthYear(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC))
+	return &month
+}
+
+func query(t *testing.T, h *Handler, q string) string {
+	t.Helper()
+	body, err := json.Marshal(map[string]string{"query": q})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/graphql", strings.NewReader(string(body)))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.Query(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+	return w.Body.String()
+}
+
+func TestSchema(t *testing.T) {
+	userID := uuid.New()
+	price := func(v int) *int { return &v }
+
+	promo := &models.Subscription{
+		ID:          uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		ServiceName: "Yandex Plus",
+		Category:    "кино",
+		Tags:        []string{"семья"},
+		Price:       price(399),
+		Prices:      []models.PricePeriod{{EffectiveFrom: *month(2025, 1), Price: 399}},
+		UserID:      userID,
+		StartDate:   *month(2025, 1),
+		EndDate:     month(2025, 2),
+		Promo:       &models.Promo{Type: models.PromoPercentage, Value: 50, Months: 3},
+	}
+	trial := &models.Subscription{
+		ID:          uuid.New(),
+		ServiceName: "Netflix",
+		Price:       price(999),
+		UserID:      userID,
+		StartDate:   *month(2025, 1),
+		EndDate:     month(2025, 3),
+		TrialEnd:    month(2025, 3),
+	}
+	plain := &models.Subscription{
+		ID:          uuid.New(),
+		ServiceName: "Netflix",
+		Price:       price(500),
+		UserID:      userID,
+		StartDate:   *month(2025, 1),
+	}
+
+	h := NewHandler(Params{
+		Config:  Config{MaxDepth: 8, MaxComplexity: 1000},
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		UseCase: staticUseCase{subs: []*models.Subscription{promo, trial, plain}},
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "поля подписки",
+			query: `{ subscription(id: "` + promo.ID.String() + `") { category tags trialEnd promo { type value months } prices { effectiveFrom price } } }`,
+			want:  `{"data":{"subscription":{"category":"кино","tags":["семья"],"trialEnd":null,"promo":{"type":"percentage","value":50,"months":3},"prices":[{"effectiveFrom":"01-2025","price":399}]}}}`,
+		},
+		{
+			name:  "подписка без тегов и истории цен",
+			query: `{ subscription(id: "` + plain.ID.String() + `") { tags prices { price } promo { type } } }`,
+			want:  `{"data":{"subscription":{"tags":[],"prices":[],"promo":null}}}`,
+		},
+		{
+			name:  "сервисы без месяца по правилам суммы",
+			query: `{ user(id: "` + userID.String() + `") { services { serviceName total count } } }`,
+			want:  `{"data":{"user":{"services":[{"serviceName":"Netflix","total":500,"count":2},{"serviceName":"Yandex Plus","total":199,"count":1}]}}}`,
+		},
+		{
+			name:  "сервисы за месяц",
+			query: `{ user(id: "` + userID.String() + `") { services(month: "04-2025") { serviceName total count } } }`,
+			want:  `{"data":{"user":{"services":[{"serviceName":"Netflix","total":500,"count":1}]}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := query(t, h, tt.query); got != tt.want {
+				t.Errorf("response = %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
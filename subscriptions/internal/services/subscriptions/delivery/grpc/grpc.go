@@ -0,0 +1,424 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	subscriptionsv1 "github.com/ekkserapopova/subscriptions/api/subscriptions/v1"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type Params struct {
+	fx.In
+
+	Logger  *slog.Logger
+	UseCase subscriptions.UseCase
+}
+
+type Handler struct {
+	subscriptionsv1.UnimplementedSubscriptionServiceServer
+
+	logger  *slog.Logger
+	usecase subscriptions.UseCase
+}
+
+func NewHandler(params Params) *Handler {
+	return &Handler{
+		logger:  params.Logger,
+		usecase: params.UseCase,
+	}
+}
+
+func (h *Handler) CreateSubscription(ctx context.Context, req *subscriptionsv1.CreateSubscriptionRequest) (*subscriptionsv1.Subscription, error) {
+	subscriptionData := &models.Subscription{
+		ServiceName: req.GetServiceName(),
+		Category:    req.GetCategory(),
+		Tags:        req.GetTags(),
+	}
+
+	if req.GetId() != "" {
+		id, err := uuid.Parse(req.GetId())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid id format")
+		}
+		subscriptionData.ID = id
+	}
+
+	if subscriptionData.ServiceName == "" {
+		return nil, status.Error(codes.InvalidArgument, "service name is nil")
+	}
+
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil || userID == uuid.Nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+	}
+	subscriptionData.UserID = userID
+
+	price := int(req.GetPrice())
+	subscriptionData.Price = &price
+
+	startDate, err := fromProtoMonthYear(req.GetStartDate())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid start_date: "+err.Error())
+	}
+	subscriptionData.StartDate = models.MonthYear(startDate)
+
+	if req.EndDate != nil {
+		endDate, err := fromProtoMonthYear(req.GetEndDate())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid end_date: "+err.Error())
+		}
+		end := models.MonthYear(endDate)
+		subscriptionData.EndDate = &end
+	}
+
+	if req.TrialEnd != nil {
+		trialEnd, err := fromProtoMonthYear(req.GetTrialEnd())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid trial_end: "+err.Error())
+		}
+		end := models.MonthYear(trialEnd)
+		subscriptionData.TrialEnd = &end
+	}
+	subscriptionData.Promo = fromProtoPromo(req.GetPromo())
+
+	createdSubscription, err := h.usecase.CreateSubscription(ctx, subscriptionData)
+	if err != nil {
+		return nil, h.statusFromError(ctx, err)
+	}
+
+	return toProtoSubscription(createdSubscription), nil
+}
+
+func (h *Handler) GetSubscription(ctx context.Context, req *subscriptionsv1.GetSubscriptionRequest) (*subscriptionsv1.Subscription, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id format")
+	}
+
+	sub, err := h.usecase.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, h.statusFromError(ctx, err)
+	}
+
+	return toProtoSubscription(sub), nil
+}
+
+func (h *Handler) UpdateSubscription(ctx context.Context, req *subscriptionsv1.UpdateSubscriptionRequest) (*subscriptionsv1.Subscription, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id format")
+	}
+
+	updates := make(map[string]interface{})
+
+	if req.ServiceName != nil {
+		if req.GetServiceName() == "" {
+			return nil, status.Error(codes.InvalidArgument, "service name is nil")
+		}
+		updates["service_name"] = req.GetServiceName()
+	}
+
+	if req.Price != nil {
+		updates["price"] = int(req.GetPrice())
+	}
+
+	if req.EffectiveFrom != nil {
+		effectiveFrom, err := fromProtoMonthYear(req.GetEffectiveFrom())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid effective_from: "+err.Error())
+		}
+		updates["effective_from"] = effectiveFrom
+	}
+
+	if req.UserId != nil {
+		userID, err := uuid.Parse(req.GetUserId())
+		if err != nil || userID == uuid.Nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+		}
+		updates["user_id"] = userID
+	}
+
+	if req.StartDate != nil {
+		startDate, err := fromProtoMonthYear(req.GetStartDate())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid start_date: "+err.Error())
+		}
+		updates["start_date"] = startDate
+	}
+
+	switch {
+	case req.GetClearEndDate():
+		updates["end_date"] = nil
+	case req.EndDate != nil:
+		endDate, err := fromProtoMonthYear(req.GetEndDate())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid end_date: "+err.Error())
+		}
+		updates["end_date"] = endDate
+	}
+
+	if req.Category != nil {
+		updates["category"] = req.GetCategory()
+	}
+
+	if req.Tags != nil {
+		updates["tags"] = req.GetTags().GetTags()
+	}
+
+	switch {
+	case req.GetClearTrialEnd():
+		updates["trial_end"] = nil
+	case req.TrialEnd != nil:
+		trialEnd, err := fromProtoMonthYear(req.GetTrialEnd())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid trial_end: "+err.Error())
+		}
+		updates["trial_end"] = trialEnd
+	}
+
+	switch {
+	case req.GetClearPromo():
+		updates["promo"] = nil
+	case req.Promo != nil:
+		updates["promo"] = fromProtoPromo(req.GetPromo())
+	}
+
+	updatedSubscription, err := h.usecase.UpdateSubscription(ctx, id, updates)
+	if err != nil {
+		return nil, h.statusFromError(ctx, err)
+	}
+
+	return toProtoSubscription(updatedSubscription), nil
+}
+
+func (h *Handler) DeleteSubscription(ctx context.Context, req *subscriptionsv1.DeleteSubscriptionRequest) (*emptypb.Empty, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id format")
+	}
+
+	if err := h.usecase.DeleteSubscription(ctx, id); err != nil {
+		return nil, h.statusFromError(ctx, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *Handler) DeletePrice(ctx context.Context, req *subscriptionsv1.DeletePriceRequest) (*subscriptionsv1.Subscription, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id format")
+	}
+
+	month, err := fromProtoMonthYear(req.GetMonth())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid month: "+err.Error())
+	}
+
+	updated, err := h.usecase.DeletePrice(ctx, id, month)
+	if err != nil {
+		return nil, h.statusFromError(ctx, err)
+	}
+
+	return toProtoSubscription(updated), nil
+}
+
+func (h *Handler) TrialsEnding(ctx context.Context, req *subscriptionsv1.TrialsEndingRequest) (*subscriptionsv1.ListSubscriptionsResponse, error) {
+	month := time.Now().UTC()
+	if req.Month != nil {
+		var err error
+		if month, err = fromProtoMonthYear(req.GetMonth()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid month: "+err.Error())
+		}
+	}
+
+	var userIDs []uuid.UUID
+	for _, idStr := range req.GetUserIds() {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user_ids: "+idStr)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	subs, err := h.usecase.TrialsEnding(ctx, month, userIDs)
+	if err != nil {
+		return nil, h.statusFromError(ctx, err)
+	}
+
+	return toProtoSubscriptions(subs), nil
+}
+
+func (h *Handler) ListSubscriptions(ctx context.Context, req *subscriptionsv1.ListSubscriptionsRequest) (*subscriptionsv1.ListSubscriptionsResponse, error) {
+	var (
+		subs []*models.Subscription
+		err  error
+	)
+	if req.GetCategory() == "" && req.GetTag() == "" {
+		subs, err = h.usecase.GetAllSubscriptions(ctx)
+	} else {
+		subs, err = h.usecase.ListSubscriptions(ctx, models.SubscriptionFilter{
+			Category: req.GetCategory(),
+			Tag:      req.GetTag(),
+		})
+	}
+	if err != nil {
+		return nil, h.statusFromError(ctx, err)
+	}
+
+	return toProtoSubscriptions(subs), nil
+}
+
+func (h *Handler) SumSubscriptions(ctx context.Context, req *subscriptionsv1.SumSubscriptionsRequest) (*subscriptionsv1.SumSubscriptionsResponse, error) {
+	var startDate, endDate string
+
+	if req.StartDate != nil {
+		t, err := fromProtoMonthYear(req.GetStartDate())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid start_date: "+err.Error())
+		}
+		startDate = t.Format("01-2006")
+	}
+
+	if req.EndDate != nil {
+		t, err := fromProtoMonthYear(req.GetEndDate())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid end_date: "+err.Error())
+		}
+		endDate = t.Format("01-2006")
+	}
+
+	for _, userID := range req.GetUserIds() {
+		if _, err := uuid.Parse(userID); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user_ids: "+userID)
+		}
+	}
+
+	sum, err := h.usecase.GetSumSubscriptions(ctx, models.CostFilter{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		ServiceName: req.GetServiceName(),
+		UsersIDs:    strings.Join(req.GetUserIds(), ","),
+		Category:    req.GetCategory(),
+		Tag:         req.GetTag(),
+	})
+	if err != nil {
+		return nil, h.statusFromError(ctx, err)
+	}
+
+	return &subscriptionsv1.SumSubscriptionsResponse{Sum: int64(sum)}, nil
+}
+
+// statusFromError переводит ошибку usecase в статус gRPC. Текст
+// внутренних ошибок клиенту не отдается, а пишется в лог.
+func (h *Handler) statusFromError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, subscriptions.ErrNotFound),
+		errors.Is(err, subscriptions.ErrTagNotFound),
+		errors.Is(err, subscriptions.ErrPriceNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, subscriptions.ErrAlreadyExists),
+		errors.Is(err, subscriptions.ErrTagExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, subscriptions.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		h.logger.ErrorContext(ctx, "grpc handler: "+err.Error())
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func toProtoSubscription(sub *models.Subscription) *subscriptionsv1.Subscription {
+	resp := &subscriptionsv1.Subscription{
+		Id:          sub.ID.String(),
+		ServiceName: sub.ServiceName,
+		UserId:      sub.UserID.String(),
+		StartDate:   toProtoMonthYear(sub.StartDate.Time()),
+		Category:    sub.Category,
+		Tags:        sub.Tags,
+	}
+
+	if sub.Price != nil {
+		resp.Price = int64(*sub.Price)
+	}
+
+	if sub.EndDate != nil {
+		resp.EndDate = toProtoMonthYear(sub.EndDate.Time())
+	}
+
+	if sub.TrialEnd != nil {
+		resp.TrialEnd = toProtoMonthYear(sub.TrialEnd.Time())
+	}
+
+	if sub.Promo != nil {
+		resp.Promo = &subscriptionsv1.Promo{
+			Type:   sub.Promo.Type,
+			Value:  int64(sub.Promo.Value),
+			Months: int32(sub.Promo.Months),
+		}
+	}
+
+	for _, period := range sub.Prices {
+		resp.Prices = append(resp.Prices, &subscriptionsv1.PricePeriod{
+			EffectiveFrom: toProtoMonthYear(period.EffectiveFrom.Time()),
+			Price:         int64(period.Price),
+		})
+	}
+
+	return resp
+}
+
+func toProtoSubscriptions(subs []*models.Subscription) *subscriptionsv1.ListSubscriptionsResponse {
+	resp := &subscriptionsv1.ListSubscriptionsResponse{
+		Subscriptions: make([]*subscriptionsv1.Subscription, 0, len(subs)),
+	}
+	for _, sub := range subs {
+		resp.Subscriptions = append(resp.Subscriptions, toProtoSubscription(sub))
+	}
+	return resp
+}
+
+func fromProtoPromo(promo *subscriptionsv1.Promo) *models.Promo {
+	if promo == nil {
+		return nil
+	}
+	return &models.Promo{
+		Type:   promo.GetType(),
+		Value:  int(promo.GetValue()),
+		Months: int(promo.GetMonths()),
+	}
+}
+
+func toProtoMonthYear(t time.Time) *subscriptionsv1.MonthYear {
+	return &subscriptionsv1.MonthYear{
+		Year:  int32(t.Year()),
+		Month: int32(t.Month()),
+	}
+}
+
+func fromProtoMonthYear(m *subscriptionsv1.MonthYear) (time.Time, error) {
+	if m == nil {
+		return time.Time{}, errors.New("value is required")
+	}
+	if m.GetMonth() < 1 || m.GetMonth() > 12 {
+		return time.Time{}, errors.New("month must be between 1 and 12")
+	}
+	if m.GetYear() < 1 {
+		return time.Time{}, errors.New("year must be positive")
+	}
+
+	return time.Date(int(m.GetYear()), time.Month(m.GetMonth()), 1, 0, 0, 0, 0, time.UTC), nil
+}
@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	subscriptionsv1 "github.com/ekkserapopova/subscriptions/api/subscriptions/v1"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+)
+
+// failingUseCase возвращает err из GetSubscriptionByID.
+type failingUseCase struct {
+	subscriptions.UseCase
+
+	err error
+}
+
+func (u failingUseCase) GetSubscriptionByID(context.Context, uuid.UUID) (*models.Subscription, error) {
+	return nil, u.err
+}
+
+// dial регистрирует обработчик на сервере в памяти и возвращает клиента
+// к нему.
+func dial(t *testing.T, h *Handler) subscriptionsv1.SubscriptionServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	subscriptionsv1.RegisterSubscriptionServiceServer(srv, h)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return subscriptionsv1.NewSubscriptionServiceClient(conn)
+}
+
+func TestStatusFromError(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		err     error
+		code    codes.Code
+		message string
+		logged  bool
+	}{
+		{name: "неверный id", id: "not-a-uuid", code: codes.InvalidArgument, message: "invalid id format"},
+		{name: "не найдена", err: subscriptions.ErrNotFound, code: codes.NotFound, message: subscriptions.ErrNotFound.Error()},
+		{name: "тег не найден", err: subscriptions.ErrTagNotFound, code: codes.NotFound, message: subscriptions.ErrTagNotFound.Error()},
+		{name: "уже существует", err: subscriptions.ErrAlreadyExists, code: codes.AlreadyExists, message: subscriptions.ErrAlreadyExists.Error()},
+		{name: "тег занят", err: subscriptions.ErrTagExists, code: codes.AlreadyExists, message: subscriptions.ErrTagExists.Error()},
+		{name: "неверный аргумент", err: subscriptions.ErrNegativePrice, code: codes.InvalidArgument, message: subscriptions.ErrNegativePrice.Error()},
+		{name: "отмена", err: context.Canceled, code: codes.Canceled, message: context.Canceled.Error()},
+		{name: "таймаут", err: fmt.Errorf("query: %w", context.DeadlineExceeded), code: codes.DeadlineExceeded, message: "query: " + context.DeadlineExceeded.Error()},
+		{name: "внутренняя ошибка скрыта", err: errors.New("dial tcp 10.0.0.5:5432: connection refused"), code: codes.Internal, message: "internal error", logged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			client := dial(t, NewHandler(Params{
+				Logger:  slog.New(slog.NewTextHandler(&logs, nil)),
+				UseCase: failingUseCase{err: tt.err},
+			}))
+
+			id := tt.id
+			if id == "" {
+				id = uuid.NewString()
+			}
+			_, err := client.GetSubscription(context.Background(), &subscriptionsv1.GetSubscriptionRequest{Id: id})
+
+			st := status.Convert(err)
+			if st.Code() != tt.code || st.Message() != tt.message {
+				t.Errorf("status = %s %q, want %s %q", st.Code(), st.Message(), tt.code, tt.message)
+			}
+			if logged := tt.err != nil && strings.Contains(logs.String(), tt.err.Error()); logged != tt.logged {
+				t.Errorf("error logged = %v, want %v: %s", logged, tt.logged, logs.String())
+			}
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/ekkserapopova/subscriptions/internal/models"
-	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/usecase"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	"github.com/ekkserapopova/subscriptions/pkg/logger"
 	"github.com/ekkserapopova/subscriptions/pkg/reader"
 	"github.com/ekkserapopova/subscriptions/pkg/responser"
 	"github.com/google/uuid"
@@ -10,50 +16,59 @@ import (
 	"go.uber.org/fx"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Params struct {
 	fx.In
 
-	Logger  *slog.Logger
-	UseCase *usecase.UseCase
+	Logger       *slog.Logger
+	UseCase      subscriptions.UseCase
+	Broker       *events.Broker
+	EventsConfig events.Config
 }
 
 type Handler struct {
-	logger   *slog.Logger
-	useacase *usecase.UseCase
+	logger    *slog.Logger
+	useacase  subscriptions.UseCase
+	broker    *events.Broker
+	heartbeat time.Duration
 }
 
 func NewHandler(params Params) *Handler {
 	return &Handler{
-		logger:   params.Logger,
-		useacase: params.UseCase,
+		logger:    params.Logger,
+		useacase:  params.UseCase,
+		broker:    params.Broker,
+		heartbeat: params.EventsConfig.Heartbeat,
 	}
 }
 
 // CreateSubscription godoc
 // @Summary Создать подписку
-// @Description Создает новую подписку
+// @Description Создает новую подписку. Известный каталогу сервис (по service_id или названию) связывается с каталогом и получает каноническое название
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param subscription body models.Subscription true "Подписка"
 // @Success 201 {object} models.Subscription
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /subscriptions [post]
 func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 	subscriptionData := &models.Subscription{}
 	subscriptionData.ID = uuid.New()
 	if err := reader.ReadResponseData(r, subscriptionData); err != nil {
-		h.logger.Error("create subscription request err: " + err.Error())
+		h.logger.ErrorContext(r.Context(), "create subscription request err: "+err.Error())
 		responser.SendErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	if subscriptionData.ServiceName == "" {
-		h.logger.Error("create subscription request err: service name is nil")
+	if subscriptionData.ServiceName == "" && subscriptionData.ServiceID == nil {
+		h.logger.ErrorContext(r.Context(), "create subscription request err: service name is nil")
 		responser.SendErr(w, http.StatusBadRequest, "create subscription request err: service name is nil")
 		return
 	}
@@ -61,26 +76,26 @@ func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 	nilTime := time.Time{}
 
 	if subscriptionData.StartDate == models.MonthYear(nilTime) {
-		h.logger.Error("create subscription request err: start date is nil")
+		h.logger.ErrorContext(r.Context(), "create subscription request err: start date is nil")
 		responser.SendErr(w, http.StatusBadRequest, "create subscription request err: start date is nil")
 		return
 	}
 
 	if subscriptionData.Price == nil {
-		h.logger.Error("create subscription request err: price is nil")
+		h.logger.ErrorContext(r.Context(), "create subscription request err: price is nil")
 		responser.SendErr(w, http.StatusBadRequest, "create subscription request err: price is nil")
 		return
 	}
 
 	if subscriptionData.UserID == uuid.Nil {
-		h.logger.Error("create subscription request err: user_id is nil")
+		h.logger.ErrorContext(r.Context(), "create subscription request err: user_id is nil")
 		responser.SendErr(w, http.StatusBadRequest, "create subscription request err: user_id is nil")
 		return
 	}
 
 	createdSubscription, err := h.useacase.CreateSubscription(r.Context(), subscriptionData)
 	if err != nil {
-		responser.SendErr(w, http.StatusInternalServerError, err.Error())
+		responser.SendErr(w, statusFromError(err), err.Error())
 		return
 	}
 
@@ -88,7 +103,7 @@ func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Изменить подписку
-// @Description Изменяет существующую подписку
+// @Description Изменяет существующую подписку. Новое название заново связывается с каталогом, service_id: null отвязывает подписку. tags заменяет теги целиком, пустая category возвращает категорию из каталога. price не переписывает прошлые месяцы: новая цена действует с effective_from (MM-YYYY, можно в будущем), без него - с текущего месяца. trial_end - последний бесплатный месяц, promo - вводная цена после него ({"type": "percentage"|"fixed", "value", "months"}); null убирает пробный период или промо
 // @Tags subscriptions
 // @Accept json
 // @Produce json
@@ -96,52 +111,84 @@ func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 // @Param subscription body models.Subscription true "Подписка"
 // @Success 200 {object} models.Subscription
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /subscriptions/{id} [put]
 func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr, ok := vars["id"]
 	if !ok || idStr == "" {
-		h.logger.Error("update subscription request err: id is nil")
+		h.logger.ErrorContext(r.Context(), "update subscription request err: id is nil")
 		responser.SendErr(w, http.StatusBadRequest, "id is required")
 		return
 	}
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		h.logger.Error("update subscription request err: invalid id format")
+		h.logger.ErrorContext(r.Context(), "update subscription request err: invalid id format")
 		responser.SendErr(w, http.StatusBadRequest, "invalid id format")
 		return
 	}
 
 	updates := make(map[string]interface{})
 	if err := reader.ReadResponseData(r, &updates); err != nil {
-		h.logger.Error("update subscription request err: " + err.Error())
+		h.logger.ErrorContext(r.Context(), "update subscription request err: "+err.Error())
 		responser.SendErr(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	if len(updates) == 0 {
-		h.logger.Error("update subscription request err: no fields to update")
+		h.logger.ErrorContext(r.Context(), "update subscription request err: no fields to update")
 		responser.SendErr(w, http.StatusBadRequest, "no fields to update")
 		return
 	}
 
-	if startDate, ok := updates["start_date"]; ok {
-		if t, ok := startDate.(string); ok {
-			parsedTime, parseErr := time.Parse("2006-01", t)
-			if parseErr != nil || parsedTime.IsZero() {
-				h.logger.Error("update subscription request err: invalid start_date")
-				responser.SendErr(w, http.StatusBadRequest, "invalid start_date")
-				return
-			}
-			updates["start_date"] = parsedTime
+	if value, ok := updates["service_id"]; ok && value != nil {
+		idStr, ok := value.(string)
+		serviceID, err := uuid.Parse(idStr)
+		if !ok || err != nil {
+			h.logger.ErrorContext(r.Context(), "update subscription request err: invalid service_id")
+			responser.SendErr(w, http.StatusBadRequest, "invalid service_id")
+			return
+		}
+		updates["service_id"] = serviceID
+	}
+
+	if value, ok := updates["promo"]; ok && value != nil {
+		promo, err := decodePromo(value)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "update subscription request err: invalid promo")
+			responser.SendErr(w, http.StatusBadRequest, "invalid promo")
+			return
 		}
+		updates["promo"] = promo
+	}
+
+	for _, field := range []string{"start_date", "end_date", "trial_end", "effective_from"} {
+		value, ok := updates[field]
+		if !ok || value == nil {
+			continue
+		}
+
+		t, ok := value.(string)
+		if !ok {
+			h.logger.ErrorContext(r.Context(), "update subscription request err: invalid "+field)
+			responser.SendErr(w, http.StatusBadRequest, "invalid "+field)
+			return
+		}
+
+		parsedTime, parseErr := parseMonthYear(t)
+		if parseErr != nil || parsedTime.IsZero() {
+			h.logger.ErrorContext(r.Context(), "update subscription request err: invalid "+field)
+			responser.SendErr(w, http.StatusBadRequest, "invalid "+field)
+			return
+		}
+		updates[field] = parsedTime
 	}
 
 	updatedSubscription, err := h.useacase.UpdateSubscription(r.Context(), id, updates)
 	if err != nil {
-		responser.SendErr(w, http.StatusInternalServerError, err.Error())
+		responser.SendErr(w, statusFromError(err), err.Error())
 		return
 	}
 
@@ -157,6 +204,7 @@ func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} models.Subscription
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
 // @Router /subscriptions/{id} [get]
 func (h *Handler) GetSubscriptionByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -170,7 +218,7 @@ func (h *Handler) GetSubscriptionByID(w http.ResponseWriter, r *http.Request) {
 
 	sub, err := h.useacase.GetSubscriptionByID(r.Context(), id)
 	if err != nil {
-		responser.SendErr(w, http.StatusNotFound, err.Error())
+		responser.SendErr(w, statusFromError(err), err.Error())
 		return
 	}
 
@@ -178,19 +226,37 @@ func (h *Handler) GetSubscriptionByID(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Получить все подписки
-// @Description Получить записи всех подписок
+// @Description Получить записи всех подписок, с фильтром по категории и тегу
 // @Tags subscriptions
 // @Accept json
 // @Produce json
+// @Param category query string false "Категория"
+// @Param tag query string false "Тег"
 // @Success 200 {array} models.Subscription
 // @Failure 500 {object} map[string]string
 // @Router /subscriptions [get]
 func (h *Handler) GetAllSubscriptions(w http.ResponseWriter, r *http.Request) {
-	subs, err := h.useacase.GetAllSubscriptions(r.Context())
+	filter := models.SubscriptionFilter{
+		Category: r.URL.Query().Get("category"),
+		Tag:      r.URL.Query().Get("tag"),
+	}
+
+	var (
+		subs []*models.Subscription
+		err  error
+	)
+	if filter.Category == "" && filter.Tag == "" {
+		subs, err = h.useacase.GetAllSubscriptions(r.Context())
+	} else {
+		subs, err = h.useacase.ListSubscriptions(r.Context(), filter)
+	}
 	if err != nil {
 		responser.SendErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if subs == nil {
+		subs = []*models.Subscription{}
+	}
 
 	responser.SendOK(w, http.StatusOK, subs)
 }
@@ -217,38 +283,111 @@ func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.useacase.DeleteSubscription(r.Context(), id); err != nil {
-		if err.Error() == "subscription not found" {
-			responser.SendErr(w, http.StatusNotFound, err.Error())
-		} else {
-			responser.SendErr(w, http.StatusInternalServerError, err.Error())
-		}
+		responser.SendErr(w, statusFromError(err), err.Error())
 		return
 	}
 
 	responser.SendOK(w, http.StatusNoContent, map[string]string{"msg": "subscription deleted"})
 }
 
+// @Summary Отменить изменение цены
+// @Description Удаляет период цены, начинающийся с month, например запланированное повышение. Начальную цену удалить нельзя
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param month path string true "Месяц начала периода в формате MM-YYYY"
+// @Success 200 {object} models.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/{id}/prices/{month} [delete]
+func (h *Handler) DeletePrice(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		responser.SendErr(w, http.StatusBadRequest, "invalid id format")
+		return
+	}
+
+	month, err := parseMonthYear(vars["month"])
+	if err != nil {
+		responser.SendErr(w, http.StatusBadRequest, "invalid month, expected MM-YYYY")
+		return
+	}
+
+	updated, err := h.useacase.DeletePrice(r.Context(), id, month)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, updated)
+}
+
+// @Summary Подписки с заканчивающимся пробным периодом
+// @Description Подписки, последний бесплатный месяц которых - month: со следующего месяца начинается оплата. Для напоминаний и отчетов
+// @Tags subscriptions
+// @Produce json
+// @Param month query string false "Месяц в формате MM-YYYY, по умолчанию текущий"
+// @Param users_ids query string false "Список ID пользователей через запятую"
+// @Success 200 {array} models.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/trials [get]
+func (h *Handler) TrialsEnding(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	month := time.Now().UTC()
+	if value := query.Get("month"); value != "" {
+		var err error
+		if month, err = parseMonthYear(value); err != nil {
+			responser.SendErr(w, http.StatusBadRequest, "invalid month, expected MM-YYYY")
+			return
+		}
+	}
+
+	var userIDs []uuid.UUID
+	if value := query.Get("users_ids"); value != "" {
+		for _, idStr := range strings.Split(value, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(idStr))
+			if err != nil {
+				responser.SendErr(w, http.StatusBadRequest, "invalid users_ids")
+				return
+			}
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	subs, err := h.useacase.TrialsEnding(r.Context(), month, userIDs)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+	if subs == nil {
+		subs = []*models.Subscription{}
+	}
+
+	responser.SendOK(w, http.StatusOK, subs)
+}
+
 // @Summary Получить суммарную стоимость подписок
-// @Description Получить суммарную стоимость подписок с фильтрацией по дате, названию сервиса и по пользователям
+// @Description Получить суммарную стоимость подписок с фильтрацией по дате, названию сервиса, пользователям, категории и тегу. Подписка входит в сумму по цене своего последнего месяца, бессрочная - по цене месяца, после которого сумма к оплате не меняется (конец пробного и промо-периода, последнее изменение цены). Сумма не зависит от текущей даты
 // @Tags subscriptions
 // @Accept json
 // @Produce json
-// @Param start_date query string false "Дата начала фильтрации в формате YYYY-MM"
-// @Param end_date query string false "Дата окончания фильтрации в формате YYYY-MM"
-// @Param name query string false "Название сервиса"
+// @Param start_date query string false "Дата начала фильтрации в формате MM-YYYY"
+// @Param end_date query string false "Дата окончания фильтрации в формате MM-YYYY"
+// @Param name query string false "Название сервиса или его псевдоним из каталога"
 // @Param users_ids query string false "Список ID пользователей через запятую"
+// @Param category query string false "Категория"
+// @Param tag query string false "Тег"
 // @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]string
 // @Router /subscriptions/sum [get]
 func (h *Handler) GetSumSubscriptions(w http.ResponseWriter, r *http.Request) {
-	startDate := r.URL.Query().Get("start_date")
-	endDate := r.URL.Query().Get("end_date")
-	nameService := r.URL.Query().Get("name")
-	usersIds := r.URL.Query().Get("users_ids")
-
-	sum, err := h.useacase.GetSumSubscriptions(r.Context(), startDate, endDate, nameService, usersIds)
+	sum, err := h.useacase.GetSumSubscriptions(r.Context(), costFilter(r))
 	if err != nil {
-		h.logger.Error("get sum subscriptions err: " + err.Error())
+		h.logger.ErrorContext(r.Context(), "get sum subscriptions err: "+err.Error())
 		responser.SendErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -257,3 +396,283 @@ func (h *Handler) GetSumSubscriptions(w http.ResponseWriter, r *http.Request) {
 		"sum": sum,
 	})
 }
+
+// @Summary Разбивка суммы по пользователям
+// @Description Сколько из суммарной стоимости приходится на каждого пользователя. Общие подписки домохозяйств раскладываются по долям участников. Фильтры те же, что у /subscriptions/sum
+// @Tags subscriptions
+// @Produce json
+// @Param start_date query string false "Дата начала фильтрации в формате MM-YYYY"
+// @Param end_date query string false "Дата окончания фильтрации в формате MM-YYYY"
+// @Param name query string false "Название сервиса или его псевдоним из каталога"
+// @Param users_ids query string false "Список ID пользователей через запятую"
+// @Param category query string false "Категория"
+// @Param tag query string false "Тег"
+// @Success 200 {array} models.UserCost
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/sum/breakdown [get]
+func (h *Handler) GetSumBreakdown(w http.ResponseWriter, r *http.Request) {
+	breakdown, err := h.useacase.GetSumBreakdown(r.Context(), costFilter(r))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get sum breakdown err: "+err.Error())
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, breakdown)
+}
+
+// @Summary Суммы по категориям или тегам
+// @Description Делит суммарную стоимость по категориям или тегам, группы по убыванию суммы. Подписка с несколькими тегами входит в каждую их группу; подписки без категории или тегов попадают в группу "". Фильтры те же, что у /subscriptions/sum
+// @Tags subscriptions
+// @Produce json
+// @Param group_by query string true "category или tag"
+// @Param start_date query string false "Дата начала фильтрации в формате MM-YYYY"
+// @Param end_date query string false "Дата окончания фильтрации в формате MM-YYYY"
+// @Param name query string false "Название сервиса или его псевдоним из каталога"
+// @Param users_ids query string false "Список ID пользователей через запятую"
+// @Param category query string false "Категория"
+// @Param tag query string false "Тег"
+// @Success 200 {array} models.GroupCost
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/sum/groups [get]
+func (h *Handler) GetSumGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.useacase.GetSumGroups(r.Context(), costFilter(r), r.URL.Query().Get("group_by"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get sum groups err: "+err.Error())
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, groups)
+}
+
+// @Summary Теги подписок
+// @Description Все теги с числом подписок, по алфавиту
+// @Tags tags
+// @Produce json
+// @Success 200 {array} models.TagUsage
+// @Failure 500 {object} map[string]string
+// @Router /tags [get]
+func (h *Handler) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.useacase.ListTags(r.Context())
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+	if tags == nil {
+		tags = []*models.TagUsage{}
+	}
+
+	responser.SendOK(w, http.StatusOK, tags)
+}
+
+// @Summary Переименовать тег
+// @Description Заменяет тег во всех подписках. Если новый тег уже есть, вернет 409: используйте /tags/merge
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag path string true "Тег"
+// @Param rename body models.TagRename true "Новое название"
+// @Success 200 {object} models.TagUsage
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{tag} [put]
+func (h *Handler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	rename := models.TagRename{}
+	if err := reader.ReadResponseData(r, &rename); err != nil {
+		h.logger.ErrorContext(r.Context(), "rename tag request err: "+err.Error())
+		responser.SendErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	usage, err := h.useacase.RenameTag(r.Context(), mux.Vars(r)["tag"], rename.Name)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, usage)
+}
+
+// @Summary Объединить теги
+// @Description Заменяет теги tags тегом into во всех подписках
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param merge body models.TagMerge true "Объединяемые теги"
+// @Success 200 {object} models.TagUsage
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/merge [post]
+func (h *Handler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	merge := models.TagMerge{}
+	if err := reader.ReadResponseData(r, &merge); err != nil {
+		h.logger.ErrorContext(r.Context(), "merge tags request err: "+err.Error())
+		responser.SendErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	usage, err := h.useacase.MergeTags(r.Context(), merge.Tags, merge.Into)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, usage)
+}
+
+// @Summary Поток изменений подписок
+// @Description Server-Sent Events с изменениями подписок. Для продолжения потока передайте заголовок Last-Event-ID. Если пропущенные события уже не восстановить, первым приходит событие stream.reset: подписки нужно перечитать
+// @Tags subscriptions
+// @Produce text/event-stream
+// @Param user_id query string false "ID пользователя, события которого нужно получать"
+// @Param Last-Event-ID header string false "ID последнего полученного события"
+// @Success 200 {object} events.Event
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /subscriptions/events [get]
+func (h *Handler) StreamSubscriptionEvents(w http.ResponseWriter, r *http.Request) {
+	userID := uuid.Nil
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		parsedID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			responser.SendErr(w, http.StatusBadRequest, "invalid user_id format")
+			return
+		}
+		userID = parsedID
+		logger.SetUserID(r.Context(), userID.String())
+	}
+
+	var lastEventID uint64
+	lastEventIDStr := r.Header.Get("Last-Event-ID")
+	if lastEventIDStr == "" {
+		lastEventIDStr = r.URL.Query().Get("last_event_id")
+	}
+	if lastEventIDStr != "" {
+		parsedID, err := strconv.ParseUint(lastEventIDStr, 10, 64)
+		if err != nil {
+			responser.SendErr(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+		lastEventID = parsedID
+	}
+
+	rc := http.NewResponseController(w)
+
+	// WriteTimeout сервера оборвал бы долгоживущий поток.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.WarnContext(r.Context(), "stream subscription events: cannot reset write deadline: "+err.Error())
+	}
+
+	sub, backlog := h.broker.Subscribe(userID, lastEventID)
+	defer h.broker.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, e := range backlog {
+		if err := writeEvent(w, e); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		h.logger.ErrorContext(r.Context(), "stream subscription events err: "+err.Error())
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// parseMonthYear принимает формат MM-YYYY, как в models.MonthYear, а также
+// YYYY-MM, который раньше ожидал этот обработчик.
+func parseMonthYear(s string) (time.Time, error) {
+	t, err := time.Parse("01-2006", s)
+	if err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01", s)
+}
+
+// decodePromo разбирает промо из JSON-тела обновления.
+func decodePromo(value interface{}) (*models.Promo, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	promo := &models.Promo{}
+	if err := json.Unmarshal(data, promo); err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+// costFilter читает фильтры отчетов о расходах из запроса.
+func costFilter(r *http.Request) models.CostFilter {
+	query := r.URL.Query()
+	return models.CostFilter{
+		StartDate:   query.Get("start_date"),
+		EndDate:     query.Get("end_date"),
+		ServiceName: query.Get("name"),
+		UsersIDs:    query.Get("users_ids"),
+		Category:    query.Get("category"),
+		Tag:         query.Get("tag"),
+	}
+}
+
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, subscriptions.ErrNotFound),
+		errors.Is(err, subscriptions.ErrTagNotFound),
+		errors.Is(err, subscriptions.ErrPriceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, subscriptions.ErrAlreadyExists),
+		errors.Is(err, subscriptions.ErrTagExists):
+		return http.StatusConflict
+	case errors.Is(err, subscriptions.ErrInvalidArgument):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
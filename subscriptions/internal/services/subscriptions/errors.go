@@ -1 +1,35 @@
 package subscriptions
+
+import (
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/errs"
+)
+
+var (
+	ErrNotFound        = errors.New("subscription not found")
+	ErrAlreadyExists   = errors.New("subscription with this id already exists")
+	ErrInvalidArgument = errs.ErrInvalidArgument
+	ErrTagNotFound     = errors.New("tag not found")
+	ErrTagExists       = errors.New("tag already exists, merge tags instead")
+	ErrPriceNotFound   = errors.New("price change not found")
+)
+
+var (
+	ErrIDRequired        = errs.InvalidArgument("id is required")
+	ErrStartDateRequired = errs.InvalidArgument("start date is nil")
+	ErrNoFieldsToUpdate  = errs.InvalidArgument("no fields to update")
+	ErrPriceRequired     = errs.InvalidArgument("price is required")
+	ErrNegativePrice     = errs.InvalidArgument("price must not be negative")
+	ErrEndBeforeStart    = errs.InvalidArgument("end date is before start date")
+	ErrUserNotFound      = errs.InvalidArgument("user not found")
+	ErrServiceNotFound   = errs.InvalidArgument("service not found in catalog")
+	ErrTagRequired       = errs.InvalidArgument("tag is required")
+	ErrInvalidGroupBy    = errs.InvalidArgument("group_by must be category or tag")
+	ErrPriceBeforeStart  = errs.InvalidArgument("price change is before start date")
+	ErrPriceAfterEnd     = errs.InvalidArgument("price change is after end date")
+	ErrInitialPrice      = errs.InvalidArgument("initial price cannot be deleted")
+	ErrTrialBeforeStart  = errs.InvalidArgument("trial end is before start date")
+	ErrInvalidPromo      = errs.InvalidArgument("promo type must be percentage or fixed")
+	ErrPromoValue        = errs.InvalidArgument("promo percentage must be between 0 and 100, fixed price must not be negative")
+	ErrPromoMonths       = errs.InvalidArgument("promo months must be positive")
+)
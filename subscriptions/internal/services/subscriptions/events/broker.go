@@ -0,0 +1,167 @@
+package events
+
+import (
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type BrokerParams struct {
+	fx.In
+
+	Config Config
+	Logger *slog.Logger
+}
+
+// Broker раздает события подключенным клиентам и хранит последние LogSize
+// событий, чтобы клиент мог продолжить поток с Last-Event-ID.
+//
+// Уведомления Postgres приходят не обязательно в порядке ID, поэтому журнал
+// хранит события в порядке получения: клиент с Last-Event-ID уже видел все
+// события, полученные до него, и продолжает с места этого события в журнале.
+type Broker struct {
+	log        *slog.Logger
+	bufferSize int
+
+	mu      sync.Mutex
+	history []Event
+	head    int
+	// positions - номер получения каждого события журнала по его ID.
+	positions map[uint64]uint64
+	received  uint64
+	// lostID - ID события, полученного непосредственно перед журналом:
+	// вытесненного или предшествующего первому событию этого процесса.
+	lostID      uint64
+	subscribers map[*Subscriber]struct{}
+}
+
+type Subscriber struct {
+	userID uuid.UUID
+	events chan Event
+}
+
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscriber) matches(e Event) bool {
+	return s.userID == uuid.Nil || s.userID == e.UserID
+}
+
+func NewBroker(params BrokerParams) *Broker {
+	logSize := params.Config.LogSize
+	if logSize <= 0 {
+		logSize = 1
+	}
+
+	bufferSize := params.Config.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+
+	return &Broker{
+		log:         params.Logger,
+		bufferSize:  bufferSize,
+		history:     make([]Event, 0, logSize),
+		positions:   make(map[uint64]uint64, logSize),
+		subscribers: make(map[*Subscriber]struct{}),
+	}
+}
+
+// Subscribe регистрирует клиента и возвращает события из журнала, полученные
+// после события lastEventID. uuid.Nil в userID означает подписку на события
+// всех пользователей. Если события lastEventID нет в журнале (вытеснено или
+// ID из другого запуска), вместо пропущенных событий возвращается одно
+// событие TypeReset.
+func (b *Broker) Subscribe(userID uuid.UUID, lastEventID uint64) (*Subscriber, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []Event
+	if lastEventID > 0 {
+		from, ok := b.positions[lastEventID]
+		if !ok && lastEventID == b.lostID {
+			from, ok = 0, true
+		}
+
+		if !ok {
+			backlog = []Event{{ID: b.lastID(), Type: TypeReset, OccurredAt: time.Now().UTC()}}
+		} else {
+			for i := 0; i < len(b.history); i++ {
+				e := b.history[(b.head+i)%len(b.history)]
+				if b.positions[e.ID] > from && (userID == uuid.Nil || e.UserID == userID) {
+					backlog = append(backlog, e)
+				}
+			}
+		}
+	}
+
+	sub := &Subscriber{
+		userID: userID,
+		events: make(chan Event, b.bufferSize),
+	}
+	b.subscribers[sub] = struct{}{}
+
+	return sub, backlog
+}
+
+func (b *Broker) Unsubscribe(sub *Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Dispatch добавляет событие в журнал и отправляет его подписчикам. Событие
+// с меньшим ID, чем уже полученные, тоже доставляется; повтор события из
+// журнала пропускается. Подписчик, который не успевает читать события,
+// отключается и может переподключиться с Last-Event-ID.
+func (b *Broker) Dispatch(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.positions[e.ID]; ok {
+		return
+	}
+	if b.received == 0 {
+		b.lostID = e.ID - 1
+	}
+	b.received++
+	b.positions[e.ID] = b.received
+
+	if len(b.history) < cap(b.history) {
+		b.history = append(b.history, e)
+	} else {
+		b.lostID = b.history[b.head].ID
+		delete(b.positions, b.lostID)
+		b.history[b.head] = e
+		b.head = (b.head + 1) % len(b.history)
+	}
+
+	for sub := range b.subscribers {
+		if !sub.matches(e) {
+			continue
+		}
+		select {
+		case sub.events <- e:
+		default:
+			b.log.Warn("events subscriber is too slow, disconnecting")
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// lastID - ID последнего полученного события: с него клиент продолжит поток
+// после TypeReset.
+func (b *Broker) lastID() uint64 {
+	if len(b.history) == 0 {
+		return 0
+	}
+	return b.history[(b.head+len(b.history)-1)%len(b.history)].ID
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func TestBrokerSubscribeBacklog(t *testing.T) {
+	userID := uuid.New()
+
+	newBroker := func(ids ...uint64) *Broker {
+		b := NewBroker(BrokerParams{
+			Config: Config{LogSize: 3, BufferSize: 1},
+			Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		})
+		for _, id := range ids {
+			e := Event{ID: id, Type: TypeUpdated, UserID: userID}
+			if id%2 == 0 {
+				e.UserID = uuid.New()
+			}
+			b.Dispatch(e)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name        string
+		dispatched  []uint64
+		userID      uuid.UUID
+		lastEventID uint64
+		want        []uint64
+		reset       bool
+	}{
+		{name: "без Last-Event-ID", dispatched: []uint64{1, 2, 3}},
+		{name: "продолжение из журнала", dispatched: []uint64{1, 2, 3, 4, 5}, lastEventID: 2, want: []uint64{3, 4, 5}},
+		{name: "фильтр пользователя", dispatched: []uint64{1, 2, 3, 4, 5}, userID: userID, lastEventID: 2, want: []uint64{3, 5}},
+		{name: "ничего не пропущено", dispatched: []uint64{1, 2, 3}, lastEventID: 3},
+		{name: "события вытеснены", dispatched: []uint64{1, 2, 3, 4, 5}, lastEventID: 1, want: []uint64{5}, reset: true},
+		{name: "ID из другого запуска", dispatched: []uint64{1, 2}, lastEventID: 10, want: []uint64{2}, reset: true},
+		{name: "пустой журнал", lastEventID: 3, want: []uint64{0}, reset: true},
+		{name: "первое событие процесса", dispatched: []uint64{41, 42}, lastEventID: 40, want: []uint64{41, 42}},
+		{name: "до первого события процесса", dispatched: []uint64{41, 42}, lastEventID: 39, want: []uint64{42}, reset: true},
+		{name: "меньший ID после большего", dispatched: []uint64{2, 1}, lastEventID: 2, want: []uint64{1}},
+		{name: "меньший ID уже получен", dispatched: []uint64{2, 1}, lastEventID: 1},
+		{name: "повтор события", dispatched: []uint64{1, 2, 1, 3}, lastEventID: 1, want: []uint64{2, 3}},
+		{name: "вытеснение в порядке получения", dispatched: []uint64{3, 1, 2, 5, 4}, lastEventID: 1, want: []uint64{2, 5, 4}},
+		{name: "вытеснено событие с меньшим ID", dispatched: []uint64{3, 1, 2, 5, 4}, lastEventID: 3, want: []uint64{4}, reset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBroker(tt.dispatched...)
+			sub, backlog := b.Subscribe(tt.userID, tt.lastEventID)
+			defer b.Unsubscribe(sub)
+
+			var ids []uint64
+			for _, e := range backlog {
+				ids = append(ids, e.ID)
+				if (e.Type == TypeReset) != tt.reset {
+					t.Errorf("event %d type = %s, reset = %v", e.ID, e.Type, tt.reset)
+				}
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("backlog = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrokerDispatchOutOfOrder(t *testing.T) {
+	b := NewBroker(BrokerParams{
+		Config: Config{LogSize: 10, BufferSize: 10},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	sub, _ := b.Subscribe(uuid.Nil, 0)
+	defer b.Unsubscribe(sub)
+
+	for _, id := range []uint64{2, 1, 2} {
+		b.Dispatch(Event{ID: id, Type: TypeUpdated})
+	}
+
+	var ids []uint64
+	for len(sub.Events()) > 0 {
+		ids = append(ids, (<-sub.Events()).ID)
+	}
+	if want := []uint64{2, 1}; !slices.Equal(ids, want) {
+		t.Errorf("delivered = %v, want %v", ids, want)
+	}
+}
+
+func TestNotifySize(t *testing.T) {
+	payload := []byte(`{"id":0,"type":"subscription.updated","tags":["a","b"]}`)
+	notified := `{"id": 18446744073709551615, "tags": ["a", "b"], "type": "subscription.updated"}`
+	if got := notifySize(payload); got < len(notified) {
+		t.Errorf("notifySize = %d, want at least %d", got, len(notified))
+	}
+}
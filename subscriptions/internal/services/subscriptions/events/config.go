@@ -0,0 +1,10 @@
+package events
+
+import "time"
+
+type Config struct {
+	Channel    string        `yaml:"channel" env-default:"subscription_events"`
+	LogSize    int           `yaml:"logSize" env-default:"1024"`
+	BufferSize int           `yaml:"bufferSize" env-default:"64"`
+	Heartbeat  time.Duration `yaml:"heartbeat" env-default:"15s"`
+}
@@ -0,0 +1,40 @@
+package events
+
+import (
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/google/uuid"
+	"time"
+)
+
+type Type string
+
+const (
+	TypeCreated Type = "subscription.created"
+	TypeUpdated Type = "subscription.updated"
+	TypeDeleted Type = "subscription.deleted"
+	// TypeReset сообщает клиенту, что часть событий после его Last-Event-ID
+	// уже не восстановить и подписки нужно перечитать.
+	TypeReset Type = "stream.reset"
+)
+
+// Event - изменение подписки. Subscription может отсутствовать, если
+// подписка не поместилась в уведомление Postgres: тогда ее читают по
+// SubscriptionID.
+type Event struct {
+	ID             uint64               `json:"id"`
+	Type           Type                 `json:"type"`
+	UserID         uuid.UUID            `json:"user_id"`
+	SubscriptionID uuid.UUID            `json:"subscription_id"`
+	Subscription   *models.Subscription `json:"subscription,omitempty"`
+	OccurredAt     time.Time            `json:"occurred_at"`
+}
+
+func NewEvent(eventType Type, sub *models.Subscription) Event {
+	return Event{
+		Type:           eventType,
+		UserID:         sub.UserID,
+		SubscriptionID: sub.ID,
+		Subscription:   sub,
+		OccurredAt:     time.Now().UTC(),
+	}
+}
@@ -0,0 +1,155 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx"
+	"log/slog"
+	"time"
+)
+
+const publishQuery = `SELECT pg_notify($1, jsonb_set($2::jsonb, '{id}', to_jsonb(nextval('subscription_event_id_seq')))::text)`
+
+const reconnectDelay = time.Second
+
+// notifyPayloadLimit - ограничение Postgres на размер уведомления NOTIFY.
+const notifyPayloadLimit = 8000
+
+type PublisherParams struct {
+	fx.In
+
+	Config Config
+	Logger *slog.Logger
+	Pool   *pgxpool.Pool
+}
+
+// PostgresPublisher рассылает события через Postgres NOTIFY, чтобы их
+// получили все запущенные экземпляры сервиса, включая текущий.
+type PostgresPublisher struct {
+	pool    *pgxpool.Pool
+	log     *slog.Logger
+	channel string
+}
+
+func NewPostgresPublisher(params PublisherParams) *PostgresPublisher {
+	return &PostgresPublisher{
+		pool:    params.Pool,
+		log:     params.Logger,
+		channel: params.Config.Channel,
+	}
+}
+
+// Publish отправляет событие с подпиской. Если уведомление не помещается
+// в лимит NOTIFY, подписка не передается: событие несет только ID.
+func (p *PostgresPublisher) Publish(ctx context.Context, e Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	if notifySize(payload) >= notifyPayloadLimit {
+		p.log.WarnContext(ctx, "event payload exceeds NOTIFY limit, sending ids only")
+		e.Subscription = nil
+		if payload, err = json.Marshal(e); err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+	}
+
+	if _, err := p.pool.Exec(ctx, publishQuery, p.channel, string(payload)); err != nil {
+		p.log.ErrorContext(ctx, "failed to publish event: "+err.Error())
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
+
+// notifySize оценивает сверху размер уведомления: jsonb::text добавляет
+// пробел после каждого двоеточия и запятой, а id занимает до 20 цифр.
+func notifySize(payload []byte) int {
+	return len(payload) + bytes.Count(payload, []byte(":")) + bytes.Count(payload, []byte(",")) + 20
+}
+
+type ListenerParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    Config
+	Logger    *slog.Logger
+	Pool      *pgxpool.Pool
+	Broker    *Broker
+}
+
+// RunListener подписывается на канал событий через LISTEN и передает
+// полученные уведомления в Broker.
+func RunListener(params ListenerParams) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				listen(ctx, params)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
+
+func listen(ctx context.Context, params ListenerParams) {
+	for {
+		if err := listenOnce(ctx, params); err != nil && ctx.Err() == nil {
+			params.Logger.Error("events listener: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func listenOnce(ctx context.Context, params ListenerParams) error {
+	poolConn, err := params.Pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	// Соединение в режиме LISTEN нельзя возвращать в пул.
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{params.Config.Channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen channel: %w", err)
+	}
+	params.Logger.Info("listening for subscription events")
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var e Event
+		if err := json.Unmarshal([]byte(notification.Payload), &e); err != nil {
+			params.Logger.Warn("events listener: invalid payload: " + err.Error())
+			continue
+		}
+
+		params.Broker.Dispatch(e)
+	}
+}
@@ -0,0 +1,27 @@
+package events
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// LocalPublisher отдает события напрямую в Broker текущего процесса.
+// Используется без базы, когда экземпляр сервиса один.
+type LocalPublisher struct {
+	broker *Broker
+	lastID atomic.Uint64
+}
+
+func NewLocalPublisher(broker *Broker) *LocalPublisher {
+	return &LocalPublisher{broker: broker}
+}
+
+func (p *LocalPublisher) Publish(_ context.Context, e Event) error {
+	e.ID = p.lastID.Add(1)
+	p.broker.Dispatch(e)
+	return nil
+}
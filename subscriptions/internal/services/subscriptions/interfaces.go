@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/ekkserapopova/subscriptions/internal/models"
 	"github.com/google/uuid"
+	"time"
 )
 
 type UseCase interface {
@@ -12,14 +13,51 @@ type UseCase interface {
 	GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
 	DeleteSubscription(ctx context.Context, id uuid.UUID) error
 	GetAllSubscriptions(ctx context.Context) ([]*models.Subscription, error)
-	GetSumSubscriptions(ctx context.Context, startDate, endDate, name, usersIds string) (int, error)
+	ListSubscriptions(ctx context.Context, filter models.SubscriptionFilter) ([]*models.Subscription, error)
+	GetSumSubscriptions(ctx context.Context, filter models.CostFilter) (int, error)
+	GetSumBreakdown(ctx context.Context, filter models.CostFilter) ([]*models.UserCost, error)
+	GetSumGroups(ctx context.Context, filter models.CostFilter, groupBy string) ([]*models.GroupCost, error)
+	ListTags(ctx context.Context) ([]*models.TagUsage, error)
+	RenameTag(ctx context.Context, tag, newTag string) (*models.TagUsage, error)
+	MergeTags(ctx context.Context, tags []string, into string) (*models.TagUsage, error)
+	DeletePrice(ctx context.Context, id uuid.UUID, month time.Time) (*models.Subscription, error)
+	TrialsEnding(ctx context.Context, month time.Time, userIDs []uuid.UUID) ([]*models.Subscription, error)
 }
 
+// Repository хранит подписки вместе с тегами и историей цен. Ключ "tags"
+// в updates UpdateSubscription заменяет теги подписки целиком ([]string),
+// "price" - начальную цену; изменения цены задаются через SetPrice. Ключ
+// "promo" (*models.Promo, nil убирает промо) задает колонки promo_*.
 type Repository interface {
 	CreateSubscription(ctx context.Context, subscriptionData *models.Subscription) (*models.Subscription, error)
+	CopySubscriptions(ctx context.Context, subs []*models.Subscription) (int64, error)
 	UpdateSubscription(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*models.Subscription, error)
 	GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
-	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	DeleteSubscription(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
 	GetAllSubscriptions(ctx context.Context) ([]*models.Subscription, error)
+	ListSubscriptions(ctx context.Context, filter models.SubscriptionFilter) ([]*models.Subscription, error)
 	GetSumSubscriptions(ctx context.Context, startDate, endDate, name, usersIds string) (int, error)
+	GetSubscriptionStats(ctx context.Context, month time.Time) (*models.SubscriptionStats, error)
+	ListTags(ctx context.Context) ([]*models.TagUsage, error)
+	// SetPrice задает цену с месяца period.EffectiveFrom. Период не позже
+	// начала подписки заменяет начальную цену, более поздний добавляет или
+	// заменяет изменение с этого месяца.
+	SetPrice(ctx context.Context, id uuid.UUID, period models.PricePeriod) (*models.Subscription, error)
+	// DeletePrice отменяет изменение цены с месяца month.
+	DeletePrice(ctx context.Context, id uuid.UUID, month time.Time) (*models.Subscription, error)
+}
+
+// SplitRepository отдает правила разделения общих подписок для расчета
+// долей. Реализуется хранилищем домохозяйств.
+type SplitRepository interface {
+	// ListSplits возвращает правила домохозяйств, в которых состоит хотя бы
+	// один из userIDs; без userIDs - все правила.
+	ListSplits(ctx context.Context, userIDs []uuid.UUID) ([]*models.Split, error)
+}
+
+// ServiceCatalog связывает подписки с каталогом сервисов. Реализуется
+// хранилищем каталога; отсутствие сервиса - catalog.ErrNotFound.
+type ServiceCatalog interface {
+	GetServiceByID(ctx context.Context, id uuid.UUID) (*models.Service, error)
+	FindServiceByName(ctx context.Context, name string) (*models.Service, error)
 }
@@ -0,0 +1,352 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/cache"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"golang.org/x/sync/singleflight"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Теги, по которым инвалидируются записи кэша. Любое изменение подписки
+// поднимает версии ее пользователя, сервиса, самой подписки и tagAll.
+const (
+	tagAll          = "all"
+	tagUser         = "user:"
+	tagService      = "service:"
+	tagSubscription = "subscription:"
+)
+
+type CacheParams struct {
+	fx.In
+
+	Repo    subscriptions.Repository
+	Backend cache.Backend
+	Logger  *slog.Logger
+	Metrics *metrics.Metrics
+}
+
+// CachedRepository кэширует GetSubscriptionByID и агрегаты поверх любого
+// subscriptions.Repository. Одинаковые одновременные промахи выполняются
+// одним запросом. Ошибки кэша не ломают чтение: запрос уходит в хранилище.
+type CachedRepository struct {
+	subscriptions.Repository
+
+	backend cache.Backend
+	log     *slog.Logger
+	metrics *metrics.Metrics
+	group   singleflight.Group
+}
+
+// NewCachedRepository используется в fx.Decorate.
+func NewCachedRepository(params CacheParams) subscriptions.Repository {
+	return &CachedRepository{
+		Repository: params.Repo,
+		backend:    params.Backend,
+		log:        params.Logger,
+		metrics:    params.Metrics,
+	}
+}
+
+func (repo *CachedRepository) CreateSubscription(ctx context.Context, subscriptionData *models.Subscription) (*models.Subscription, error) {
+	created, err := repo.Repository.CreateSubscription(ctx, subscriptionData)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.invalidate(ctx, subscriptionTags(created))
+	return created, nil
+}
+
+func (repo *CachedRepository) CopySubscriptions(ctx context.Context, subs []*models.Subscription) (int64, error) {
+	copied, err := repo.Repository.CopySubscriptions(ctx, subs)
+	if err != nil {
+		return 0, err
+	}
+
+	var tags []string
+	for _, sub := range subs {
+		tags = append(tags, subscriptionTags(sub)...)
+	}
+	slices.Sort(tags)
+	repo.invalidate(ctx, slices.Compact(tags))
+
+	return copied, nil
+}
+
+func (repo *CachedRepository) UpdateSubscription(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*models.Subscription, error) {
+	// Смена пользователя или сервиса затрагивает и прежние теги.
+	var tags []string
+	_, userChanged := updates["user_id"]
+	_, serviceChanged := updates["service_name"]
+	if userChanged || serviceChanged {
+		if current, err := repo.Repository.GetSubscriptionByID(db.WithPrimary(ctx), id); err == nil {
+			tags = subscriptionTags(current)
+		}
+	}
+
+	updated, err := repo.Repository.UpdateSubscription(ctx, id, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.invalidate(ctx, append(tags, subscriptionTags(updated)...))
+	return updated, nil
+}
+
+func (repo *CachedRepository) SetPrice(ctx context.Context, id uuid.UUID, period models.PricePeriod) (*models.Subscription, error) {
+	updated, err := repo.Repository.SetPrice(ctx, id, period)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.invalidate(ctx, subscriptionTags(updated))
+	return updated, nil
+}
+
+func (repo *CachedRepository) DeletePrice(ctx context.Context, id uuid.UUID, month time.Time) (*models.Subscription, error) {
+	updated, err := repo.Repository.DeletePrice(ctx, id, month)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.invalidate(ctx, subscriptionTags(updated))
+	return updated, nil
+}
+
+func (repo *CachedRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	deleted, err := repo.Repository.DeleteSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.invalidate(ctx, subscriptionTags(deleted))
+	return deleted, nil
+}
+
+func (repo *CachedRepository) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	value, err := repo.load(ctx, "GetSubscriptionByID", "subscription:"+id.String(), []string{tagSubscription + id.String()},
+		func(ctx context.Context) ([]byte, error) {
+			sub, err := repo.Repository.GetSubscriptionByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(toCachedSubscription(sub))
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var cached cachedSubscription
+	if err := json.Unmarshal(value, &cached); err != nil {
+		return nil, err
+	}
+	return cached.subscription(), nil
+}
+
+func (repo *CachedRepository) GetSumSubscriptions(ctx context.Context, startDate, endDate, name, usersIds string) (int, error) {
+	var (
+		tags    []string
+		userIDs []string
+	)
+	if name != "" {
+		tags = append(tags, tagService+name)
+	}
+	for _, id := range models.ParseUserIDs(usersIds) {
+		userIDs = append(userIDs, id.String())
+		tags = append(tags, tagUser+id.String())
+	}
+	// Без фильтров сумма зависит от любой подписки.
+	if len(tags) == 0 {
+		tags = append(tags, tagAll)
+	}
+
+	slices.Sort(userIDs)
+	key := "sum:" + strings.Join([]string{startDate, endDate, name, strings.Join(userIDs, ",")}, "|")
+
+	value, err := repo.load(ctx, "GetSumSubscriptions", key, tags, func(ctx context.Context) ([]byte, error) {
+		sum, err := repo.Repository.GetSumSubscriptions(ctx, startDate, endDate, name, usersIds)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.AppendInt(nil, int64(sum), 10), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(string(value))
+}
+
+func (repo *CachedRepository) GetSubscriptionStats(ctx context.Context, month time.Time) (*models.SubscriptionStats, error) {
+	value, err := repo.load(ctx, "GetSubscriptionStats", "stats:"+month.Format("2006-01"), []string{tagAll},
+		func(ctx context.Context) ([]byte, error) {
+			stats, err := repo.Repository.GetSubscriptionStats(ctx, month)
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(stats)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.SubscriptionStats{}
+	if err := json.Unmarshal(value, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// load возвращает значение из кэша или вычисляет его через fetch. Ключ
+// дополняется версиями tags, поэтому после invalidate старая запись
+// больше не находится. Внутри транзакции кэш не используется: она может
+// видеть незафиксированные данные.
+func (repo *CachedRepository) load(ctx context.Context, method, key string, tags []string, fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if tx.Active(ctx) {
+		return fetch(ctx)
+	}
+
+	versions, err := repo.backend.Versions(ctx, tags...)
+	if err != nil {
+		repo.log.WarnContext(ctx, "cache versions: "+err.Error())
+		return fetch(ctx)
+	}
+
+	for _, version := range versions {
+		key += "@" + strconv.FormatUint(version, 10)
+	}
+
+	value, ok, err := repo.backend.Get(ctx, key)
+	if err != nil {
+		repo.log.WarnContext(ctx, "cache get: "+err.Error())
+	}
+	repo.metrics.ObserveCache(method, ok)
+	if ok {
+		return value, nil
+	}
+
+	// Результат получают все ожидающие запросы, поэтому отмена того,
+	// кто начал загрузку, не должна ее прерывать.
+	shared, err, _ := repo.group.Do(key, func() (interface{}, error) {
+		ctx := context.WithoutCancel(ctx)
+		value, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.backend.Set(ctx, key, value); err != nil {
+			repo.log.WarnContext(ctx, "cache set: "+err.Error())
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shared.([]byte), nil
+}
+
+// invalidate поднимает версии тегов после фиксации транзакции, иначе
+// параллельное чтение закэширует еще не измененные данные под новой версией.
+// Если кэш недоступен, устаревшие записи живут до истечения TTL.
+func (repo *CachedRepository) invalidate(ctx context.Context, tags []string) {
+	tx.AfterCommit(ctx, func(ctx context.Context) {
+		if err := repo.backend.Bump(ctx, tags...); err != nil {
+			repo.log.ErrorContext(ctx, "cache invalidate: "+err.Error())
+		}
+	})
+}
+
+func subscriptionTags(sub *models.Subscription) []string {
+	return []string{
+		tagAll,
+		tagUser + sub.UserID.String(),
+		tagService + sub.ServiceName,
+		tagSubscription + sub.ID.String(),
+	}
+}
+
+// cachedSubscription хранит даты целиком: JSON-формат MonthYear
+// отбрасывает день. Цена текущего месяца пересчитывается по истории
+// при чтении.
+type cachedSubscription struct {
+	ID          uuid.UUID            `json:"id"`
+	ServiceName string               `json:"service_name"`
+	ServiceID   *uuid.UUID           `json:"service_id"`
+	Category    string               `json:"category"`
+	Tags        []string             `json:"tags"`
+	Price       *int                 `json:"price"`
+	Prices      []models.PricePeriod `json:"prices"`
+	UserID      uuid.UUID            `json:"user_id"`
+	StartDate   time.Time            `json:"start_date"`
+	EndDate     *time.Time           `json:"end_date"`
+	TrialEnd    *time.Time           `json:"trial_end"`
+	Promo       *models.Promo        `json:"promo"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+func toCachedSubscription(sub *models.Subscription) cachedSubscription {
+	cached := cachedSubscription{
+		ID:          sub.ID,
+		ServiceName: sub.ServiceName,
+		ServiceID:   sub.ServiceID,
+		Category:    sub.Category,
+		Tags:        sub.Tags,
+		Price:       sub.Price,
+		Prices:      sub.Prices,
+		UserID:      sub.UserID,
+		StartDate:   sub.StartDate.Time(),
+		Promo:       sub.Promo,
+		CreatedAt:   sub.CreatedAt,
+		UpdatedAt:   sub.UpdatedAt,
+	}
+	if sub.EndDate != nil {
+		cached.EndDate = sub.EndDate.PtrTime()
+	}
+	if sub.TrialEnd != nil {
+		cached.TrialEnd = sub.TrialEnd.PtrTime()
+	}
+	return cached
+}
+
+func (c cachedSubscription) subscription() *models.Subscription {
+	sub := &models.Subscription{
+		ID:          c.ID,
+		ServiceName: c.ServiceName,
+		ServiceID:   c.ServiceID,
+		Category:    c.Category,
+		Tags:        tagsOrEmpty(c.Tags),
+		Price:       c.Price,
+		Prices:      c.Prices,
+		UserID:      c.UserID,
+		StartDate:   models.MonthYear(c.StartDate),
+		Promo:       c.Promo,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+	if c.EndDate != nil {
+		end := models.MonthYear(*c.EndDate)
+		sub.EndDate = &end
+	}
+	if c.TrialEnd != nil {
+		end := models.MonthYear(*c.TrialEnd)
+		sub.TrialEnd = &end
+	}
+	if len(sub.Prices) > 0 {
+		price := sub.PriceIn(time.Now())
+		sub.Price = &price
+	}
+	return sub
+}
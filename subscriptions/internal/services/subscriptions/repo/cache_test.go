@@ -0,0 +1,222 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/cache"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingBackend считает вызовы Bump.
+type countingBackend struct {
+	cache.Backend
+
+	mu    sync.Mutex
+	bumps int
+}
+
+func (b *countingBackend) Bump(ctx context.Context, tags ...string) error {
+	b.mu.Lock()
+	b.bumps++
+	b.mu.Unlock()
+	return b.Backend.Bump(ctx, tags...)
+}
+
+func (b *countingBackend) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.bumps
+}
+
+// fakeTx - транзакция без хранилища: MemoryRepository не узнает ее и
+// применяет изменения сразу, как база видит их до фиксации изнутри.
+type fakeTx struct{}
+
+func (fakeTx) Commit(context.Context) error   { return nil }
+func (fakeTx) Rollback(context.Context) error { return nil }
+
+func runFakeTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	begin := func(context.Context) (tx.Tx, error) { return fakeTx{}, nil }
+	return tx.Run(ctx, tx.Config{}, begin, func(error) bool { return false }, fn)
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func newTestCache(t *testing.T, repo subscriptions.Repository) (*CachedRepository, *countingBackend) {
+	t.Helper()
+	backend := &countingBackend{Backend: cache.NewMemory(100, time.Minute)}
+	cached := NewCachedRepository(CacheParams{
+		Repo:    repo,
+		Backend: backend,
+		Logger:  testLogger(),
+		Metrics: metrics.NewMetrics(),
+	})
+	return cached.(*CachedRepository), backend
+}
+
+func createTestSubscription(t *testing.T, repo subscriptions.Repository, price int) *models.Subscription {
+	t.Helper()
+	sub, err := repo.CreateSubscription(context.Background(), &models.Subscription{
+		ID:          uuid.New(),
+		ServiceName: "Yandex Plus",
+		Price:       &price,
+		UserID:      uuid.New(),
+		StartDate:   models.MonthYear(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+func TestCachedRepositoryInvalidatesAfterCommit(t *testing.T) {
+	tests := []struct {
+		name      string
+		fail      error
+		wantBumps int
+		wantPrice int
+	}{
+		{name: "фиксация", wantBumps: 1, wantPrice: 500},
+		{name: "откат", fail: errors.New("rollback"), wantBumps: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			memory := NewMemoryRepository(MemoryParams{Logger: testLogger()})
+			repo, backend := newTestCache(t, memory)
+			sub := createTestSubscription(t, memory, 399)
+
+			if _, err := repo.GetSubscriptionByID(ctx, sub.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			err := runFakeTx(ctx, func(txCtx context.Context) error {
+				if _, err := repo.UpdateSubscription(txCtx, sub.ID, map[string]interface{}{"price": 500}); err != nil {
+					return err
+				}
+				if backend.count() != 0 {
+					t.Error("cache invalidated before commit")
+				}
+
+				// Чтение вне транзакции до фиксации видит прежние данные и
+				// не должно закэшировать их под будущей версией.
+				outside, err := repo.GetSubscriptionByID(ctx, sub.ID)
+				if err != nil {
+					return err
+				}
+				if *outside.Price != 399 {
+					t.Errorf("price outside tx = %d, want cached 399", *outside.Price)
+				}
+				return tt.fail
+			})
+			if !errors.Is(err, tt.fail) {
+				t.Fatalf("tx error = %v, want %v", err, tt.fail)
+			}
+
+			if backend.count() != tt.wantBumps {
+				t.Errorf("bumps = %d, want %d", backend.count(), tt.wantBumps)
+			}
+			if tt.fail != nil {
+				// fakeTx не откатывает память: проверяется только кэш.
+				return
+			}
+			got, err := repo.GetSubscriptionByID(ctx, sub.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got.Price != tt.wantPrice {
+				t.Errorf("price after commit = %d, want %d", *got.Price, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestCachedRepositoryBypassesCacheInTx(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemoryRepository(MemoryParams{Logger: testLogger()})
+	repo, _ := newTestCache(t, memory)
+	sub := createTestSubscription(t, memory, 399)
+
+	if _, err := repo.GetSubscriptionByID(ctx, sub.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runFakeTx(ctx, func(ctx context.Context) error {
+		if _, err := repo.UpdateSubscription(ctx, sub.ID, map[string]interface{}{"price": 500}); err != nil {
+			return err
+		}
+		got, err := repo.GetSubscriptionByID(ctx, sub.ID)
+		if err != nil {
+			return err
+		}
+		if *got.Price != 500 {
+			t.Errorf("price inside tx = %d, want uncommitted 500", *got.Price)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// blockingRepository ждет release в GetSubscriptionByID и возвращает
+// ошибку контекста, если его отменили.
+type blockingRepository struct {
+	subscriptions.Repository
+
+	started chan struct{}
+	release chan struct{}
+	calls   int
+}
+
+func (repo *blockingRepository) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	repo.calls++
+	close(repo.started)
+	<-repo.release
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return repo.Repository.GetSubscriptionByID(ctx, id)
+}
+
+func TestCachedRepositoryLoadIgnoresCallerCancel(t *testing.T) {
+	memory := NewMemoryRepository(MemoryParams{Logger: testLogger()})
+	sub := createTestSubscription(t, memory, 399)
+	blocking := &blockingRepository{Repository: memory, started: make(chan struct{}), release: make(chan struct{})}
+	repo, _ := newTestCache(t, blocking)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := repo.GetSubscriptionByID(ctx, sub.ID)
+		done <- err
+	}()
+
+	<-blocking.started
+	cancel()
+	close(blocking.release)
+	if err := <-done; err != nil {
+		t.Fatalf("GetSubscriptionByID after cancel: %v", err)
+	}
+
+	// Загруженное значение попало в кэш: хранилище больше не вызывается.
+	got, err := repo.GetSubscriptionByID(context.Background(), sub.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != sub.ID || blocking.calls != 1 {
+		t.Errorf("got %s after %d calls, want cached %s", got.ID, blocking.calls, sub.ID)
+	}
+}
@@ -0,0 +1,83 @@
+package repo
+
+import "strings"
+
+// dialect - различия Postgres и SQLite в арифметике дат. Выражения цены и
+// суммы к оплате строятся по нему одинаково для обоих хранилищ и повторяют
+// методы models.Subscription.
+type dialect struct {
+	// addMonths прибавляет к дате date n месяцев, n - SQL-выражение.
+	addMonths func(date, n string) string
+	// greatest возвращает наибольшую из дат. first не бывает NULL, NULL
+	// среди остальных пропускаются.
+	greatest func(first string, rest ...string) string
+}
+
+var postgresDialect = dialect{
+	addMonths: func(date, n string) string {
+		return "(" + date + " + " + n + " * interval '1 month')::date"
+	},
+	greatest: func(first string, rest ...string) string {
+		return "GREATEST(" + strings.Join(append([]string{first}, rest...), ", ") + ")"
+	},
+}
+
+// В SQLite даты хранятся текстом YYYY-MM-DD: месяцы прибавляет date(), а
+// скалярный MAX сравнивает строки и возвращает NULL, если NULL хотя бы один
+// аргумент.
+var sqliteDialect = dialect{
+	addMonths: func(date, n string) string {
+		return "date(" + date + ", '+' || " + n + " || ' months')"
+	},
+	greatest: func(first string, rest ...string) string {
+		args := []string{first}
+		for _, date := range rest {
+			args = append(args, "COALESCE("+date+", "+first+")")
+		}
+		return "MAX(" + strings.Join(args, ", ") + ")"
+	},
+}
+
+// priceIn - цена подписки в месяце month, как models.Subscription.PriceIn:
+// последнее изменение не позже месяца (или начала подписки), иначе
+// начальная цена.
+func (d dialect) priceIn(month string) string {
+	return "COALESCE((SELECT p.price FROM subscription_prices p WHERE p.subscription_id = subscriptions.id" +
+		" AND p.effective_from <= " + d.greatest(month, "subscriptions.start_date") +
+		" ORDER BY p.effective_from DESC LIMIT 1), subscriptions.price)"
+}
+
+// chargeIn - сумма к оплате в месяце month, как models.Subscription.ChargeIn.
+// month входит в выражение несколько раз.
+func (d dialect) chargeIn(month string) string {
+	price := d.priceIn(month)
+	promoFrom := d.promoFrom()
+	return "CASE WHEN subscriptions.trial_end >= " + month + " THEN 0" +
+		" WHEN subscriptions.promo_type IS NOT NULL AND " + month + " >= " + promoFrom +
+		" AND " + month + " < " + d.addMonths(promoFrom, "subscriptions.promo_months") +
+		" THEN CASE subscriptions.promo_type WHEN 'fixed' THEN subscriptions.promo_value" +
+		" ELSE " + price + " * (100 - subscriptions.promo_value) / 100 END" +
+		" ELSE " + price + " END"
+}
+
+// reportMonth - месяц models.Subscription.ReportMonth. Без пробного и
+// промо-периода и истории цен соответствующие даты NULL и не учитываются.
+func (d dialect) reportMonth() string {
+	return "COALESCE(subscriptions.end_date, " + d.greatest("subscriptions.start_date",
+		d.addMonths("subscriptions.trial_end", "1"),
+		d.addMonths(d.promoFrom(), "subscriptions.promo_months"),
+		"(SELECT MAX(p.effective_from) FROM subscription_prices p WHERE p.subscription_id = subscriptions.id)",
+	) + ")"
+}
+
+// reportPrice - сумма, с которой подписка входит в суммарную стоимость,
+// как models.Subscription.ReportPrice.
+func (d dialect) reportPrice() string {
+	return d.chargeIn(d.reportMonth())
+}
+
+// promoFrom - первый месяц промо-периода: после пробного или с начала
+// подписки.
+func (d dialect) promoFrom() string {
+	return "COALESCE(" + d.addMonths("subscriptions.trial_end", "1") + ", subscriptions.start_date)"
+}
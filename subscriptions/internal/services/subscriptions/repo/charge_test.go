@@ -0,0 +1,109 @@
+package repo
+
+import (
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+// TestChargeInParity сверяет сумму к оплате по месяцам в сводке каждого
+// хранилища с models.Subscription.ChargeIn: SQL-выражения dialect.chargeIn
+// должны повторять ее, включая целочисленное округление процента.
+func TestChargeInParity(t *testing.T) {
+	tests := []struct {
+		name   string
+		sub    models.Subscription
+		prices []models.PricePeriod
+		// want - сумма к оплате по месяцам начиная с 01-2025.
+		want []int
+	}{
+		{
+			name: "без скидок",
+			sub:  models.Subscription{Price: pricePtr(1000)},
+			want: []int{1000, 1000},
+		},
+		{
+			name: "пробный период",
+			sub:  models.Subscription{Price: pricePtr(1000), TrialEnd: monthPtr(2025, time.February)},
+			want: []int{0, 0, 1000},
+		},
+		{
+			name: "процентное промо",
+			sub:  models.Subscription{Price: pricePtr(1000), Promo: &models.Promo{Type: models.PromoPercentage, Value: 50, Months: 2}},
+			want: []int{500, 500, 1000},
+		},
+		{
+			name: "фиксированное промо",
+			sub:  models.Subscription{Price: pricePtr(1000), Promo: &models.Promo{Type: models.PromoFixed, Value: 199, Months: 1}},
+			want: []int{199, 1000},
+		},
+		{
+			name: "промо после пробного периода",
+			sub: models.Subscription{Price: pricePtr(1000), TrialEnd: monthPtr(2025, time.January),
+				Promo: &models.Promo{Type: models.PromoPercentage, Value: 25, Months: 2}},
+			want: []int{0, 750, 750, 1000},
+		},
+		{
+			name: "процент округляется вниз",
+			sub:  models.Subscription{Price: pricePtr(999), Promo: &models.Promo{Type: models.PromoPercentage, Value: 33, Months: 1}},
+			want: []int{669, 999},
+		},
+		{
+			name: "скидка меньше рубля",
+			sub:  models.Subscription{Price: pricePtr(3), Promo: &models.Promo{Type: models.PromoPercentage, Value: 50, Months: 1}},
+			want: []int{1, 3},
+		},
+		{
+			name:   "изменение цены",
+			sub:    models.Subscription{Price: pricePtr(500)},
+			prices: []models.PricePeriod{{EffectiveFrom: monthOf(2025, time.March), Price: 700}},
+			want:   []int{500, 500, 700},
+		},
+		{
+			name:   "замена начальной цены",
+			sub:    models.Subscription{Price: pricePtr(500)},
+			prices: []models.PricePeriod{{EffectiveFrom: monthOf(2025, time.January), Price: 600}},
+			want:   []int{600, 600},
+		},
+		{
+			name:   "промо от измененной цены",
+			sub:    models.Subscription{Price: pricePtr(500), Promo: &models.Promo{Type: models.PromoPercentage, Value: 10, Months: 3}},
+			prices: []models.PricePeriod{{EffectiveFrom: monthOf(2025, time.February), Price: 999}},
+			want:   []int{450, 899, 899, 999},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachStorage(t, func(t *testing.T, s storage) {
+				ctx := context.Background()
+				sub := tt.sub
+				sub.ServiceName, sub.UserID, sub.StartDate = "Netflix", uuid.New(), monthOf(2025, time.January)
+				s.addUser(t, sub.UserID)
+				created := createSubscription(t, s.repo, &sub)
+				for _, period := range tt.prices {
+					var err error
+					if created, err = s.repo.SetPrice(ctx, created.ID, period); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				for i, want := range tt.want {
+					month := time.Date(2025, time.January+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+					if got := created.ChargeIn(month); got != want {
+						t.Errorf("ChargeIn(%s) = %d, want %d", month.Format("01-2006"), got, want)
+					}
+					stats, err := s.repo.GetSubscriptionStats(ctx, month)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if stats.MonthlySpend != want {
+						t.Errorf("monthly spend in %s = %d, want %d", month.Format("01-2006"), stats.MonthlySpend, want)
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,629 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/migrations"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/pkg/builder"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx/fxtest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// postgresDSNEnv - база для контрактных тестов Postgres. Тесты очищают ее
+// таблицы, поэтому база должна быть отдельной.
+const postgresDSNEnv = "TEST_POSTGRES_DSN"
+
+// storage - хранилище, на котором проверяется контракт Repository.
+// Подписки ссылаются на пользователей, addUser заводит пользователя.
+type storage struct {
+	repo    subscriptions.Repository
+	addUser func(t *testing.T, id uuid.UUID)
+}
+
+// testStorages перечисляет хранилища контракта. Каждое создается заново
+// для каждого теста.
+var testStorages = []struct {
+	name string
+	open func(t *testing.T) storage
+}{
+	{name: "memory", open: openMemory},
+	{name: "sqlite", open: openSQLite},
+	{name: "postgres", open: openPostgres},
+}
+
+// forEachStorage выполняет test на каждом хранилище с пустыми данными.
+func forEachStorage(t *testing.T, test func(t *testing.T, s storage)) {
+	for _, ts := range testStorages {
+		t.Run(ts.name, func(t *testing.T) {
+			test(t, ts.open(t))
+		})
+	}
+}
+
+// userSet - UserChecker памяти с пользователями, заведенными в тесте.
+type userSet map[uuid.UUID]bool
+
+func (users userSet) UserExists(id uuid.UUID) bool {
+	return users[id]
+}
+
+func openMemory(t *testing.T) storage {
+	users := userSet{}
+	return storage{
+		repo:    NewMemoryRepository(MemoryParams{Logger: testLogger(), Users: users}),
+		addUser: func(_ *testing.T, id uuid.UUID) { users[id] = true },
+	}
+}
+
+func openSQLite(t *testing.T) storage {
+	lifecycle := fxtest.NewLifecycle(t)
+	sqliteDB, err := db.NewSQLite(db.SQLiteParams{
+		Lifecycle: lifecycle,
+		Cfg:       db.SQLiteConfig{Path: filepath.Join(t.TempDir(), "subscriptions.db"), BusyTimeout: time.Second},
+		Logger:    testLogger(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(lifecycle.RequireStop)
+
+	migrator, err := migrations.NewSQLite(sqliteDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	return storage{
+		repo: NewSQLiteRepository(SQLiteParams{Logger: testLogger(), DB: sqliteDB, Metrics: metrics.NewMetrics()}),
+		addUser: func(t *testing.T, id uuid.UUID) {
+			if _, err := sqliteDB.Exec("INSERT INTO users (id) VALUES (?)", id.String()); err != nil {
+				t.Fatal(err)
+			}
+		},
+	}
+}
+
+func openPostgres(t *testing.T) storage {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skip(postgresDSNEnv + " is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrator, err := migrations.New(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, "TRUNCATE subscriptions, subscription_prices, users CASCADE"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Без реплик кластеру не нужны Lifecycle и конфиг.
+	cluster, err := db.NewCluster(db.ClusterParams{Primary: pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return storage{
+		repo: NewRepository(Params{
+			Logger:  testLogger(),
+			Cluster: cluster,
+			Builder: builder.SetupBuilder(),
+			Metrics: metrics.NewMetrics(),
+		}),
+		addUser: func(t *testing.T, id uuid.UUID) {
+			if _, err := pool.Exec(ctx, "INSERT INTO users (id) VALUES ($1)", id); err != nil {
+				t.Fatal(err)
+			}
+		},
+	}
+}
+
+func monthOf(year int, month time.Month) models.MonthYear {
+	return models.MonthYear(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func monthPtr(year int, month time.Month) *models.MonthYear {
+	m := monthOf(year, month)
+	return &m
+}
+
+func pricePtr(price int) *int {
+	return &price
+}
+
+// fixture - подписки двух пользователей для проверки фильтров, сумм и
+// сводки:
+//
+//	netflixA  A  Netflix  500  01-2025..06-2025  streaming  видео
+//	spotifyA  A  Spotify  300  03-2025..         music
+//	netflixB  B  Netflix  700  02-2025..12-2025  streaming  видео, семья
+type fixture struct {
+	userA, userB                 uuid.UUID
+	netflixA, spotifyA, netflixB *models.Subscription
+}
+
+func newFixture(t *testing.T, s storage) fixture {
+	t.Helper()
+	f := fixture{userA: uuid.New(), userB: uuid.New()}
+	s.addUser(t, f.userA)
+	s.addUser(t, f.userB)
+
+	f.netflixA = createSubscription(t, s.repo, &models.Subscription{
+		ServiceName: "Netflix", Category: "streaming", Tags: []string{"видео"}, Price: pricePtr(500),
+		UserID: f.userA, StartDate: monthOf(2025, time.January), EndDate: monthPtr(2025, time.June),
+	})
+	f.spotifyA = createSubscription(t, s.repo, &models.Subscription{
+		ServiceName: "Spotify", Category: "music", Price: pricePtr(300),
+		UserID: f.userA, StartDate: monthOf(2025, time.March),
+	})
+	f.netflixB = createSubscription(t, s.repo, &models.Subscription{
+		ServiceName: "Netflix", Category: "streaming", Tags: []string{"видео", "семья"}, Price: pricePtr(700),
+		UserID: f.userB, StartDate: monthOf(2025, time.February), EndDate: monthPtr(2025, time.December),
+	})
+	return f
+}
+
+func createSubscription(t *testing.T, repo subscriptions.Repository, sub *models.Subscription) *models.Subscription {
+	t.Helper()
+	sub.ID = uuid.New()
+	if sub.Tags == nil {
+		sub.Tags = []string{}
+	}
+	created, err := repo.CreateSubscription(context.Background(), sub)
+	if err != nil {
+		t.Fatalf("create %s: %v", sub.ServiceName, err)
+	}
+	return created
+}
+
+// sortedIDs возвращает ID подписок в порядке Postgres.
+func sortedIDs(subs ...*models.Subscription) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(subs))
+	for _, sub := range subs {
+		ids = append(ids, sub.ID)
+	}
+	slices.SortFunc(ids, compareUUID)
+	return ids
+}
+
+func TestRepositoryCRUD(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, s storage) {
+		ctx := context.Background()
+		user := uuid.New()
+		s.addUser(t, user)
+
+		created := createSubscription(t, s.repo, &models.Subscription{
+			ServiceName: "Yandex Plus", Category: "streaming", Tags: []string{"семья"}, Price: pricePtr(399),
+			UserID: user, StartDate: monthOf(2025, time.January),
+		})
+		if created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() {
+			t.Errorf("created without timestamps: %+v", created)
+		}
+
+		got, err := s.repo.GetSubscriptionByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ServiceName != "Yandex Plus" || got.Category != "streaming" || !slices.Equal(got.Tags, []string{"семья"}) ||
+			*got.Price != 399 || got.UserID != user || !got.StartDate.Time().Equal(monthOf(2025, time.January).Time()) || got.EndDate != nil {
+			t.Errorf("GetSubscriptionByID = %+v", got)
+		}
+
+		updated, err := s.repo.UpdateSubscription(ctx, created.ID, map[string]interface{}{
+			"service_name": "Кинопоиск",
+			"end_date":     monthOf(2025, time.May).Time(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.ServiceName != "Кинопоиск" || updated.EndDate == nil || !updated.EndDate.Time().Equal(monthOf(2025, time.May).Time()) {
+			t.Errorf("UpdateSubscription = %+v", updated)
+		}
+		if updated.UpdatedAt.Before(created.UpdatedAt) {
+			t.Errorf("updated_at went back: %s < %s", updated.UpdatedAt, created.UpdatedAt)
+		}
+
+		deleted, err := s.repo.DeleteSubscription(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted.ID != created.ID || deleted.ServiceName != "Кинопоиск" {
+			t.Errorf("DeleteSubscription = %+v", deleted)
+		}
+		if _, err := s.repo.GetSubscriptionByID(ctx, created.ID); !errors.Is(err, subscriptions.ErrNotFound) {
+			t.Errorf("GetSubscriptionByID after delete: %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestRepositoryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(ctx context.Context, repo subscriptions.Repository, existing *models.Subscription) error
+		want error
+	}{
+		{
+			name: "повторный id",
+			call: func(ctx context.Context, repo subscriptions.Repository, existing *models.Subscription) error {
+				_, err := repo.CreateSubscription(ctx, existing)
+				return err
+			},
+			want: subscriptions.ErrAlreadyExists,
+		},
+		{
+			name: "неизвестный пользователь",
+			call: func(ctx context.Context, repo subscriptions.Repository, existing *models.Subscription) error {
+				sub := *existing
+				sub.ID, sub.UserID = uuid.New(), uuid.New()
+				_, err := repo.CreateSubscription(ctx, &sub)
+				return err
+			},
+			want: subscriptions.ErrUserNotFound,
+		},
+		{
+			name: "отрицательная цена",
+			call: func(ctx context.Context, repo subscriptions.Repository, existing *models.Subscription) error {
+				sub := *existing
+				sub.ID, sub.Price = uuid.New(), pricePtr(-1)
+				_, err := repo.CreateSubscription(ctx, &sub)
+				return err
+			},
+			want: subscriptions.ErrNegativePrice,
+		},
+		{
+			name: "окончание раньше начала",
+			call: func(ctx context.Context, repo subscriptions.Repository, existing *models.Subscription) error {
+				_, err := repo.UpdateSubscription(ctx, existing.ID, map[string]interface{}{"end_date": monthOf(2024, time.December).Time()})
+				return err
+			},
+			want: subscriptions.ErrEndBeforeStart,
+		},
+		{
+			name: "изменение без полей",
+			call: func(ctx context.Context, repo subscriptions.Repository, existing *models.Subscription) error {
+				_, err := repo.UpdateSubscription(ctx, existing.ID, map[string]interface{}{})
+				return err
+			},
+			want: subscriptions.ErrNoFieldsToUpdate,
+		},
+		{
+			name: "изменение несуществующей",
+			call: func(ctx context.Context, repo subscriptions.Repository, _ *models.Subscription) error {
+				_, err := repo.UpdateSubscription(ctx, uuid.New(), map[string]interface{}{"service_name": "Netflix"})
+				return err
+			},
+			want: subscriptions.ErrNotFound,
+		},
+		{
+			name: "чтение несуществующей",
+			call: func(ctx context.Context, repo subscriptions.Repository, _ *models.Subscription) error {
+				_, err := repo.GetSubscriptionByID(ctx, uuid.New())
+				return err
+			},
+			want: subscriptions.ErrNotFound,
+		},
+		{
+			name: "удаление несуществующей",
+			call: func(ctx context.Context, repo subscriptions.Repository, _ *models.Subscription) error {
+				_, err := repo.DeleteSubscription(ctx, uuid.New())
+				return err
+			},
+			want: subscriptions.ErrNotFound,
+		},
+	}
+
+	forEachStorage(t, func(t *testing.T, s storage) {
+		f := newFixture(t, s)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := tt.call(context.Background(), s.repo, f.netflixA); !errors.Is(err, tt.want) {
+					t.Errorf("error = %v, want %v", err, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestRepositoryListSubscriptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter func(f fixture) models.SubscriptionFilter
+		want   func(f fixture) []*models.Subscription
+	}{
+		{
+			name:   "без фильтров",
+			filter: func(fixture) models.SubscriptionFilter { return models.SubscriptionFilter{} },
+			want: func(f fixture) []*models.Subscription {
+				return []*models.Subscription{f.netflixA, f.spotifyA, f.netflixB}
+			},
+		},
+		{
+			name: "по пользователю",
+			filter: func(f fixture) models.SubscriptionFilter {
+				return models.SubscriptionFilter{UserIDs: []uuid.UUID{f.userA}}
+			},
+			want: func(f fixture) []*models.Subscription { return []*models.Subscription{f.netflixA, f.spotifyA} },
+		},
+		{
+			name:   "по сервису",
+			filter: func(fixture) models.SubscriptionFilter { return models.SubscriptionFilter{ServiceName: "Netflix"} },
+			want:   func(f fixture) []*models.Subscription { return []*models.Subscription{f.netflixA, f.netflixB} },
+		},
+		{
+			name:   "по категории",
+			filter: func(fixture) models.SubscriptionFilter { return models.SubscriptionFilter{Category: "music"} },
+			want:   func(f fixture) []*models.Subscription { return []*models.Subscription{f.spotifyA} },
+		},
+		{
+			name:   "по тегу",
+			filter: func(fixture) models.SubscriptionFilter { return models.SubscriptionFilter{Tag: "семья"} },
+			want:   func(f fixture) []*models.Subscription { return []*models.Subscription{f.netflixB} },
+		},
+		{
+			name: "по id",
+			filter: func(f fixture) models.SubscriptionFilter {
+				return models.SubscriptionFilter{IDs: []uuid.UUID{f.spotifyA.ID, f.netflixB.ID}}
+			},
+			want: func(f fixture) []*models.Subscription { return []*models.Subscription{f.spotifyA, f.netflixB} },
+		},
+		{
+			name: "фильтры вместе",
+			filter: func(f fixture) models.SubscriptionFilter {
+				return models.SubscriptionFilter{UserIDs: []uuid.UUID{f.userB}, ServiceName: "Spotify"}
+			},
+			want: func(fixture) []*models.Subscription { return nil },
+		},
+	}
+
+	forEachStorage(t, func(t *testing.T, s storage) {
+		f := newFixture(t, s)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := s.repo.ListSubscriptions(context.Background(), tt.filter(f))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ids, want := sortedIDs(got...), sortedIDs(tt.want(f)...); !slices.Equal(ids, want) {
+					t.Errorf("ids = %v, want %v", ids, want)
+				}
+				if !slices.IsSortedFunc(got, func(a, b *models.Subscription) int { return compareUUID(a.ID, b.ID) }) {
+					t.Error("subscriptions are not sorted by id")
+				}
+			})
+		}
+	})
+}
+
+func TestRepositoryPagination(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, s storage) {
+		ctx := context.Background()
+		user := uuid.New()
+		s.addUser(t, user)
+
+		var all []*models.Subscription
+		for range 5 {
+			all = append(all, createSubscription(t, s.repo, &models.Subscription{
+				ServiceName: "Netflix", Price: pricePtr(500), UserID: user, StartDate: monthOf(2025, time.January),
+			}))
+		}
+
+		var (
+			pages [][]uuid.UUID
+			seen  []uuid.UUID
+			after uuid.UUID
+		)
+		for {
+			page, err := s.repo.ListSubscriptions(ctx, models.SubscriptionFilter{AfterID: after, Limit: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			ids := sortedIDs(page...)
+			pages = append(pages, ids)
+			seen = append(seen, ids...)
+			after = page[len(page)-1].ID
+		}
+
+		if len(pages) != 3 || len(pages[0]) != 2 || len(pages[2]) != 1 {
+			t.Errorf("pages = %v, want sizes 2, 2, 1", pages)
+		}
+		if want := sortedIDs(all...); !slices.Equal(seen, want) {
+			t.Errorf("paged ids = %v, want %v", seen, want)
+		}
+	})
+}
+
+func TestRepositorySum(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		service    string
+		users      func(f fixture) string
+		want       int
+	}{
+		{name: "все подписки", want: 1500},
+		{name: "по сервису", service: "Netflix", want: 1200},
+		{name: "по пользователю", users: func(f fixture) string { return f.userA.String() }, want: 800},
+		{name: "по двум пользователям", users: func(f fixture) string { return f.userA.String() + ", " + f.userB.String() }, want: 1500},
+		{name: "неверные id не фильтруют", users: func(fixture) string { return "not-a-uuid" }, want: 1500},
+		{name: "с начала периода", start: "02-2025", want: 1000},
+		{name: "бессрочная не входит в период с концом", end: "06-2025", want: 500},
+		{name: "период целиком", start: "01-2025", end: "12-2025", want: 1200},
+		{name: "неверная граница не ограничивает", start: "2025-01", want: 1500},
+		{name: "пустой период", start: "01-2026", end: "12-2026", want: 0},
+	}
+
+	forEachStorage(t, func(t *testing.T, s storage) {
+		f := newFixture(t, s)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var users string
+				if tt.users != nil {
+					users = tt.users(f)
+				}
+				got, err := s.repo.GetSumSubscriptions(context.Background(), tt.start, tt.end, tt.service, users)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tt.want {
+					t.Errorf("sum = %d, want %d", got, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestRepositoryStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		month models.MonthYear
+		want  models.SubscriptionStats
+	}{
+		{name: "до всех подписок", month: monthOf(2024, time.December), want: models.SubscriptionStats{}},
+		{name: "первый месяц", month: monthOf(2025, time.January), want: models.SubscriptionStats{Active: 1, MonthlySpend: 500}},
+		{name: "все активны", month: monthOf(2025, time.March), want: models.SubscriptionStats{Active: 3, MonthlySpend: 1500}},
+		{name: "последний месяц включается", month: monthOf(2025, time.June), want: models.SubscriptionStats{Active: 3, MonthlySpend: 1500}},
+		{name: "после окончания", month: monthOf(2025, time.July), want: models.SubscriptionStats{Active: 2, MonthlySpend: 1000}},
+		{name: "только бессрочная", month: monthOf(2026, time.January), want: models.SubscriptionStats{Active: 1, MonthlySpend: 300}},
+	}
+
+	forEachStorage(t, func(t *testing.T, s storage) {
+		newFixture(t, s)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := s.repo.GetSubscriptionStats(context.Background(), tt.month.Time())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if *got != tt.want {
+					t.Errorf("stats = %+v, want %+v", *got, tt.want)
+				}
+			})
+		}
+	})
+}
+
+// TestRepositorySumCharges проверяет, что подписка входит в сумму с оплатой
+// за models.Subscription.ReportMonth с учетом пробного периода, промо и
+// истории цен. Каждый случай - подписка отдельного пользователя.
+func TestRepositorySumCharges(t *testing.T) {
+	tests := []struct {
+		name   string
+		sub    models.Subscription
+		prices []models.PricePeriod
+		want   int
+	}{
+		{
+			name: "последний месяц пробный",
+			sub:  models.Subscription{Price: pricePtr(1000), EndDate: monthPtr(2025, time.March), TrialEnd: monthPtr(2025, time.March)},
+			want: 0,
+		},
+		{
+			name: "бессрочная после пробного периода",
+			sub:  models.Subscription{Price: pricePtr(1000), TrialEnd: monthPtr(2025, time.March)},
+			want: 1000,
+		},
+		{
+			name: "последний месяц по промо",
+			sub:  models.Subscription{Price: pricePtr(1000), EndDate: monthPtr(2025, time.February), Promo: &models.Promo{Type: models.PromoPercentage, Value: 50, Months: 3}},
+			want: 500,
+		},
+		{
+			name: "фиксированное промо",
+			sub:  models.Subscription{Price: pricePtr(1000), EndDate: monthPtr(2025, time.February), Promo: &models.Promo{Type: models.PromoFixed, Value: 99, Months: 3}},
+			want: 99,
+		},
+		{
+			name: "промо после пробного периода",
+			sub: models.Subscription{Price: pricePtr(1000), EndDate: monthPtr(2025, time.May), TrialEnd: monthPtr(2025, time.February),
+				Promo: &models.Promo{Type: models.PromoPercentage, Value: 50, Months: 3}},
+			want: 500,
+		},
+		{
+			name: "процент округляется вниз",
+			sub:  models.Subscription{Price: pricePtr(999), EndDate: monthPtr(2025, time.January), Promo: &models.Promo{Type: models.PromoPercentage, Value: 33, Months: 1}},
+			want: 669,
+		},
+		{
+			name: "бессрочная после промо",
+			sub:  models.Subscription{Price: pricePtr(1000), Promo: &models.Promo{Type: models.PromoPercentage, Value: 50, Months: 3}},
+			want: 1000,
+		},
+		{
+			name:   "последняя цена до окончания",
+			sub:    models.Subscription{Price: pricePtr(500), EndDate: monthPtr(2025, time.December)},
+			prices: []models.PricePeriod{{EffectiveFrom: monthOf(2025, time.March), Price: 700}, {EffectiveFrom: monthOf(2025, time.June), Price: 900}},
+			want:   900,
+		},
+		{
+			name:   "замена начальной цены",
+			sub:    models.Subscription{Price: pricePtr(500), EndDate: monthPtr(2025, time.March)},
+			prices: []models.PricePeriod{{EffectiveFrom: monthOf(2025, time.January), Price: 600}},
+			want:   600,
+		},
+		{
+			name:   "бессрочная с запланированной ценой",
+			sub:    models.Subscription{Price: pricePtr(500)},
+			prices: []models.PricePeriod{{EffectiveFrom: monthOf(2040, time.May), Price: 1500}},
+			want:   1500,
+		},
+		{
+			name:   "промо от измененной цены",
+			sub:    models.Subscription{Price: pricePtr(500), EndDate: monthPtr(2025, time.February), Promo: &models.Promo{Type: models.PromoPercentage, Value: 50, Months: 2}},
+			prices: []models.PricePeriod{{EffectiveFrom: monthOf(2025, time.February), Price: 800}},
+			want:   400,
+		},
+	}
+
+	forEachStorage(t, func(t *testing.T, s storage) {
+		ctx := context.Background()
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				sub := tt.sub
+				sub.ServiceName, sub.UserID, sub.StartDate = "Netflix", uuid.New(), monthOf(2025, time.January)
+				s.addUser(t, sub.UserID)
+				created := createSubscription(t, s.repo, &sub)
+				for _, period := range tt.prices {
+					if _, err := s.repo.SetPrice(ctx, created.ID, period); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				got, err := s.repo.GetSumSubscriptions(ctx, "", "", "", sub.UserID.String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tt.want {
+					t.Errorf("sum = %d, want %d", got, tt.want)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,584 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// UserChecker заменяет внешний ключ subscriptions.user_id в памяти.
+type UserChecker interface {
+	UserExists(id uuid.UUID) bool
+}
+
+type MemoryParams struct {
+	fx.In
+
+	Logger *slog.Logger
+	Users  UserChecker `optional:"true"`
+}
+
+// MemoryRepository хранит подписки в памяти процесса и повторяет поведение
+// Repository: те же ошибки, ограничения и фильтры. Подходит для локального
+// запуска без базы и для тестов. Как и в таблице, Price хранимой подписки -
+// начальная цена, а Prices - только изменения цены.
+type MemoryRepository struct {
+	log   *slog.Logger
+	users UserChecker
+
+	mu   sync.RWMutex
+	subs map[uuid.UUID]*models.Subscription
+}
+
+func NewMemoryRepository(params MemoryParams) *MemoryRepository {
+	return &MemoryRepository{
+		log:   params.Logger,
+		users: params.Users,
+		subs:  make(map[uuid.UUID]*models.Subscription),
+	}
+}
+
+func (repo *MemoryRepository) CreateSubscription(ctx context.Context, subscriptionData *models.Subscription) (*models.Subscription, error) {
+	sub := cloneSubscription(subscriptionData)
+	sub.Prices = nil
+	if err := validateStored(sub); err != nil {
+		repo.log.WarnContext(ctx, "create subscription: "+err.Error())
+		return nil, err
+	}
+
+	defer repo.lock(ctx)()
+
+	if _, ok := repo.subs[sub.ID]; ok {
+		repo.log.WarnContext(ctx, "subscription with this id already exists")
+		return nil, subscriptions.ErrAlreadyExists
+	}
+	if !repo.userExists(sub.UserID) {
+		repo.log.WarnContext(ctx, "create subscription: user not found")
+		return nil, subscriptions.ErrUserNotFound
+	}
+
+	now := time.Now().UTC()
+	sub.CreatedAt, sub.UpdatedAt = now, now
+	repo.subs[sub.ID] = sub
+
+	return view(sub), nil
+}
+
+// CopySubscriptions вставляет все подписки или ни одной, как COPY.
+func (repo *MemoryRepository) CopySubscriptions(ctx context.Context, subs []*models.Subscription) (int64, error) {
+	batch := make([]*models.Subscription, 0, len(subs))
+	seen := make(map[uuid.UUID]struct{}, len(subs))
+	for _, s := range subs {
+		sub := cloneSubscription(s)
+		sub.Prices = nil
+		if err := validateStored(sub); err != nil {
+			return 0, err
+		}
+		if _, ok := seen[sub.ID]; ok {
+			return 0, subscriptions.ErrAlreadyExists
+		}
+		seen[sub.ID] = struct{}{}
+		batch = append(batch, sub)
+	}
+
+	defer repo.lock(ctx)()
+
+	for _, sub := range batch {
+		if _, ok := repo.subs[sub.ID]; ok {
+			return 0, subscriptions.ErrAlreadyExists
+		}
+		if !repo.userExists(sub.UserID) {
+			return 0, subscriptions.ErrUserNotFound
+		}
+	}
+
+	now := time.Now().UTC()
+	for _, sub := range batch {
+		sub.CreatedAt, sub.UpdatedAt = now, now
+		repo.subs[sub.ID] = sub
+	}
+
+	return int64(len(batch)), nil
+}
+
+func (repo *MemoryRepository) UpdateSubscription(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*models.Subscription, error) {
+	if len(updates) == 0 {
+		return nil, subscriptions.ErrNoFieldsToUpdate
+	}
+
+	defer repo.lock(ctx)()
+
+	current, ok := repo.subs[id]
+	if !ok {
+		repo.log.WarnContext(ctx, "subscription not found for update")
+		return nil, subscriptions.ErrNotFound
+	}
+
+	updated := cloneSubscription(current)
+	for field, value := range updates {
+		if err := applyUpdate(updated, field, value); err != nil {
+			repo.log.WarnContext(ctx, "update subscription: "+err.Error())
+			return nil, err
+		}
+	}
+	if err := validateStored(updated); err != nil {
+		repo.log.WarnContext(ctx, "update subscription: "+err.Error())
+		return nil, err
+	}
+	if updated.UserID != current.UserID && !repo.userExists(updated.UserID) {
+		repo.log.WarnContext(ctx, "update subscription: user not found")
+		return nil, subscriptions.ErrUserNotFound
+	}
+
+	updated.UpdatedAt = time.Now().UTC()
+	repo.subs[id] = updated
+
+	return view(updated), nil
+}
+
+func (repo *MemoryRepository) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	defer repo.rlock(ctx)()
+
+	sub, ok := repo.subs[id]
+	if !ok {
+		return nil, subscriptions.ErrNotFound
+	}
+	return view(sub), nil
+}
+
+func (repo *MemoryRepository) GetAllSubscriptions(ctx context.Context) ([]*models.Subscription, error) {
+	return repo.ListSubscriptions(ctx, models.SubscriptionFilter{})
+}
+
+func (repo *MemoryRepository) ListSubscriptions(ctx context.Context, filter models.SubscriptionFilter) ([]*models.Subscription, error) {
+	defer repo.rlock(ctx)()
+
+	var subs []*models.Subscription
+	for _, sub := range repo.subs {
+		if len(filter.IDs) > 0 && !slices.Contains(filter.IDs, sub.ID) {
+			continue
+		}
+		if len(filter.UserIDs) > 0 && !slices.Contains(filter.UserIDs, sub.UserID) {
+			continue
+		}
+		if filter.ServiceName != "" && sub.ServiceName != filter.ServiceName {
+			continue
+		}
+		if filter.Category != "" && sub.Category != filter.Category {
+			continue
+		}
+		if filter.Tag != "" && !slices.Contains(sub.Tags, filter.Tag) {
+			continue
+		}
+		if !filter.TrialEnd.IsZero() && (sub.TrialEnd == nil || !sub.TrialEnd.Time().Equal(filter.TrialEnd)) {
+			continue
+		}
+		if filter.AfterID != uuid.Nil && compareUUID(sub.ID, filter.AfterID) <= 0 {
+			continue
+		}
+		subs = append(subs, view(sub))
+	}
+
+	// Postgres сравнивает uuid побайтно, порядок должен совпадать.
+	slices.SortFunc(subs, func(a, b *models.Subscription) int {
+		return compareUUID(a.ID, b.ID)
+	})
+
+	if filter.Limit > 0 && uint64(len(subs)) > filter.Limit {
+		subs = subs[:filter.Limit]
+	}
+
+	return subs, nil
+}
+
+func (repo *MemoryRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	defer repo.lock(ctx)()
+
+	sub, ok := repo.subs[id]
+	if !ok {
+		return nil, subscriptions.ErrNotFound
+	}
+	delete(repo.subs, id)
+
+	return view(sub), nil
+}
+
+func (repo *MemoryRepository) GetSumSubscriptions(ctx context.Context, startDate, endDate, name, usersIds string) (int, error) {
+	from, to, err := models.ParsePeriod(startDate, endDate)
+	if err != nil {
+		repo.log.WarnContext(ctx, err.Error())
+	}
+	userIDs := models.ParseUserIDs(usersIds)
+
+	defer repo.rlock(ctx)()
+
+	sum := 0
+	for _, sub := range repo.subs {
+		if name != "" && sub.ServiceName != name {
+			continue
+		}
+		if len(userIDs) > 0 && !slices.Contains(userIDs, sub.UserID) {
+			continue
+		}
+		if !sub.Within(from, to) {
+			continue
+		}
+		sum += view(sub).ReportPrice()
+	}
+
+	return sum, nil
+}
+
+func (repo *MemoryRepository) GetSubscriptionStats(ctx context.Context, month time.Time) (*models.SubscriptionStats, error) {
+	defer repo.rlock(ctx)()
+
+	stats := &models.SubscriptionStats{}
+	for _, sub := range repo.subs {
+		if sub.ActiveIn(month) {
+			stats.Active++
+			stats.MonthlySpend += view(sub).ChargeIn(month)
+		}
+	}
+
+	return stats, nil
+}
+
+func (repo *MemoryRepository) ListTags(ctx context.Context) ([]*models.TagUsage, error) {
+	defer repo.rlock(ctx)()
+
+	counts := make(map[string]int)
+	for _, sub := range repo.subs {
+		for _, tag := range sub.Tags {
+			counts[tag]++
+		}
+	}
+
+	list := make([]*models.TagUsage, 0, len(counts))
+	for _, tag := range slices.Sorted(maps.Keys(counts)) {
+		list = append(list, &models.TagUsage{Tag: tag, Subscriptions: counts[tag]})
+	}
+
+	return list, nil
+}
+
+func (repo *MemoryRepository) SetPrice(ctx context.Context, id uuid.UUID, period models.PricePeriod) (*models.Subscription, error) {
+	if period.Price < 0 {
+		return nil, subscriptions.ErrNegativePrice
+	}
+
+	defer repo.lock(ctx)()
+
+	current, ok := repo.subs[id]
+	if !ok {
+		return nil, subscriptions.ErrNotFound
+	}
+
+	updated := cloneSubscription(current)
+	from := period.EffectiveFrom.Time()
+	if from.After(updated.StartDate.Time()) {
+		updated.Prices = slices.DeleteFunc(updated.Prices, func(p models.PricePeriod) bool {
+			return p.EffectiveFrom.Time().Equal(from)
+		})
+		i, _ := slices.BinarySearchFunc(updated.Prices, from, func(p models.PricePeriod, t time.Time) int {
+			return p.EffectiveFrom.Time().Compare(t)
+		})
+		updated.Prices = slices.Insert(updated.Prices, i, period)
+	} else {
+		// Изменения до начала подписки перекрыли бы новую начальную цену.
+		updated.Price = &period.Price
+		updated.Prices = slices.DeleteFunc(updated.Prices, func(p models.PricePeriod) bool {
+			return !p.EffectiveFrom.Time().After(updated.StartDate.Time())
+		})
+	}
+
+	updated.UpdatedAt = time.Now().UTC()
+	repo.subs[id] = updated
+
+	return view(updated), nil
+}
+
+func (repo *MemoryRepository) DeletePrice(ctx context.Context, id uuid.UUID, month time.Time) (*models.Subscription, error) {
+	defer repo.lock(ctx)()
+
+	current, ok := repo.subs[id]
+	if !ok {
+		return nil, subscriptions.ErrNotFound
+	}
+
+	updated := cloneSubscription(current)
+	updated.Prices = slices.DeleteFunc(updated.Prices, func(p models.PricePeriod) bool {
+		return p.EffectiveFrom.Time().Equal(month)
+	})
+	if len(updated.Prices) == len(current.Prices) {
+		return nil, subscriptions.ErrPriceNotFound
+	}
+
+	updated.UpdatedAt = time.Now().UTC()
+	repo.subs[id] = updated
+
+	return view(updated), nil
+}
+
+// Do выполняет fn под блокировкой записи всего хранилища: транзакции идут
+// по одной, что соответствует serializable. При ошибке восстанавливается
+// снимок, сделанный в начале транзакции.
+func (repo *MemoryRepository) Do(ctx context.Context, _ tx.Options, fn func(ctx context.Context) error) error {
+	begin := func(context.Context) (tx.Tx, error) {
+		repo.mu.Lock()
+		// Записи не меняются на месте, поэтому достаточно копии карты.
+		return &memoryTx{repo: repo, snapshot: maps.Clone(repo.subs)}, nil
+	}
+	return tx.Run(ctx, tx.Config{}, begin, func(error) bool { return false }, fn)
+}
+
+type memoryTx struct {
+	repo     *MemoryRepository
+	snapshot map[uuid.UUID]*models.Subscription
+}
+
+func (t *memoryTx) Commit(context.Context) error {
+	t.repo.mu.Unlock()
+	return nil
+}
+
+func (t *memoryTx) Rollback(context.Context) error {
+	t.repo.subs = t.snapshot
+	t.repo.mu.Unlock()
+	return nil
+}
+
+// userExists без UserChecker считает, что пользователь есть: так хранилище
+// работает и без ресурса пользователей.
+func (repo *MemoryRepository) userExists(id uuid.UUID) bool {
+	return repo.users == nil || repo.users.UserExists(id)
+}
+
+// lock и rlock не блокируют внутри транзакции: ее Do уже держит mu.
+func (repo *MemoryRepository) lock(ctx context.Context) func() {
+	if repo.inTx(ctx) {
+		return func() {}
+	}
+	repo.mu.Lock()
+	return repo.mu.Unlock
+}
+
+func (repo *MemoryRepository) rlock(ctx context.Context) func() {
+	if repo.inTx(ctx) {
+		return func() {}
+	}
+	repo.mu.RLock()
+	return repo.mu.RUnlock
+}
+
+func (repo *MemoryRepository) inTx(ctx context.Context) bool {
+	t, ok := tx.From(ctx)
+	if !ok {
+		return false
+	}
+	memTx, ok := t.(*memoryTx)
+	return ok && memTx.repo == repo
+}
+
+// validateStored повторяет NOT NULL и CHECK-ограничения таблицы.
+func validateStored(sub *models.Subscription) error {
+	if sub.Price == nil {
+		return subscriptions.ErrPriceRequired
+	}
+	if *sub.Price < 0 {
+		return subscriptions.ErrNegativePrice
+	}
+	if sub.StartDate.Time().IsZero() {
+		return subscriptions.ErrStartDateRequired
+	}
+	if sub.EndDate != nil && sub.EndDate.Time().Before(sub.StartDate.Time()) {
+		return subscriptions.ErrEndBeforeStart
+	}
+	if sub.TrialEnd != nil && sub.TrialEnd.Time().Before(sub.StartDate.Time()) {
+		return subscriptions.ErrTrialBeforeStart
+	}
+	if promo := sub.Promo; promo != nil {
+		valid := promo.Months > 0 && promo.Value >= 0 &&
+			(promo.Type == models.PromoFixed || promo.Type == models.PromoPercentage && promo.Value <= 100)
+		if !valid {
+			return subscriptions.ErrInvalidPromo
+		}
+	}
+	return nil
+}
+
+// applyUpdate принимает значения в тех же видах, что приходят из HTTP (JSON)
+// и gRPC обработчиков.
+func applyUpdate(sub *models.Subscription, field string, value interface{}) error {
+	switch field {
+	case "service_name":
+		name, ok := value.(string)
+		if !ok {
+			return invalidField(field)
+		}
+		sub.ServiceName = name
+	case "service_id":
+		switch v := value.(type) {
+		case nil:
+			sub.ServiceID = nil
+		case uuid.UUID:
+			sub.ServiceID = &v
+		case string:
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return invalidField(field)
+			}
+			sub.ServiceID = &id
+		default:
+			return invalidField(field)
+		}
+	case "category":
+		category, ok := value.(string)
+		if !ok {
+			return invalidField(field)
+		}
+		sub.Category = category
+	case "tags":
+		tags, ok := value.([]string)
+		if !ok {
+			return invalidField(field)
+		}
+		sub.Tags = slices.Clone(tags)
+	case "price":
+		price, ok := toInt(value)
+		if !ok {
+			return invalidField(field)
+		}
+		sub.Price = &price
+	case "user_id":
+		switch v := value.(type) {
+		case uuid.UUID:
+			sub.UserID = v
+		case string:
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return invalidField(field)
+			}
+			sub.UserID = id
+		default:
+			return invalidField(field)
+		}
+	case "start_date":
+		if value == nil {
+			return subscriptions.ErrStartDateRequired
+		}
+		t, ok := value.(time.Time)
+		if !ok {
+			return invalidField(field)
+		}
+		sub.StartDate = models.MonthYear(t)
+	case "end_date":
+		if value == nil {
+			sub.EndDate = nil
+			return nil
+		}
+		t, ok := value.(time.Time)
+		if !ok {
+			return invalidField(field)
+		}
+		end := models.MonthYear(t)
+		sub.EndDate = &end
+	case "trial_end":
+		if value == nil {
+			sub.TrialEnd = nil
+			return nil
+		}
+		t, ok := value.(time.Time)
+		if !ok {
+			return invalidField(field)
+		}
+		end := models.MonthYear(t)
+		sub.TrialEnd = &end
+	case "promo":
+		promo, ok := value.(*models.Promo)
+		if value != nil && !ok {
+			return invalidField(field)
+		}
+		if promo != nil {
+			promo = &models.Promo{Type: promo.Type, Value: promo.Value, Months: promo.Months}
+		}
+		sub.Promo = promo
+	default:
+		return fmt.Errorf("%w: unknown field %s", subscriptions.ErrInvalidArgument, field)
+	}
+	return nil
+}
+
+func invalidField(field string) error {
+	return fmt.Errorf("%w: invalid %s", subscriptions.ErrInvalidArgument, field)
+}
+
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != math.Trunc(v) || v > math.MaxInt32 || v < math.MinInt32 {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+func compareUUID(a, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+func cloneSubscription(sub *models.Subscription) *models.Subscription {
+	clone := *sub
+	if sub.Price != nil {
+		price := *sub.Price
+		clone.Price = &price
+	}
+	if sub.EndDate != nil {
+		end := *sub.EndDate
+		clone.EndDate = &end
+	}
+	if sub.TrialEnd != nil {
+		end := *sub.TrialEnd
+		clone.TrialEnd = &end
+	}
+	if sub.Promo != nil {
+		promo := *sub.Promo
+		clone.Promo = &promo
+	}
+	if sub.ServiceID != nil {
+		serviceID := *sub.ServiceID
+		clone.ServiceID = &serviceID
+	}
+	clone.Tags = slices.Clone(sub.Tags)
+	if clone.Tags == nil {
+		clone.Tags = []string{}
+	}
+	clone.Prices = slices.Clone(sub.Prices)
+	return &clone
+}
+
+// view возвращает копию хранимой подписки с полной историей цен, как ее
+// отдают хранилища в базе.
+func view(sub *models.Subscription) *models.Subscription {
+	clone := cloneSubscription(sub)
+	clone.ApplyPriceChanges(sub.Prices, time.Now())
+	return clone
+}
@@ -4,129 +4,198 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/fx"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 )
 
+// insertColumns - колонки, которые задаются при вставке подписки.
+var insertColumns = []string{
+	"id", "service_name", "service_id", "category", "price", "user_id", "start_date", "end_date",
+	"trial_end", "promo_type", "promo_value", "promo_months",
+}
+
+var subscriptionColumns = append(slices.Clone(insertColumns), "created_at", "updated_at")
+
+var returningSubscription = "RETURNING " + strings.Join(subscriptionColumns, ", ")
+
 type Params struct {
 	fx.In
 
 	Logger  *slog.Logger
-	Pool    *pgxpool.Pool
+	Cluster *db.Cluster
 	Builder squirrel.StatementBuilderType
+	Metrics *metrics.Metrics
 }
 
 type Repository struct {
-	pool    *pgxpool.Pool
+	cluster *db.Cluster
 	log     *slog.Logger
 	builder squirrel.StatementBuilderType
+	metrics *metrics.Metrics
 }
 
 func NewRepository(params Params) *Repository {
 	return &Repository{
-		pool:    params.Pool,
+		cluster: params.Cluster,
 		log:     params.Logger,
 		builder: params.Builder,
+		metrics: params.Metrics,
 	}
 }
 
 func (repo *Repository) CreateSubscription(ctx context.Context, subscriptionData *models.Subscription) (*models.Subscription, error) {
-	var endDate *time.Time
-	if subscriptionData.EndDate != nil {
-		endDate = subscriptionData.EndDate.PtrTime()
-	}
+	defer repo.metrics.ObserveQuery("CreateSubscription", time.Now())
 
 	query, args, err := repo.builder.
 		Insert("subscriptions").
-		Columns("id", "service_name", "price", "user_id", "start_date", "end_date").
-		Values(
-			subscriptionData.ID,
-			subscriptionData.ServiceName,
-			subscriptionData.Price,
-			subscriptionData.UserID,
-			subscriptionData.StartDate.Time(),
-			endDate,
-		).
-		Suffix("RETURNING id, service_name, price, user_id, start_date, end_date").
+		Columns(insertColumns...).
+		Values(subscriptionRow(subscriptionData)...).
+		Suffix(returningSubscription).
 		ToSql()
 	if err != nil {
-		repo.log.Error("build query error: " + err.Error())
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
 		return nil, err
 	}
 
-	createdSubscription := &models.Subscription{}
-	if err := repo.pool.QueryRow(ctx, query, args...).Scan(
-		&createdSubscription.ID,
-		&createdSubscription.ServiceName,
-		&createdSubscription.Price,
-		&createdSubscription.UserID,
-		&createdSubscription.StartDate,
-		&createdSubscription.EndDate,
-	); err != nil {
+	createdSubscription, err := scanSubscription(repo.cluster.Writer(ctx).QueryRow(ctx, query, args...))
+	if err != nil {
 		pgErr := &pgconn.PgError{}
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			repo.log.Warn("subscription with this id already exists")
-			return nil, errors.New("subscription with this id already exists")
+			repo.log.WarnContext(ctx, "subscription with this id already exists")
+			return nil, subscriptions.ErrAlreadyExists
 		}
-		repo.log.Error("failed to create subscription: " + err.Error())
+		if domainErr := checkViolation(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "create subscription: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to create subscription: "+err.Error())
+		return nil, err
+	}
+
+	if err := repo.replaceTags(ctx, createdSubscription.ID, subscriptionData.Tags); err != nil {
 		return nil, err
 	}
+	createdSubscription.Tags = tagsOrEmpty(subscriptionData.Tags)
+	createdSubscription.ApplyPriceChanges(nil, time.Now())
 
 	return createdSubscription, nil
 }
 
+// CopySubscriptions вставляет подписки одним COPY. События при этом
+// не публикуются: метод предназначен для массовой загрузки данных.
+func (repo *Repository) CopySubscriptions(ctx context.Context, subs []*models.Subscription) (int64, error) {
+	defer repo.metrics.ObserveQuery("CopySubscriptions", time.Now())
+
+	var tagRows [][]any
+	rows := make([][]any, 0, len(subs))
+	for _, sub := range subs {
+		rows = append(rows, subscriptionRow(sub))
+		for _, tag := range sub.Tags {
+			tagRows = append(tagRows, []any{sub.ID, tag})
+		}
+	}
+
+	writer := repo.cluster.Writer(ctx)
+	copied, err := writer.CopyFrom(
+		ctx,
+		pgx.Identifier{"subscriptions"},
+		insertColumns,
+		pgx.CopyFromRows(rows),
+	)
+	if err == nil && len(tagRows) > 0 {
+		_, err = writer.CopyFrom(ctx, pgx.Identifier{"subscription_tags"}, []string{"subscription_id", "tag"}, pgx.CopyFromRows(tagRows))
+	}
+	if err != nil {
+		pgErr := &pgconn.PgError{}
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, subscriptions.ErrAlreadyExists
+		}
+		if domainErr := checkViolation(err); domainErr != nil {
+			return 0, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to copy subscriptions: "+err.Error())
+		return 0, err
+	}
+
+	return copied, nil
+}
+
 func (repo *Repository) UpdateSubscription(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("UpdateSubscription", time.Now())
+
 	if len(updates) == 0 {
-		return nil, errors.New("no fields to update")
+		return nil, subscriptions.ErrNoFieldsToUpdate
 	}
 
+	tags, retag := updates["tags"].([]string)
+
 	builder := repo.builder.Update("subscriptions")
 	for field, value := range updates {
-		builder = builder.Set(field, value)
+		switch field {
+		case "tags":
+			continue
+		case "promo":
+			promo, _ := value.(*models.Promo)
+			promoType, promoValue, promoMonths := promoColumns(promo)
+			builder = builder.Set("promo_type", promoType).Set("promo_value", promoValue).Set("promo_months", promoMonths)
+		default:
+			builder = builder.Set(field, value)
+		}
 	}
+	builder = builder.Set("updated_at", squirrel.Expr("now()"))
 
 	query, args, err := builder.
 		Where(squirrel.Eq{"id": id}).
-		Suffix("RETURNING id, service_name, price, user_id, start_date, end_date").
+		Suffix(returningSubscription).
 		ToSql()
 
 	if err != nil {
-		repo.log.Error("build query error: " + err.Error())
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
 		return nil, err
 	}
 
-	updatedSubscription := &models.Subscription{}
-
-	if err = repo.pool.QueryRow(ctx, query, args...).Scan(
-		&updatedSubscription.ID,
-		&updatedSubscription.ServiceName,
-		&updatedSubscription.Price,
-		&updatedSubscription.UserID,
-		&updatedSubscription.StartDate,
-		&updatedSubscription.EndDate,
-	); err != nil {
+	updatedSubscription, err := scanSubscription(repo.cluster.Writer(ctx).QueryRow(ctx, query, args...))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			repo.log.Warn("subscription not found for update")
-			return nil, errors.New("subscription not found")
+			repo.log.WarnContext(ctx, "subscription not found for update")
+			return nil, subscriptions.ErrNotFound
+		}
+		if domainErr := checkViolation(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "update subscription: "+domainErr.Error())
+			return nil, domainErr
 		}
-		repo.log.Error("failed to update subscription: " + err.Error())
+		repo.log.ErrorContext(ctx, "failed to update subscription: "+err.Error())
+		return nil, err
+	}
+
+	if !retag {
+		return updatedSubscription, repo.loadDetails(ctx, repo.cluster.Writer(ctx), updatedSubscription)
+	}
+	if err := repo.replaceTags(ctx, id, tags); err != nil {
 		return nil, err
 	}
+	updatedSubscription.Tags = tagsOrEmpty(tags)
 
-	return updatedSubscription, nil
+	return updatedSubscription, repo.loadPrices(ctx, repo.cluster.Writer(ctx), updatedSubscription)
 }
 
 func (repo *Repository) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("GetSubscriptionByID", time.Now())
+
 	query, args, err := repo.builder.
-		Select("id", "service_name", "price", "user_id", "start_date", "end_date").
+		Select(subscriptionColumns...).
 		From("subscriptions").
 		Where(squirrel.Eq{"id": id}).
 		ToSql()
@@ -135,27 +204,23 @@ func (repo *Repository) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (
 		return nil, err
 	}
 
-	sub := &models.Subscription{}
-	if err := repo.pool.QueryRow(ctx, query, args...).Scan(
-		&sub.ID,
-		&sub.ServiceName,
-		&sub.Price,
-		&sub.UserID,
-		&sub.StartDate,
-		&sub.EndDate,
-	); err != nil {
+	reader := repo.cluster.Reader(ctx)
+	sub, err := scanSubscription(reader.QueryRow(ctx, query, args...))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("subscription not found")
+			return nil, subscriptions.ErrNotFound
 		}
 		return nil, err
 	}
 
-	return sub, nil
+	return sub, repo.loadDetails(ctx, reader, sub)
 }
 
 func (repo *Repository) GetAllSubscriptions(ctx context.Context) ([]*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("GetAllSubscriptions", time.Now())
+
 	query, args, err := repo.builder.
-		Select("id", "service_name", "price", "user_id", "start_date", "end_date").
+		Select(subscriptionColumns...).
 		From("subscriptions").
 		ToSql()
 
@@ -163,76 +228,131 @@ func (repo *Repository) GetAllSubscriptions(ctx context.Context) ([]*models.Subs
 		return nil, err
 	}
 
-	rows, err := repo.pool.Query(ctx, query, args...)
-	if err != nil {
-		return nil, err
+	return repo.querySubscriptions(ctx, query, args)
+}
+
+func (repo *Repository) ListSubscriptions(ctx context.Context, filter models.SubscriptionFilter) ([]*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("ListSubscriptions", time.Now())
+
+	builder := repo.builder.
+		Select(subscriptionColumns...).
+		From("subscriptions").
+		OrderBy("id")
+
+	if len(filter.IDs) > 0 {
+		builder = builder.Where(squirrel.Eq{"id": filter.IDs})
 	}
-	defer rows.Close()
 
-	var subs []*models.Subscription
-	for rows.Next() {
-		sub := &models.Subscription{}
-		if err := rows.Scan(
-			&sub.ID,
-			&sub.ServiceName,
-			&sub.Price,
-			&sub.UserID,
-			&sub.StartDate,
-			&sub.EndDate,
-		); err != nil {
-			return nil, err
-		}
-		subs = append(subs, sub)
+	if len(filter.UserIDs) > 0 {
+		builder = builder.Where(squirrel.Eq{"user_id": filter.UserIDs})
+	}
+
+	if filter.ServiceName != "" {
+		builder = builder.Where(squirrel.Eq{"service_name": filter.ServiceName})
 	}
 
-	return subs, nil
+	if filter.Category != "" {
+		builder = builder.Where(squirrel.Eq{"category": filter.Category})
+	}
+
+	if filter.Tag != "" {
+		builder = builder.Where("EXISTS (SELECT 1 FROM subscription_tags WHERE subscription_tags.subscription_id = subscriptions.id AND tag = ?)", filter.Tag)
+	}
+
+	if !filter.TrialEnd.IsZero() {
+		builder = builder.Where(squirrel.Eq{"trial_end": filter.TrialEnd})
+	}
+
+	if filter.AfterID != uuid.Nil {
+		builder = builder.Where(squirrel.Gt{"id": filter.AfterID})
+	}
+
+	if filter.Limit > 0 {
+		builder = builder.Limit(filter.Limit)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	return repo.querySubscriptions(ctx, query, args)
 }
 
-func (repo *Repository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+func (repo *Repository) DeleteSubscription(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("DeleteSubscription", time.Now())
+
 	query, args, err := repo.builder.
 		Delete("subscriptions").
 		Where(squirrel.Eq{"id": id}).
+		Suffix(returningSubscription).
 		ToSql()
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	cmdTag, err := repo.pool.Exec(ctx, query, args...)
+	// Теги и цены удаляются каскадно, поэтому для события они читаются заранее.
+	writer := repo.cluster.Writer(ctx)
+	tags, err := repo.fetchTags(ctx, writer, []uuid.UUID{id})
 	if err != nil {
-		return err
+		return nil, err
+	}
+	prices, err := repo.fetchPrices(ctx, writer, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
 	}
 
-	if cmdTag.RowsAffected() == 0 {
-		return errors.New("subscription not found")
+	deletedSubscription, err := scanSubscription(writer.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, subscriptions.ErrNotFound
+		}
+		return nil, err
 	}
+	deletedSubscription.Tags = tagsOrEmpty(tags[id])
+	deletedSubscription.ApplyPriceChanges(prices[id], time.Now())
 
-	return nil
+	return deletedSubscription, nil
 }
 
 func (repo *Repository) GetSumSubscriptions(ctx context.Context, startDate, endDate, name, usersIds string) (int, error) {
-	builder := repo.builder.Select("SUM(price)").From("subscriptions")
+	defer repo.metrics.ObserveQuery("GetSumSubscriptions", time.Now())
+
+	// Подписка входит в сумму с оплатой за models.Subscription.ReportMonth.
+	builder := repo.builder.
+		Select().
+		Column("SUM(" + postgresDialect.reportPrice() + ")").
+		From("subscriptions")
 
+	var from, to *time.Time
 	if startDate != "" {
 		t, err := time.Parse("01-2006", startDate)
 		if err != nil {
-			repo.log.Warn("invalid start_date format, expected MM-YYYY: " + err.Error())
+			repo.log.WarnContext(ctx, "invalid start_date format, expected MM-YYYY: "+err.Error())
 		} else {
 			startTime := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
-			builder = builder.Where(squirrel.GtOrEq{"start_date": startTime})
+			from = &startTime
 		}
 	}
 
 	if endDate != "" {
 		t, err := time.Parse("01-2006", endDate)
 		if err != nil {
-			repo.log.Warn("invalid end_date format, expected MM-YYYY: " + err.Error())
+			repo.log.WarnContext(ctx, "invalid end_date format, expected MM-YYYY: "+err.Error())
 		} else {
-			endTime := time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Second)
-			builder = builder.Where(squirrel.LtOrEq{"end_date": endTime})
+			endTime := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+			to = &endTime
 		}
 	}
 
+	// Подписка должна целиком лежать в периоде; открытая граница периода
+	// (NULL) не ограничивает, а бессрочная подписка не входит в период с концом.
+	if from != nil || to != nil {
+		builder = builder.Where("period <@ daterange(?::date, ?::date, '[]')", from, to)
+	}
+
 	if name != "" {
 		builder = builder.Where(squirrel.Eq{"service_name": name})
 	}
@@ -258,14 +378,14 @@ func (repo *Repository) GetSumSubscriptions(ctx context.Context, startDate, endD
 
 	query, args, err := builder.ToSql()
 	if err != nil {
-		repo.log.Error("build query error: " + err.Error())
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
 		return 0, err
 	}
 
 	var sum sql.NullInt64
 
-	if err := repo.pool.QueryRow(ctx, query, args...).Scan(&sum); err != nil {
-		repo.log.Error("failed to fetch sum subscription: " + err.Error())
+	if err := repo.cluster.Reader(ctx).QueryRow(ctx, query, args...).Scan(&sum); err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch sum subscription: "+err.Error())
 		return 0, err
 	}
 
@@ -275,3 +395,403 @@ func (repo *Repository) GetSumSubscriptions(ctx context.Context, startDate, endD
 
 	return int(sum.Int64), nil
 }
+
+func (repo *Repository) GetSubscriptionStats(ctx context.Context, month time.Time) (*models.SubscriptionStats, error) {
+	defer repo.metrics.ObserveQuery("GetSubscriptionStats", time.Now())
+
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	query, args, err := repo.builder.
+		Select("COUNT(*)").
+		Column(monthExpr("COALESCE(SUM(%s), 0)", postgresDialect.chargeIn("?::date"), monthStart)).
+		From("subscriptions").
+		Where("period && daterange(?::date, ?::date, '[]')", monthStart, monthEnd).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	stats := &models.SubscriptionStats{}
+	if err := repo.cluster.Reader(ctx).QueryRow(ctx, query, args...).Scan(&stats.Active, &stats.MonthlySpend); err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch subscription stats: "+err.Error())
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (repo *Repository) ListTags(ctx context.Context) ([]*models.TagUsage, error) {
+	defer repo.metrics.ObserveQuery("ListTags", time.Now())
+
+	rows, err := repo.cluster.Reader(ctx).Query(ctx,
+		"SELECT tag, COUNT(*) FROM subscription_tags GROUP BY tag ORDER BY tag")
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to list tags: "+err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.TagUsage
+	for rows.Next() {
+		usage := &models.TagUsage{}
+		if err := rows.Scan(&usage.Tag, &usage.Subscriptions); err != nil {
+			return nil, err
+		}
+		list = append(list, usage)
+	}
+
+	return list, rows.Err()
+}
+
+func (repo *Repository) querySubscriptions(ctx context.Context, query string, args []interface{}) ([]*models.Subscription, error) {
+	reader := repo.cluster.Reader(ctx)
+	rows, err := reader.Query(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to list subscriptions: "+err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*models.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	return subs, repo.loadDetails(ctx, reader, subs...)
+}
+
+// SetPrice сравнивает месяц с началом подписки в самом UPDATE, поэтому
+// начальная цена меняется без отдельного чтения.
+func (repo *Repository) SetPrice(ctx context.Context, id uuid.UUID, period models.PricePeriod) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("SetPrice", time.Now())
+
+	from := period.EffectiveFrom.Time()
+	query, args, err := repo.builder.
+		Update("subscriptions").
+		Set("price", squirrel.Expr("CASE WHEN start_date >= ? THEN ? ELSE price END", from, period.Price)).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": id}).
+		Suffix(returningSubscription).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	writer := repo.cluster.Writer(ctx)
+	sub, err := scanSubscription(writer.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, subscriptions.ErrNotFound
+		}
+		if domainErr := checkViolation(err); domainErr != nil {
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to set subscription price: "+err.Error())
+		return nil, err
+	}
+
+	if from.After(sub.StartDate.Time()) {
+		_, err = writer.Exec(ctx,
+			"INSERT INTO subscription_prices (subscription_id, effective_from, price) VALUES ($1, $2, $3) "+
+				"ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price",
+			id, from, period.Price)
+	} else {
+		// Изменения до начала подписки перекрыли бы новую начальную цену.
+		_, err = writer.Exec(ctx,
+			"DELETE FROM subscription_prices WHERE subscription_id = $1 AND effective_from <= $2", id, sub.StartDate.Time())
+	}
+	if err != nil {
+		if domainErr := checkViolation(err); domainErr != nil {
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to set subscription price: "+err.Error())
+		return nil, err
+	}
+
+	return sub, repo.loadDetails(ctx, writer, sub)
+}
+
+func (repo *Repository) DeletePrice(ctx context.Context, id uuid.UUID, month time.Time) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("DeletePrice", time.Now())
+
+	query, args, err := repo.builder.
+		Update("subscriptions").
+		Set("updated_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": id}).
+		Suffix(returningSubscription).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	writer := repo.cluster.Writer(ctx)
+	sub, err := scanSubscription(writer.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, subscriptions.ErrNotFound
+		}
+		repo.log.ErrorContext(ctx, "failed to delete subscription price: "+err.Error())
+		return nil, err
+	}
+
+	tag, err := writer.Exec(ctx,
+		"DELETE FROM subscription_prices WHERE subscription_id = $1 AND effective_from = $2", id, month)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to delete subscription price: "+err.Error())
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, subscriptions.ErrPriceNotFound
+	}
+
+	return sub, repo.loadDetails(ctx, writer, sub)
+}
+
+// loadDetails заполняет теги и историю цен подписок.
+func (repo *Repository) loadDetails(ctx context.Context, conn db.Querier, subs ...*models.Subscription) error {
+	if err := repo.loadTags(ctx, conn, subs...); err != nil {
+		return err
+	}
+	return repo.loadPrices(ctx, conn, subs...)
+}
+
+// loadTags заполняет теги подписок одним запросом.
+func (repo *Repository) loadTags(ctx context.Context, conn db.Querier, subs ...*models.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(subs))
+	for _, sub := range subs {
+		ids = append(ids, sub.ID)
+	}
+
+	tags, err := repo.fetchTags(ctx, conn, ids)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		sub.Tags = tagsOrEmpty(tags[sub.ID])
+	}
+	return nil
+}
+
+func (repo *Repository) fetchTags(ctx context.Context, conn db.Querier, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	rows, err := conn.Query(ctx,
+		"SELECT subscription_id, tag FROM subscription_tags WHERE subscription_id = ANY($1) ORDER BY tag", ids)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch subscription tags: "+err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var (
+			id  uuid.UUID
+			tag string
+		)
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// loadPrices заполняет историю цен подписок одним запросом и заменяет
+// Price ценой текущего месяца.
+func (repo *Repository) loadPrices(ctx context.Context, conn db.Querier, subs ...*models.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(subs))
+	for _, sub := range subs {
+		ids = append(ids, sub.ID)
+	}
+
+	prices, err := repo.fetchPrices(ctx, conn, ids)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, sub := range subs {
+		sub.ApplyPriceChanges(prices[sub.ID], now)
+	}
+	return nil
+}
+
+func (repo *Repository) fetchPrices(ctx context.Context, conn db.Querier, ids []uuid.UUID) (map[uuid.UUID][]models.PricePeriod, error) {
+	rows, err := conn.Query(ctx,
+		"SELECT subscription_id, effective_from, price FROM subscription_prices WHERE subscription_id = ANY($1) ORDER BY effective_from", ids)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch subscription prices: "+err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[uuid.UUID][]models.PricePeriod)
+	for rows.Next() {
+		var (
+			id     uuid.UUID
+			period models.PricePeriod
+		)
+		if err := rows.Scan(&id, &period.EffectiveFrom, &period.Price); err != nil {
+			return nil, err
+		}
+		prices[id] = append(prices[id], period)
+	}
+
+	return prices, rows.Err()
+}
+
+// replaceTags заменяет теги подписки.
+func (repo *Repository) replaceTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	writer := repo.cluster.Writer(ctx)
+	if _, err := writer.Exec(ctx, "DELETE FROM subscription_tags WHERE subscription_id = $1", id); err != nil {
+		repo.log.ErrorContext(ctx, "failed to replace subscription tags: "+err.Error())
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	insert := repo.builder.Insert("subscription_tags").Columns("subscription_id", "tag")
+	for _, tag := range tags {
+		insert = insert.Values(id, tag)
+	}
+	query, args, err := insert.ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Exec(ctx, query, args...); err != nil {
+		repo.log.ErrorContext(ctx, "failed to replace subscription tags: "+err.Error())
+		return err
+	}
+	return nil
+}
+
+func scanSubscription(row pgx.Row) (*models.Subscription, error) {
+	sub := &models.Subscription{}
+	var promo promoRow
+	if err := row.Scan(
+		&sub.ID,
+		&sub.ServiceName,
+		&sub.ServiceID,
+		&sub.Category,
+		&sub.Price,
+		&sub.UserID,
+		&sub.StartDate,
+		&sub.EndDate,
+		&sub.TrialEnd,
+		&promo.Type,
+		&promo.Value,
+		&promo.Months,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	sub.Promo = promo.promo()
+	return sub, nil
+}
+
+// subscriptionRow готовит значения колонок insertColumns.
+func subscriptionRow(sub *models.Subscription) []any {
+	var endDate, trialEnd *time.Time
+	if sub.EndDate != nil {
+		endDate = sub.EndDate.PtrTime()
+	}
+	if sub.TrialEnd != nil {
+		trialEnd = sub.TrialEnd.PtrTime()
+	}
+	promoType, promoValue, promoMonths := promoColumns(sub.Promo)
+
+	return []any{
+		sub.ID, sub.ServiceName, sub.ServiceID, sub.Category, sub.Price, sub.UserID, sub.StartDate.Time(), endDate,
+		trialEnd, promoType, promoValue, promoMonths,
+	}
+}
+
+// promoRow - колонки promo_type, promo_value, promo_months.
+type promoRow struct {
+	Type   *string
+	Value  int
+	Months int
+}
+
+func (p promoRow) promo() *models.Promo {
+	if p.Type == nil {
+		return nil
+	}
+	return &models.Promo{Type: *p.Type, Value: p.Value, Months: p.Months}
+}
+
+func promoColumns(promo *models.Promo) (*string, int, int) {
+	if promo == nil {
+		return nil, 0, 0
+	}
+	return &promo.Type, promo.Value, promo.Months
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// monthExpr подставляет выражение expr в format и передает month каждому
+// его параметру.
+func monthExpr(format, expr string, month any) squirrel.Sqlizer {
+	args := make([]any, strings.Count(expr, "?"))
+	for i := range args {
+		args[i] = month
+	}
+	return squirrel.Expr(fmt.Sprintf(format, expr), args...)
+}
+
+// checkViolation переводит нарушения CHECK-ограничений и внешних ключей
+// на пользователя и сервис каталога в доменные ошибки.
+func checkViolation(err error) error {
+	pgErr := &pgconn.PgError{}
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	if pgErr.Code == "23503" && pgErr.ConstraintName == "subscriptions_user_id_fkey" {
+		return subscriptions.ErrUserNotFound
+	}
+	if pgErr.Code == "23503" && pgErr.ConstraintName == "subscriptions_service_id_fkey" {
+		return subscriptions.ErrServiceNotFound
+	}
+	if pgErr.Code != "23514" {
+		return nil
+	}
+
+	switch pgErr.ConstraintName {
+	case "subscriptions_price_non_negative", "subscription_prices_price_non_negative":
+		return subscriptions.ErrNegativePrice
+	case "subscriptions_end_date_after_start":
+		return subscriptions.ErrEndBeforeStart
+	case "subscriptions_trial_end_after_start":
+		return subscriptions.ErrTrialBeforeStart
+	case "subscriptions_promo_valid":
+		return subscriptions.ErrInvalidPromo
+	default:
+		return subscriptions.ErrInvalidArgument
+	}
+}
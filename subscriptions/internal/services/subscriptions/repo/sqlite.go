@@ -0,0 +1,796 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"log/slog"
+	sqlite3 "modernc.org/sqlite/lib"
+	"strings"
+	"time"
+)
+
+type SQLiteParams struct {
+	fx.In
+
+	Logger  *slog.Logger
+	DB      *sql.DB
+	Metrics *metrics.Metrics
+}
+
+// SQLiteRepository хранит подписки в файле SQLite для однопользовательских
+// и edge-установок. Даты лежат строками YYYY-MM-DD, uuid — в каноническом
+// текстовом виде, поэтому сортировка по id совпадает с Postgres.
+type SQLiteRepository struct {
+	db      *sql.DB
+	log     *slog.Logger
+	builder squirrel.StatementBuilderType
+	metrics *metrics.Metrics
+}
+
+func NewSQLiteRepository(params SQLiteParams) *SQLiteRepository {
+	return &SQLiteRepository{
+		db:      params.DB,
+		log:     params.Logger,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		metrics: params.Metrics,
+	}
+}
+
+func (repo *SQLiteRepository) CreateSubscription(ctx context.Context, subscriptionData *models.Subscription) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("CreateSubscription", time.Now())
+
+	query, args, err := repo.builder.
+		Insert("subscriptions").
+		Columns(insertColumns...).
+		Values(sqliteRow(subscriptionData)...).
+		Suffix(returningSubscription).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	createdSubscription, err := scanSQLiteSubscription(db.SQLiteConn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "create subscription: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to create subscription: "+err.Error())
+		return nil, err
+	}
+
+	if err := repo.replaceTags(ctx, createdSubscription.ID, subscriptionData.Tags); err != nil {
+		return nil, err
+	}
+	createdSubscription.Tags = tagsOrEmpty(subscriptionData.Tags)
+	createdSubscription.ApplyPriceChanges(nil, time.Now())
+
+	return createdSubscription, nil
+}
+
+// CopySubscriptions вставляет подписки в одной транзакции: все или ни одной.
+// Внутри tx.Manager.Do используется внешняя транзакция.
+func (repo *SQLiteRepository) CopySubscriptions(ctx context.Context, subs []*models.Subscription) (int64, error) {
+	defer repo.metrics.ObserveQuery("CopySubscriptions", time.Now())
+
+	conn := db.SQLiteConn(ctx, repo.db)
+	var sqlTx *sql.Tx
+	if !tx.Active(ctx) {
+		var err error
+		if sqlTx, err = repo.db.BeginTx(ctx, nil); err != nil {
+			return 0, err
+		}
+		defer sqlTx.Rollback()
+		conn = sqlTx
+	}
+
+	stmt, err := conn.PrepareContext(ctx,
+		"INSERT INTO subscriptions ("+strings.Join(insertColumns, ", ")+") VALUES (?"+strings.Repeat(", ?", len(insertColumns)-1)+")")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	tagStmt, err := conn.PrepareContext(ctx, "INSERT INTO subscription_tags (subscription_id, tag) VALUES (?, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer tagStmt.Close()
+
+	for _, sub := range subs {
+		if _, err := stmt.ExecContext(ctx, sqliteRow(sub)...); err != nil {
+			if domainErr := sqliteViolations.Map(err); domainErr != nil {
+				return 0, domainErr
+			}
+			repo.log.ErrorContext(ctx, "failed to copy subscriptions: "+err.Error())
+			return 0, err
+		}
+		for _, tag := range sub.Tags {
+			if _, err := tagStmt.ExecContext(ctx, sub.ID.String(), tag); err != nil {
+				if domainErr := sqliteViolations.Map(err); domainErr != nil {
+					return 0, domainErr
+				}
+				repo.log.ErrorContext(ctx, "failed to copy subscription tags: "+err.Error())
+				return 0, err
+			}
+		}
+	}
+
+	if sqlTx != nil {
+		if err := sqlTx.Commit(); err != nil {
+			repo.log.ErrorContext(ctx, "failed to copy subscriptions: "+err.Error())
+			return 0, err
+		}
+	}
+
+	return int64(len(subs)), nil
+}
+
+func (repo *SQLiteRepository) UpdateSubscription(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("UpdateSubscription", time.Now())
+
+	if len(updates) == 0 {
+		return nil, subscriptions.ErrNoFieldsToUpdate
+	}
+
+	tags, retag := updates["tags"].([]string)
+
+	builder := repo.builder.Update("subscriptions")
+	for field, value := range updates {
+		switch field {
+		case "tags":
+			continue
+		case "promo":
+			promo, _ := value.(*models.Promo)
+			promoType, promoValue, promoMonths := promoColumns(promo)
+			builder = builder.Set("promo_type", promoType).Set("promo_value", promoValue).Set("promo_months", promoMonths)
+			continue
+		}
+		value, err := sqliteValue(field, value)
+		if err != nil {
+			repo.log.WarnContext(ctx, "update subscription: "+err.Error())
+			return nil, err
+		}
+		builder = builder.Set(field, value)
+	}
+	builder = builder.Set("updated_at", squirrel.Expr(db.SQLiteNow))
+
+	query, args, err := builder.
+		Where(squirrel.Eq{"id": id.String()}).
+		Suffix(returningSubscription).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	updatedSubscription, err := scanSQLiteSubscription(db.SQLiteConn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			repo.log.WarnContext(ctx, "subscription not found for update")
+			return nil, subscriptions.ErrNotFound
+		}
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "update subscription: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to update subscription: "+err.Error())
+		return nil, err
+	}
+
+	if !retag {
+		return updatedSubscription, repo.loadDetails(ctx, updatedSubscription)
+	}
+	if err := repo.replaceTags(ctx, id, tags); err != nil {
+		return nil, err
+	}
+	updatedSubscription.Tags = tagsOrEmpty(tags)
+
+	return updatedSubscription, repo.loadPrices(ctx, updatedSubscription)
+}
+
+func (repo *SQLiteRepository) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("GetSubscriptionByID", time.Now())
+
+	query, args, err := repo.builder.
+		Select(subscriptionColumns...).
+		From("subscriptions").
+		Where(squirrel.Eq{"id": id.String()}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := scanSQLiteSubscription(db.SQLiteConn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, subscriptions.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return sub, repo.loadDetails(ctx, sub)
+}
+
+func (repo *SQLiteRepository) GetAllSubscriptions(ctx context.Context) ([]*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("GetAllSubscriptions", time.Now())
+
+	return repo.querySubscriptions(ctx, repo.builder.Select(subscriptionColumns...).From("subscriptions"))
+}
+
+func (repo *SQLiteRepository) ListSubscriptions(ctx context.Context, filter models.SubscriptionFilter) ([]*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("ListSubscriptions", time.Now())
+
+	builder := repo.builder.
+		Select(subscriptionColumns...).
+		From("subscriptions").
+		OrderBy("id")
+
+	if len(filter.IDs) > 0 {
+		builder = builder.Where(squirrel.Eq{"id": uuidStrings(filter.IDs)})
+	}
+
+	if len(filter.UserIDs) > 0 {
+		builder = builder.Where(squirrel.Eq{"user_id": uuidStrings(filter.UserIDs)})
+	}
+
+	if filter.ServiceName != "" {
+		builder = builder.Where(squirrel.Eq{"service_name": filter.ServiceName})
+	}
+
+	if filter.Category != "" {
+		builder = builder.Where(squirrel.Eq{"category": filter.Category})
+	}
+
+	if filter.Tag != "" {
+		builder = builder.Where("EXISTS (SELECT 1 FROM subscription_tags WHERE subscription_tags.subscription_id = subscriptions.id AND tag = ?)", filter.Tag)
+	}
+
+	if !filter.TrialEnd.IsZero() {
+		builder = builder.Where(squirrel.Eq{"trial_end": sqliteDate(filter.TrialEnd)})
+	}
+
+	if filter.AfterID != uuid.Nil {
+		builder = builder.Where(squirrel.Gt{"id": filter.AfterID.String()})
+	}
+
+	if filter.Limit > 0 {
+		builder = builder.Limit(filter.Limit)
+	}
+
+	return repo.querySubscriptions(ctx, builder)
+}
+
+func (repo *SQLiteRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("DeleteSubscription", time.Now())
+
+	query, args, err := repo.builder.
+		Delete("subscriptions").
+		Where(squirrel.Eq{"id": id.String()}).
+		Suffix(returningSubscription).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	// Теги и цены удаляются каскадно, поэтому для события они читаются заранее.
+	tags, err := repo.fetchTags(ctx, []string{id.String()})
+	if err != nil {
+		return nil, err
+	}
+	prices, err := repo.fetchPrices(ctx, []string{id.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	deletedSubscription, err := scanSQLiteSubscription(db.SQLiteConn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, subscriptions.ErrNotFound
+		}
+		return nil, err
+	}
+	deletedSubscription.Tags = tagsOrEmpty(tags[id])
+	deletedSubscription.ApplyPriceChanges(prices[id], time.Now())
+
+	return deletedSubscription, nil
+}
+
+func (repo *SQLiteRepository) GetSumSubscriptions(ctx context.Context, startDate, endDate, name, usersIds string) (int, error) {
+	defer repo.metrics.ObserveQuery("GetSumSubscriptions", time.Now())
+
+	builder := repo.builder.
+		Select().
+		Column("SUM(" + sqliteDialect.reportPrice() + ")").
+		From("subscriptions")
+
+	if startDate != "" {
+		t, err := time.Parse("01-2006", startDate)
+		if err != nil {
+			repo.log.WarnContext(ctx, "invalid start_date format, expected MM-YYYY: "+err.Error())
+		} else {
+			builder = builder.Where(squirrel.GtOrEq{"start_date": sqliteDate(t)})
+		}
+	}
+
+	// Как period <@ daterange в Postgres: подписка целиком лежит в периоде,
+	// бессрочная не входит в период с концом.
+	if endDate != "" {
+		t, err := time.Parse("01-2006", endDate)
+		if err != nil {
+			repo.log.WarnContext(ctx, "invalid end_date format, expected MM-YYYY: "+err.Error())
+		} else {
+			monthEnd := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+			builder = builder.Where(squirrel.And{
+				squirrel.NotEq{"end_date": nil},
+				squirrel.LtOrEq{"end_date": sqliteDate(monthEnd)},
+			})
+		}
+	}
+
+	if name != "" {
+		builder = builder.Where(squirrel.Eq{"service_name": name})
+	}
+
+	if userIDs := models.ParseUserIDs(usersIds); len(userIDs) > 0 {
+		builder = builder.Where(squirrel.Eq{"user_id": uuidStrings(userIDs)})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return 0, err
+	}
+
+	var sum sql.NullInt64
+	if err := db.SQLiteConn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(&sum); err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch sum subscription: "+err.Error())
+		return 0, err
+	}
+
+	return int(sum.Int64), nil
+}
+
+func (repo *SQLiteRepository) GetSubscriptionStats(ctx context.Context, month time.Time) (*models.SubscriptionStats, error) {
+	defer repo.metrics.ObserveQuery("GetSubscriptionStats", time.Now())
+
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	query, args, err := repo.builder.
+		Select("COUNT(*)").
+		Column(monthExpr("COALESCE(SUM(%s), 0)", sqliteDialect.chargeIn("?"), sqliteDate(monthStart))).
+		From("subscriptions").
+		Where(squirrel.LtOrEq{"start_date": sqliteDate(monthEnd)}).
+		Where(squirrel.Or{
+			squirrel.Eq{"end_date": nil},
+			squirrel.GtOrEq{"end_date": sqliteDate(monthStart)},
+		}).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	stats := &models.SubscriptionStats{}
+	if err := db.SQLiteConn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(&stats.Active, &stats.MonthlySpend); err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch subscription stats: "+err.Error())
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (repo *SQLiteRepository) querySubscriptions(ctx context.Context, builder squirrel.SelectBuilder) ([]*models.Subscription, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, repo.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to list subscriptions: "+err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*models.Subscription
+	for rows.Next() {
+		sub, err := scanSQLiteSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	return subs, repo.loadDetails(ctx, subs...)
+}
+
+func (repo *SQLiteRepository) ListTags(ctx context.Context) ([]*models.TagUsage, error) {
+	defer repo.metrics.ObserveQuery("ListTags", time.Now())
+
+	rows, err := db.SQLiteConn(ctx, repo.db).QueryContext(ctx,
+		"SELECT tag, COUNT(*) FROM subscription_tags GROUP BY tag ORDER BY tag")
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to list tags: "+err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.TagUsage
+	for rows.Next() {
+		usage := &models.TagUsage{}
+		if err := rows.Scan(&usage.Tag, &usage.Subscriptions); err != nil {
+			return nil, err
+		}
+		list = append(list, usage)
+	}
+
+	return list, rows.Err()
+}
+
+func (repo *SQLiteRepository) SetPrice(ctx context.Context, id uuid.UUID, period models.PricePeriod) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("SetPrice", time.Now())
+
+	from := sqliteDate(period.EffectiveFrom.Time())
+	query, args, err := repo.builder.
+		Update("subscriptions").
+		Set("price", squirrel.Expr("CASE WHEN start_date >= ? THEN ? ELSE price END", from, period.Price)).
+		Set("updated_at", squirrel.Expr(db.SQLiteNow)).
+		Where(squirrel.Eq{"id": id.String()}).
+		Suffix(returningSubscription).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	conn := db.SQLiteConn(ctx, repo.db)
+	sub, err := scanSQLiteSubscription(conn.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, subscriptions.ErrNotFound
+		}
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to set subscription price: "+err.Error())
+		return nil, err
+	}
+
+	if period.EffectiveFrom.Time().After(sub.StartDate.Time()) {
+		_, err = conn.ExecContext(ctx,
+			"INSERT INTO subscription_prices (subscription_id, effective_from, price) VALUES (?, ?, ?) "+
+				"ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = excluded.price",
+			id.String(), from, period.Price)
+	} else {
+		// Изменения до начала подписки перекрыли бы новую начальную цену.
+		_, err = conn.ExecContext(ctx,
+			"DELETE FROM subscription_prices WHERE subscription_id = ? AND effective_from <= ?", id.String(), sqliteDate(sub.StartDate.Time()))
+	}
+	if err != nil {
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to set subscription price: "+err.Error())
+		return nil, err
+	}
+
+	return sub, repo.loadDetails(ctx, sub)
+}
+
+func (repo *SQLiteRepository) DeletePrice(ctx context.Context, id uuid.UUID, month time.Time) (*models.Subscription, error) {
+	defer repo.metrics.ObserveQuery("DeletePrice", time.Now())
+
+	query, args, err := repo.builder.
+		Update("subscriptions").
+		Set("updated_at", squirrel.Expr(db.SQLiteNow)).
+		Where(squirrel.Eq{"id": id.String()}).
+		Suffix(returningSubscription).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	conn := db.SQLiteConn(ctx, repo.db)
+	sub, err := scanSQLiteSubscription(conn.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, subscriptions.ErrNotFound
+		}
+		repo.log.ErrorContext(ctx, "failed to delete subscription price: "+err.Error())
+		return nil, err
+	}
+
+	result, err := conn.ExecContext(ctx,
+		"DELETE FROM subscription_prices WHERE subscription_id = ? AND effective_from = ?", id.String(), sqliteDate(month))
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to delete subscription price: "+err.Error())
+		return nil, err
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if deleted == 0 {
+		return nil, subscriptions.ErrPriceNotFound
+	}
+
+	return sub, repo.loadDetails(ctx, sub)
+}
+
+// loadDetails заполняет теги и историю цен подписок.
+func (repo *SQLiteRepository) loadDetails(ctx context.Context, subs ...*models.Subscription) error {
+	if err := repo.loadTags(ctx, subs...); err != nil {
+		return err
+	}
+	return repo.loadPrices(ctx, subs...)
+}
+
+// loadTags заполняет теги подписок одним запросом.
+func (repo *SQLiteRepository) loadTags(ctx context.Context, subs ...*models.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		ids = append(ids, sub.ID.String())
+	}
+
+	tags, err := repo.fetchTags(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		sub.Tags = tagsOrEmpty(tags[sub.ID])
+	}
+	return nil
+}
+
+func (repo *SQLiteRepository) fetchTags(ctx context.Context, ids []string) (map[uuid.UUID][]string, error) {
+	query, args, err := repo.builder.
+		Select("subscription_id", "tag").
+		From("subscription_tags").
+		Where(squirrel.Eq{"subscription_id": ids}).
+		OrderBy("tag").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, repo.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch subscription tags: "+err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var (
+			id  uuid.UUID
+			tag string
+		)
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// loadPrices заполняет историю цен подписок одним запросом и заменяет
+// Price ценой текущего месяца.
+func (repo *SQLiteRepository) loadPrices(ctx context.Context, subs ...*models.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		ids = append(ids, sub.ID.String())
+	}
+
+	prices, err := repo.fetchPrices(ctx, ids)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, sub := range subs {
+		sub.ApplyPriceChanges(prices[sub.ID], now)
+	}
+	return nil
+}
+
+func (repo *SQLiteRepository) fetchPrices(ctx context.Context, ids []string) (map[uuid.UUID][]models.PricePeriod, error) {
+	query, args, err := repo.builder.
+		Select("subscription_id", "effective_from", "price").
+		From("subscription_prices").
+		Where(squirrel.Eq{"subscription_id": ids}).
+		OrderBy("effective_from").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, repo.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to fetch subscription prices: "+err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[uuid.UUID][]models.PricePeriod)
+	for rows.Next() {
+		var (
+			id     uuid.UUID
+			period models.PricePeriod
+		)
+		if err := rows.Scan(&id, &period.EffectiveFrom, &period.Price); err != nil {
+			return nil, err
+		}
+		prices[id] = append(prices[id], period)
+	}
+
+	return prices, rows.Err()
+}
+
+// replaceTags заменяет теги подписки.
+func (repo *SQLiteRepository) replaceTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	conn := db.SQLiteConn(ctx, repo.db)
+	if _, err := conn.ExecContext(ctx, "DELETE FROM subscription_tags WHERE subscription_id = ?", id.String()); err != nil {
+		repo.log.ErrorContext(ctx, "failed to replace subscription tags: "+err.Error())
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	insert := repo.builder.Insert("subscription_tags").Columns("subscription_id", "tag")
+	for _, tag := range tags {
+		insert = insert.Values(id.String(), tag)
+	}
+	query, args, err := insert.ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+		repo.log.ErrorContext(ctx, "failed to replace subscription tags: "+err.Error())
+		return err
+	}
+	return nil
+}
+
+func scanSQLiteSubscription(row interface{ Scan(dest ...any) error }) (*models.Subscription, error) {
+	sub := &models.Subscription{}
+	var promo promoRow
+	var createdAt, updatedAt string
+	if err := row.Scan(
+		&sub.ID,
+		&sub.ServiceName,
+		&sub.ServiceID,
+		&sub.Category,
+		&sub.Price,
+		&sub.UserID,
+		&sub.StartDate,
+		&sub.EndDate,
+		&sub.TrialEnd,
+		&promo.Type,
+		&promo.Value,
+		&promo.Months,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+	sub.Promo = promo.promo()
+
+	var err error
+	if sub.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at %q: %w", createdAt, err)
+	}
+	if sub.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return nil, fmt.Errorf("invalid updated_at %q: %w", updatedAt, err)
+	}
+	return sub, nil
+}
+
+// sqliteRow готовит значения колонок insertColumns.
+func sqliteRow(sub *models.Subscription) []interface{} {
+	var endDate *string
+	if sub.EndDate != nil {
+		end := sqliteDate(sub.EndDate.Time())
+		endDate = &end
+	}
+
+	var trialEnd *string
+	if sub.TrialEnd != nil {
+		end := sqliteDate(sub.TrialEnd.Time())
+		trialEnd = &end
+	}
+
+	var startDate *string
+	if start := sub.StartDate.Time(); !start.IsZero() {
+		date := sqliteDate(start)
+		startDate = &date
+	}
+
+	var serviceID *string
+	if sub.ServiceID != nil {
+		id := sub.ServiceID.String()
+		serviceID = &id
+	}
+
+	promoType, promoValue, promoMonths := promoColumns(sub.Promo)
+
+	return []interface{}{
+		sub.ID.String(), sub.ServiceName, serviceID, sub.Category, sub.Price, sub.UserID.String(), startDate, endDate,
+		trialEnd, promoType, promoValue, promoMonths,
+	}
+}
+
+// sqliteValue приводит значение из карты обновлений к виду, в котором
+// колонка хранится в SQLite. Остальные значения передаются как есть.
+func sqliteValue(field string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return sqliteDate(v), nil
+	case uuid.UUID:
+		return v.String(), nil
+	case float64:
+		// JSON-числа приходят как float64; в Postgres дробная цена
+		// не попадет в колонку INT, в SQLite ее нужно отсечь явно.
+		price, ok := toInt(v)
+		if !ok {
+			return nil, invalidField(field)
+		}
+		return price, nil
+	}
+	return value, nil
+}
+
+func sqliteDate(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, id.String())
+	}
+	return strs
+}
+
+// sqliteViolations переводят нарушения ограничений таблицы в доменные ошибки.
+var sqliteViolations = db.SQLiteViolations{
+	{Code: sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, Err: subscriptions.ErrAlreadyExists},
+	{Code: sqlite3.SQLITE_CONSTRAINT_UNIQUE, Err: subscriptions.ErrAlreadyExists},
+	{Code: sqlite3.SQLITE_CONSTRAINT_NOTNULL, Constraint: "subscriptions.price", Err: subscriptions.ErrPriceRequired},
+	{Code: sqlite3.SQLITE_CONSTRAINT_NOTNULL, Constraint: "subscriptions.start_date", Err: subscriptions.ErrStartDateRequired},
+	{Code: sqlite3.SQLITE_CONSTRAINT_NOTNULL, Err: subscriptions.ErrInvalidArgument},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Constraint: "price_non_negative", Err: subscriptions.ErrNegativePrice},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Constraint: "subscriptions_end_date_after_start", Err: subscriptions.ErrEndBeforeStart},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Constraint: "subscriptions_trial_end_after_start", Err: subscriptions.ErrTrialBeforeStart},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Constraint: "subscriptions_promo_valid", Err: subscriptions.ErrInvalidPromo},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Err: subscriptions.ErrInvalidArgument},
+	// SQLite не сообщает имя ключа. Сервис каталога UseCase проверяет
+	// заранее, так что остается пользователь.
+	{Code: sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY, Err: subscriptions.ErrUserNotFound},
+}
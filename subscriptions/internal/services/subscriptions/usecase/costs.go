@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"slices"
+)
+
+// GetSumBreakdown показывает, сколько из суммы приходится на каждого
+// пользователя: общая подписка раскладывается по долям участников.
+func (u *UseCase) GetSumBreakdown(ctx context.Context, filter models.CostFilter) ([]*models.UserCost, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetSumBreakdown")
+	defer span.End()
+
+	shares, err := u.costShares(ctx, u.normalizeCostFilter(ctx, filter))
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	costs := make(map[uuid.UUID]int)
+	for _, share := range shares {
+		costs[share.userID] += share.amount
+	}
+
+	breakdown := make([]*models.UserCost, 0, len(costs))
+	for userID, total := range costs {
+		breakdown = append(breakdown, &models.UserCost{UserID: userID, Total: total})
+	}
+	slices.SortFunc(breakdown, func(a, b *models.UserCost) int {
+		if c := cmp.Compare(b.Total, a.Total); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.UserID[:], b.UserID[:])
+	})
+
+	return breakdown, nil
+}
+
+// GetSumGroups делит сумму по категориям или тегам. Подписка с несколькими
+// тегами входит в группу каждого из них, поэтому при группировке по тегам
+// итог групп может превышать общую сумму.
+func (u *UseCase) GetSumGroups(ctx context.Context, filter models.CostFilter, groupBy string) ([]*models.GroupCost, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetSumGroups")
+	defer span.End()
+
+	if groupBy != models.GroupByCategory && groupBy != models.GroupByTag {
+		return nil, subscriptions.ErrInvalidGroupBy
+	}
+
+	shares, err := u.costShares(ctx, u.normalizeCostFilter(ctx, filter))
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	totals := make(map[string]int)
+	for _, share := range shares {
+		if groupBy == models.GroupByCategory {
+			totals[share.sub.Category] += share.amount
+			continue
+		}
+		if len(share.sub.Tags) == 0 {
+			totals[""] += share.amount
+		}
+		for _, tag := range share.sub.Tags {
+			totals[tag] += share.amount
+		}
+	}
+
+	groups := make([]*models.GroupCost, 0, len(totals))
+	for group, total := range totals {
+		groups = append(groups, &models.GroupCost{Group: group, Total: total})
+	}
+	slices.SortFunc(groups, func(a, b *models.GroupCost) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), cmp.Compare(a.Group, b.Group))
+	})
+
+	return groups, nil
+}
+
+// costShare - часть цены подписки, которая приходится на пользователя.
+type costShare struct {
+	sub    *models.Subscription
+	userID uuid.UUID
+	amount int
+}
+
+// costShares раскладывает подписки, подходящие под filter, на доли
+// пользователей filter.UsersIDs (без них - всех). Кроме собственных подписок
+// учитываются общие подписки домохозяйств, где пользователю принадлежит доля.
+// Цена подписки берется так же, как в сумме хранилища (ReportPrice).
+func (u *UseCase) costShares(ctx context.Context, filter models.CostFilter) ([]costShare, error) {
+	from, to, err := models.ParsePeriod(filter.StartDate, filter.EndDate)
+	if err != nil {
+		u.log.WarnContext(ctx, err.Error())
+	}
+	userIDs := models.ParseUserIDs(filter.UsersIDs)
+
+	subs, err := u.repo.ListSubscriptions(ctx, models.SubscriptionFilter{
+		UserIDs:     userIDs,
+		ServiceName: filter.ServiceName,
+		Category:    filter.Category,
+		Tag:         filter.Tag,
+	})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.Subscription, len(subs))
+	for _, sub := range subs {
+		byID[sub.ID] = sub
+	}
+
+	splits, err := u.splits.ListSplits(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	splitByID := make(map[uuid.UUID]*models.Split, len(splits))
+	var shared []uuid.UUID
+	for _, split := range splits {
+		splitByID[split.SubscriptionID] = split
+		if _, ok := byID[split.SubscriptionID]; !ok {
+			shared = append(shared, split.SubscriptionID)
+		}
+	}
+
+	// Общие подписки других плательщиков читаются одним запросом.
+	if len(shared) > 0 {
+		sharedSubs, err := u.repo.ListSubscriptions(ctx, models.SubscriptionFilter{
+			IDs:         shared,
+			ServiceName: filter.ServiceName,
+			Category:    filter.Category,
+			Tag:         filter.Tag,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range sharedSubs {
+			byID[sub.ID] = sub
+		}
+	}
+
+	selected := func(userID uuid.UUID) bool {
+		return len(userIDs) == 0 || slices.Contains(userIDs, userID)
+	}
+
+	var shares []costShare
+	for _, sub := range byID {
+		if !sub.Within(from, to) {
+			continue
+		}
+		price := sub.ReportPrice()
+
+		split, shared := splitByID[sub.ID]
+		if !shared {
+			if selected(sub.UserID) {
+				shares = append(shares, costShare{sub: sub, userID: sub.UserID, amount: price})
+			}
+			continue
+		}
+
+		for userID, amount := range split.Amounts(price, sub.UserID) {
+			if selected(userID) {
+				shares = append(shares, costShare{sub: sub, userID: userID, amount: amount})
+			}
+		}
+	}
+
+	return shares, nil
+}
+
+// normalizeCostFilter приводит название сервиса, категорию и тег к виду,
+// в котором они хранятся в подписках.
+func (u *UseCase) normalizeCostFilter(ctx context.Context, filter models.CostFilter) models.CostFilter {
+	filter.ServiceName = u.canonicalName(ctx, filter.ServiceName)
+	filter.Category = models.NormalizeLabel(filter.Category)
+	filter.Tag = models.NormalizeLabel(filter.Tag)
+	return filter
+}
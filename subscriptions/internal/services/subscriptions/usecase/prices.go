@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"math"
+	"time"
+)
+
+// DeletePrice отменяет изменение цены, например запланированное на будущий
+// месяц. Начальную цену удалить нельзя, ее можно только заменить.
+func (u *UseCase) DeletePrice(ctx context.Context, id uuid.UUID, month time.Time) (*models.Subscription, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.DeletePrice", trace.WithAttributes(subscriptionIDAttr(id)))
+	defer span.End()
+
+	if id == uuid.Nil {
+		return nil, subscriptions.ErrIDRequired
+	}
+	month = monthStart(month)
+
+	var updated *models.Subscription
+	err := u.tx.Do(ctx, tx.Options{Isolation: tx.RepeatableRead}, func(ctx context.Context) error {
+		current, err := u.repo.GetSubscriptionByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !month.After(monthStart(current.StartDate.Time())) {
+			return subscriptions.ErrInitialPrice
+		}
+
+		updated, err = u.repo.DeletePrice(ctx, id, month)
+		return err
+	})
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	u.publish(ctx, events.TypeUpdated, updated)
+
+	return updated, nil
+}
+
+// priceUpdate забирает из обновления цену и месяц effective_from, с которого
+// она действует: цена меняется новым периодом в истории, а не колонкой
+// подписки.
+func priceUpdate(updates map[string]interface{}) (*int, *time.Time, error) {
+	value, repriced := updates["price"]
+	from, dated := updates["effective_from"]
+	delete(updates, "price")
+	delete(updates, "effective_from")
+
+	if !repriced {
+		if dated {
+			return nil, nil, subscriptions.ErrPriceRequired
+		}
+		return nil, nil, nil
+	}
+
+	var price int
+	switch v := value.(type) {
+	case int:
+		price = v
+	case int32:
+		price = int(v)
+	case int64:
+		price = int(v)
+	case float64:
+		if v != math.Trunc(v) || v > math.MaxInt32 || v < math.MinInt32 {
+			return nil, nil, fmt.Errorf("%w: invalid price", subscriptions.ErrInvalidArgument)
+		}
+		price = int(v)
+	case nil:
+		return nil, nil, subscriptions.ErrPriceRequired
+	default:
+		return nil, nil, fmt.Errorf("%w: invalid price", subscriptions.ErrInvalidArgument)
+	}
+	if price < 0 {
+		return nil, nil, subscriptions.ErrNegativePrice
+	}
+
+	if !dated || from == nil {
+		return &price, nil, nil
+	}
+	t, ok := from.(time.Time)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: invalid effective_from", subscriptions.ErrInvalidArgument)
+	}
+	return &price, &t, nil
+}
+
+// setPrice задает цену подписки с месяца from, без него - с текущего
+// месяца. У подписки, которая еще не началась, это начальная цена; цену
+// закончившейся подписки без from не изменить, чтобы не переписать прошлое.
+func (u *UseCase) setPrice(ctx context.Context, sub *models.Subscription, from *time.Time, price int) (*models.Subscription, error) {
+	start := monthStart(sub.StartDate.Time())
+
+	month := monthStart(time.Now().UTC())
+	if from != nil {
+		month = monthStart(*from)
+		if month.Before(start) {
+			return nil, subscriptions.ErrPriceBeforeStart
+		}
+	} else if month.Before(start) {
+		month = start
+	}
+	if sub.EndDate != nil && month.After(monthStart(sub.EndDate.Time())) {
+		return nil, subscriptions.ErrPriceAfterEnd
+	}
+
+	return u.repo.SetPrice(ctx, sub.ID, models.PricePeriod{EffectiveFrom: models.MonthYear(month), Price: price})
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"go.uber.org/fx"
+	"log/slog"
+	"time"
+)
+
+type StatsParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    metrics.Config
+	Logger    *slog.Logger
+	Repo      subscriptions.Repository
+	Metrics   *metrics.Metrics
+}
+
+// RunStatsRefresher периодически пересчитывает бизнес-метрики: число активных
+// подписок и их суммарную стоимость в текущем месяце.
+func RunStatsRefresher(params StatsParams) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	refresh := func() {
+		stats, err := params.Repo.GetSubscriptionStats(ctx, time.Now().UTC())
+		if err != nil {
+			if ctx.Err() == nil {
+				params.Logger.Warn("refresh subscription stats: " + err.Error())
+			}
+			return
+		}
+		params.Metrics.SetSubscriptionStats(stats.Active, stats.MonthlySpend)
+	}
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(params.Config.RefreshInterval)
+				defer ticker.Stop()
+
+				refresh()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						refresh()
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
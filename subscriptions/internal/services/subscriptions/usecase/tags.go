@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	"slices"
+)
+
+func (u *UseCase) ListTags(ctx context.Context) ([]*models.TagUsage, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.ListTags")
+	defer span.End()
+
+	result, err := u.repo.ListTags(ctx)
+	return result, recordError(span, err)
+}
+
+// RenameTag заменяет тег во всех подписках. Переименовать в уже
+// существующий тег нельзя: для этого есть MergeTags.
+func (u *UseCase) RenameTag(ctx context.Context, tag, newTag string) (*models.TagUsage, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.RenameTag")
+	defer span.End()
+
+	tag, newTag = models.NormalizeLabel(tag), models.NormalizeLabel(newTag)
+	if tag == "" || newTag == "" {
+		return nil, subscriptions.ErrTagRequired
+	}
+
+	var usage *models.TagUsage
+	err := u.tx.Do(ctx, tx.Options{Isolation: tx.RepeatableRead}, func(ctx context.Context) error {
+		if tag != newTag {
+			taken, err := u.repo.ListSubscriptions(ctx, models.SubscriptionFilter{Tag: newTag, Limit: 1})
+			if err != nil {
+				return err
+			}
+			if len(taken) > 0 {
+				u.log.WarnContext(ctx, "rename tag: tag "+newTag+" already exists")
+				return subscriptions.ErrTagExists
+			}
+		}
+
+		var err error
+		usage, err = u.retag(ctx, []string{tag}, newTag)
+		return err
+	})
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	return usage, nil
+}
+
+// MergeTags заменяет теги tags тегом into во всех подписках; into может
+// уже существовать.
+func (u *UseCase) MergeTags(ctx context.Context, tags []string, into string) (*models.TagUsage, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.MergeTags")
+	defer span.End()
+
+	into = models.NormalizeLabel(into)
+	if into == "" {
+		return nil, subscriptions.ErrTagRequired
+	}
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+	if len(tags) == 0 {
+		return nil, subscriptions.ErrTagRequired
+	}
+
+	var usage *models.TagUsage
+	err = u.tx.Do(ctx, tx.Options{Isolation: tx.RepeatableRead}, func(ctx context.Context) error {
+		var err error
+		usage, err = u.retag(ctx, tags, into)
+		return err
+	})
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	return usage, nil
+}
+
+// retag заменяет теги from тегом to. Подписки меняются через репозиторий
+// по одной, чтобы кэш и события видели каждое изменение; выборка по
+// следующему тегу уже видит результат предыдущих шагов.
+func (u *UseCase) retag(ctx context.Context, from []string, to string) (*models.TagUsage, error) {
+	found := false
+	for _, tag := range from {
+		subs, err := u.repo.ListSubscriptions(ctx, models.SubscriptionFilter{Tag: tag})
+		if err != nil {
+			return nil, err
+		}
+		if len(subs) > 0 {
+			found = true
+		}
+
+		for _, sub := range subs {
+			if tag == to {
+				continue
+			}
+			tags := slices.DeleteFunc(sub.Tags, func(t string) bool { return t == tag })
+			tags, _ = normalizeTags(append(tags, to))
+
+			updated, err := u.repo.UpdateSubscription(ctx, sub.ID, map[string]interface{}{"tags": tags})
+			if err != nil {
+				return nil, err
+			}
+			u.publish(ctx, events.TypeUpdated, updated)
+		}
+	}
+	if !found {
+		return nil, subscriptions.ErrTagNotFound
+	}
+
+	subs, err := u.repo.ListSubscriptions(ctx, models.SubscriptionFilter{Tag: to})
+	if err != nil {
+		return nil, err
+	}
+	return &models.TagUsage{Tag: to, Subscriptions: len(subs)}, nil
+}
+
+// normalizeTags приводит теги к виду хранения, убирает повторы
+// и сортирует их так же, как их отдает хранилище.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = models.NormalizeLabel(tag)
+		if tag == "" {
+			return nil, subscriptions.ErrTagRequired
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+// normalizeLabels приводит category и tags из обновления к виду хранения.
+// Теги приходят списком строк из JSON или []string; nil убирает все теги.
+func normalizeLabels(updates map[string]interface{}) error {
+	if value, ok := updates["category"]; ok {
+		category, ok := value.(string)
+		if value != nil && !ok {
+			return fmt.Errorf("%w: invalid category", subscriptions.ErrInvalidArgument)
+		}
+		updates["category"] = models.NormalizeLabel(category)
+	}
+
+	value, ok := updates["tags"]
+	if !ok {
+		return nil
+	}
+
+	var tags []string
+	switch v := value.(type) {
+	case nil:
+	case []string:
+		tags = v
+	case []interface{}:
+		for _, item := range v {
+			tag, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("%w: invalid tags", subscriptions.ErrInvalidArgument)
+			}
+			tags = append(tags, tag)
+		}
+	default:
+		return fmt.Errorf("%w: invalid tags", subscriptions.ErrInvalidArgument)
+	}
+
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	updates["tags"] = normalized
+	return nil
+}
@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	subscriptionRepo "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/repo"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace/noop"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+// recordingPublisher запоминает опубликованные события.
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e events.Event) error {
+	p.events = append(p.events, e)
+	return nil
+}
+
+// failingUpdates возвращает ошибку на failAt-м вызове UpdateSubscription.
+type failingUpdates struct {
+	subscriptions.Repository
+
+	calls  int
+	failAt int
+}
+
+func (r *failingUpdates) UpdateSubscription(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*models.Subscription, error) {
+	r.calls++
+	if r.calls == r.failAt {
+		return nil, errors.New("connection reset")
+	}
+	return r.Repository.UpdateSubscription(ctx, id, updates)
+}
+
+var initialTags = map[string][]string{
+	"s1": {"кино", "семья"},
+	"s2": {"кино"},
+	"s3": {"музыка", "семья"},
+	"s4": {"фильмы"},
+}
+
+// tagFixture - подписки с тегами, по которым проверяются переименование
+// и слияние.
+type tagFixture struct {
+	u         *UseCase
+	repo      *subscriptionRepo.MemoryRepository
+	published *recordingPublisher
+	ids       map[string]uuid.UUID
+}
+
+func newTagFixture(t *testing.T) *tagFixture {
+	t.Helper()
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := subscriptionRepo.NewMemoryRepository(subscriptionRepo.MemoryParams{Logger: log})
+	f := &tagFixture{
+		repo:      repo,
+		published: &recordingPublisher{},
+		ids:       make(map[string]uuid.UUID),
+	}
+	f.u = &UseCase{
+		log:    log,
+		repo:   repo,
+		events: f.published,
+		tx:     repo,
+		tracer: noop.NewTracerProvider().Tracer(tracerName),
+	}
+
+	price := 299
+	for name, tags := range initialTags {
+		sub, err := repo.CreateSubscription(ctx, &models.Subscription{
+			ID:          uuid.New(),
+			ServiceName: "Yandex Plus",
+			Price:       &price,
+			UserID:      uuid.New(),
+			StartDate:   models.MonthYear(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+			Tags:        slices.Clone(tags),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.ids[name] = sub.ID
+	}
+	return f
+}
+
+// tags возвращает теги подписок по их именам в фикстуре.
+func (f *tagFixture) tags(t *testing.T) map[string][]string {
+	t.Helper()
+	result := make(map[string][]string, len(f.ids))
+	for name, id := range f.ids {
+		sub, err := f.repo.GetSubscriptionByID(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result[name] = sub.Tags
+	}
+	return result
+}
+
+// withTags возвращает initialTags с заменой тегов перечисленных подписок.
+func withTags(changes map[string][]string) map[string][]string {
+	result := maps.Clone(initialTags)
+	maps.Copy(result, changes)
+	return result
+}
+
+func equalTags(a, b map[string][]string) bool {
+	return maps.EqualFunc(a, b, func(x, y []string) bool { return slices.Equal(x, y) })
+}
+
+func TestRenameTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		newTag  string
+		err     error
+		usage   models.TagUsage
+		tags    map[string][]string
+		updated int
+	}{
+		{
+			name:    "переименование",
+			tag:     "Кино",
+			newTag:  " Фильмы  Онлайн ",
+			usage:   models.TagUsage{Tag: "фильмы онлайн", Subscriptions: 2},
+			tags:    withTags(map[string][]string{"s1": {"семья", "фильмы онлайн"}, "s2": {"фильмы онлайн"}}),
+			updated: 2,
+		},
+		{name: "в тот же тег", tag: "кино", newTag: "КИНО", usage: models.TagUsage{Tag: "кино", Subscriptions: 2}, tags: initialTags},
+		{name: "в существующий тег", tag: "кино", newTag: "фильмы", err: subscriptions.ErrTagExists, tags: initialTags},
+		{name: "тега нет", tag: "сериалы", newTag: "шоу", err: subscriptions.ErrTagNotFound, tags: initialTags},
+		{name: "пустой тег", tag: " ", newTag: "шоу", err: subscriptions.ErrTagRequired, tags: initialTags},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTagFixture(t)
+
+			usage, err := f.u.RenameTag(context.Background(), tt.tag, tt.newTag)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if err == nil && *usage != tt.usage {
+				t.Errorf("usage = %+v, want %+v", *usage, tt.usage)
+			}
+			if got := f.tags(t); !equalTags(got, tt.tags) {
+				t.Errorf("tags = %v, want %v", got, tt.tags)
+			}
+			if len(f.published.events) != tt.updated {
+				t.Errorf("published events = %d, want %d", len(f.published.events), tt.updated)
+			}
+		})
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		into    string
+		err     error
+		usage   models.TagUsage
+		want    map[string][]string
+		updated int
+	}{
+		{
+			name:    "в один из объединяемых тегов",
+			tags:    []string{"кино", "фильмы"},
+			into:    "кино",
+			usage:   models.TagUsage{Tag: "кино", Subscriptions: 3},
+			want:    withTags(map[string][]string{"s4": {"кино"}}),
+			updated: 1,
+		},
+		{
+			name:    "тег не повторяется в подписке",
+			tags:    []string{"Кино", "семья"},
+			into:    "семья",
+			usage:   models.TagUsage{Tag: "семья", Subscriptions: 3},
+			want:    withTags(map[string][]string{"s1": {"семья"}, "s2": {"семья"}}),
+			updated: 2,
+		},
+		{
+			name:  "в новый тег",
+			tags:  []string{"кино", "семья"},
+			into:  "общее",
+			usage: models.TagUsage{Tag: "общее", Subscriptions: 3},
+			want: withTags(map[string][]string{
+				"s1": {"общее"},
+				"s2": {"общее"},
+				"s3": {"музыка", "общее"},
+			}),
+			updated: 4,
+		},
+		{
+			name:    "часть тегов не найдена",
+			tags:    []string{"кино", "сериалы"},
+			into:    "видео",
+			usage:   models.TagUsage{Tag: "видео", Subscriptions: 2},
+			want:    withTags(map[string][]string{"s1": {"видео", "семья"}, "s2": {"видео"}}),
+			updated: 2,
+		},
+		{name: "теги не найдены", tags: []string{"сериалы"}, into: "кино", err: subscriptions.ErrTagNotFound, want: initialTags},
+		{name: "без тегов", into: "кино", err: subscriptions.ErrTagRequired, want: initialTags},
+		{name: "пустой тег", tags: []string{"кино", " "}, into: "видео", err: subscriptions.ErrTagRequired, want: initialTags},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTagFixture(t)
+
+			usage, err := f.u.MergeTags(context.Background(), tt.tags, tt.into)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if err == nil && *usage != tt.usage {
+				t.Errorf("usage = %+v, want %+v", *usage, tt.usage)
+			}
+			if got := f.tags(t); !equalTags(got, tt.want) {
+				t.Errorf("tags = %v, want %v", got, tt.want)
+			}
+			if len(f.published.events) != tt.updated {
+				t.Errorf("published events = %d, want %d", len(f.published.events), tt.updated)
+			}
+		})
+	}
+}
+
+func TestMergeTagsRollback(t *testing.T) {
+	f := newTagFixture(t)
+	f.u.repo = &failingUpdates{Repository: f.repo, failAt: 3}
+
+	if _, err := f.u.MergeTags(context.Background(), []string{"кино", "семья"}, "общее"); err == nil {
+		t.Fatal("MergeTags succeeded, want error")
+	}
+
+	if got := f.tags(t); !equalTags(got, initialTags) {
+		t.Errorf("tags after rollback = %v, want %v", got, initialTags)
+	}
+	if len(f.published.events) != 0 {
+		t.Errorf("published %d events after rollback", len(f.published.events))
+	}
+}
@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/usecase"
+
+// recordError помечает спан ошибкой и возвращает ее без изменений,
+// чтобы можно было писать return recordError(span, err).
+func recordError(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func subscriptionIDAttr(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("subscription.id", id.String())
+}
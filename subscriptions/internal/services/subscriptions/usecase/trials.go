@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/google/uuid"
+	"time"
+)
+
+// TrialsEnding возвращает подписки пользователей userIDs (без них - всех),
+// пробный период которых заканчивается в месяце month: последний бесплатный
+// месяц, со следующего начинается оплата. Нужен напоминаниям и отчетам.
+func (u *UseCase) TrialsEnding(ctx context.Context, month time.Time, userIDs []uuid.UUID) ([]*models.Subscription, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.TrialsEnding")
+	defer span.End()
+
+	result, err := u.repo.ListSubscriptions(ctx, models.SubscriptionFilter{
+		UserIDs:  userIDs,
+		TrialEnd: monthStart(month),
+	})
+	return result, recordError(span, err)
+}
+
+// checkPromo проверяет промо-период: ограничения таблицы дают только общую
+// ошибку ErrInvalidPromo.
+func checkPromo(promo *models.Promo) error {
+	if promo == nil {
+		return nil
+	}
+	switch promo.Type {
+	case models.PromoPercentage:
+		if promo.Value < 0 || promo.Value > 100 {
+			return subscriptions.ErrPromoValue
+		}
+	case models.PromoFixed:
+		if promo.Value < 0 {
+			return subscriptions.ErrPromoValue
+		}
+	default:
+		return subscriptions.ErrInvalidPromo
+	}
+	if promo.Months <= 0 {
+		return subscriptions.ErrPromoMonths
+	}
+	return nil
+}
+
+// promoUpdate проверяет промо из обновления: *models.Promo задает
+// промо-период, nil убирает его.
+func promoUpdate(updates map[string]interface{}) error {
+	value, ok := updates["promo"]
+	if !ok || value == nil {
+		return nil
+	}
+	promo, ok := value.(*models.Promo)
+	if !ok {
+		return fmt.Errorf("%w: invalid promo", subscriptions.ErrInvalidArgument)
+	}
+	return checkPromo(promo)
+}
@@ -4,97 +4,464 @@ import (
 	"context"
 	"errors"
 	"github.com/ekkserapopova/subscriptions/internal/models"
-	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/repo"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/catalog"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	"github.com/ekkserapopova/subscriptions/pkg/logger"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"log/slog"
+	"maps"
 	"time"
 )
 
 type Params struct {
 	fx.In
 
-	Logger *slog.Logger
-	Repo   *repo.Repository
+	Logger         *slog.Logger
+	Repo           subscriptions.Repository
+	Events         events.Publisher
+	Tx             tx.Manager
+	Splits         subscriptions.SplitRepository
+	Catalog        subscriptions.ServiceCatalog
+	TracerProvider trace.TracerProvider
 }
 
 type UseCase struct {
-	log  *slog.Logger
-	repo *repo.Repository
+	log     *slog.Logger
+	repo    subscriptions.Repository
+	events  events.Publisher
+	tx      tx.Manager
+	splits  subscriptions.SplitRepository
+	catalog subscriptions.ServiceCatalog
+	tracer  trace.Tracer
 }
 
 func NewUseCase(params Params) *UseCase {
 	return &UseCase{
-		log:  params.Logger,
-		repo: params.Repo,
+		log:     params.Logger,
+		repo:    params.Repo,
+		events:  params.Events,
+		tx:      params.Tx,
+		splits:  params.Splits,
+		catalog: params.Catalog,
+		tracer:  params.TracerProvider.Tracer(tracerName),
 	}
 }
 
 func (u *UseCase) CreateSubscription(ctx context.Context, subscriptionData *models.Subscription) (*models.Subscription, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.CreateSubscription")
+	defer span.End()
+
+	logger.SetUserID(ctx, subscriptionData.UserID.String())
+
 	if subscriptionData.ID == uuid.Nil {
-		u.log.Warn("create subscription: id is nil")
+		u.log.WarnContext(ctx, "create subscription: id is nil")
 		subscriptionData.ID = uuid.New()
 	}
 
 	nilTime := time.Time{}
 
 	if subscriptionData.StartDate == models.MonthYear(nilTime) {
-		u.log.Warn("create subscription: start date is nil")
-		return nil, errors.New("start date is nil")
+		u.log.WarnContext(ctx, "create subscription: start date is nil")
+		return nil, subscriptions.ErrStartDateRequired
 	}
 
-	createdSubscription, err := u.repo.CreateSubscription(ctx, subscriptionData)
-	if err != nil {
+	if subscriptionData.Price != nil && *subscriptionData.Price < 0 {
+		u.log.WarnContext(ctx, "create subscription: price is negative")
+		return nil, subscriptions.ErrNegativePrice
+	}
+
+	if subscriptionData.EndDate != nil && subscriptionData.EndDate.Time().Before(subscriptionData.StartDate.Time()) {
+		u.log.WarnContext(ctx, "create subscription: end date is before start date")
+		return nil, subscriptions.ErrEndBeforeStart
+	}
+
+	if subscriptionData.TrialEnd != nil && subscriptionData.TrialEnd.Time().Before(subscriptionData.StartDate.Time()) {
+		u.log.WarnContext(ctx, "create subscription: trial end is before start date")
+		return nil, subscriptions.ErrTrialBeforeStart
+	}
+
+	if err := checkPromo(subscriptionData.Promo); err != nil {
+		u.log.WarnContext(ctx, "create subscription: "+err.Error())
 		return nil, err
 	}
 
+	tags, err := normalizeTags(subscriptionData.Tags)
+	if err != nil {
+		u.log.WarnContext(ctx, "create subscription: "+err.Error())
+		return nil, recordError(span, err)
+	}
+	subscriptionData.Tags = tags
+	subscriptionData.Category = models.NormalizeLabel(subscriptionData.Category)
+
+	if err := u.linkService(ctx, subscriptionData); err != nil {
+		return nil, recordError(span, err)
+	}
+
+	// Теги пишутся отдельно от подписки, поэтому вставка идет в транзакции.
+	var createdSubscription *models.Subscription
+	err = u.tx.Do(ctx, tx.Options{}, func(ctx context.Context) error {
+		var err error
+		createdSubscription, err = u.repo.CreateSubscription(ctx, subscriptionData)
+		return err
+	})
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	u.publish(ctx, events.TypeCreated, createdSubscription)
+
 	return createdSubscription, nil
 }
 
 func (u *UseCase) UpdateSubscription(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*models.Subscription, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.UpdateSubscription", trace.WithAttributes(subscriptionIDAttr(id)))
+	defer span.End()
+
 	if id == uuid.Nil {
-		u.log.Warn("update subscription: id is nil")
-		return nil, errors.New("id is required")
+		u.log.WarnContext(ctx, "update subscription: id is nil")
+		return nil, subscriptions.ErrIDRequired
 	}
 
 	if len(updates) == 0 {
-		u.log.Warn("update subscription: no fields to update")
-		return nil, errors.New("no fields to update")
+		u.log.WarnContext(ctx, "update subscription: no fields to update")
+		return nil, subscriptions.ErrNoFieldsToUpdate
 	}
 
 	if startDate, ok := updates["start_date"]; ok {
 		if t, ok := startDate.(time.Time); ok && t.IsZero() {
-			u.log.Warn("update subscription: start date is nil")
-			return nil, errors.New("start date is nil")
+			u.log.WarnContext(ctx, "update subscription: start date is nil")
+			return nil, subscriptions.ErrStartDateRequired
 		}
 	}
 
-	updatedSubscription, err := u.repo.UpdateSubscription(ctx, id, updates)
+	if err := normalizeLabels(updates); err != nil {
+		u.log.WarnContext(ctx, "update subscription: "+err.Error())
+		return nil, err
+	}
+
+	if err := promoUpdate(updates); err != nil {
+		u.log.WarnContext(ctx, "update subscription: "+err.Error())
+		return nil, err
+	}
+
+	price, priceFrom, err := priceUpdate(updates)
 	if err != nil {
+		u.log.WarnContext(ctx, "update subscription: "+err.Error())
 		return nil, err
 	}
 
+	// Даты сверяются с текущей версией подписки, поэтому чтение и запись
+	// идут в одной транзакции. Новая цена записывается после остальных
+	// полей: месяц ее начала сверяется с обновленными датами.
+	var updatedSubscription *models.Subscription
+	err = u.tx.Do(ctx, tx.Options{Isolation: tx.RepeatableRead}, func(ctx context.Context) error {
+		current, err := u.repo.GetSubscriptionByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkDates(current, updates); err != nil {
+			u.log.WarnContext(ctx, "update subscription: "+err.Error())
+			return err
+		}
+
+		// Связь с каталогом и категория дописываются в копию: при повторе
+		// транзакции они вычисляются заново по свежей версии подписки.
+		fields := maps.Clone(updates)
+		updatedSubscription = current
+		if len(fields) > 0 {
+			if err := u.linkServiceUpdate(ctx, fields); err != nil {
+				return err
+			}
+			if err := u.categoryUpdate(ctx, current, fields); err != nil {
+				return err
+			}
+
+			updatedSubscription, err = u.repo.UpdateSubscription(ctx, id, fields)
+			if err != nil {
+				return err
+			}
+		}
+
+		if price != nil {
+			updatedSubscription, err = u.setPrice(ctx, updatedSubscription, priceFrom, *price)
+			if err != nil {
+				u.log.WarnContext(ctx, "update subscription: "+err.Error())
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	logger.SetUserID(ctx, updatedSubscription.UserID.String())
+	u.publish(ctx, events.TypeUpdated, updatedSubscription)
+
 	return updatedSubscription, nil
 }
 
 func (u *UseCase) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetSubscriptionByID", trace.WithAttributes(subscriptionIDAttr(id)))
+	defer span.End()
+
 	if id == uuid.Nil {
-		return nil, errors.New("id is required")
+		return nil, subscriptions.ErrIDRequired
+	}
+	subscription, err := u.repo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, recordError(span, err)
 	}
-	return u.repo.GetSubscriptionByID(ctx, id)
+
+	logger.SetUserID(ctx, subscription.UserID.String())
+	return subscription, nil
 }
 
 func (u *UseCase) GetAllSubscriptions(ctx context.Context) ([]*models.Subscription, error) {
-	return u.repo.GetAllSubscriptions(ctx)
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetAllSubscriptions")
+	defer span.End()
+
+	result, err := u.repo.GetAllSubscriptions(ctx)
+	return result, recordError(span, err)
+}
+
+func (u *UseCase) ListSubscriptions(ctx context.Context, filter models.SubscriptionFilter) ([]*models.Subscription, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.ListSubscriptions")
+	defer span.End()
+
+	filter.ServiceName = u.canonicalName(ctx, filter.ServiceName)
+	filter.Category = models.NormalizeLabel(filter.Category)
+	filter.Tag = models.NormalizeLabel(filter.Tag)
+	result, err := u.repo.ListSubscriptions(ctx, filter)
+	return result, recordError(span, err)
 }
 
 func (u *UseCase) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	ctx, span := u.tracer.Start(ctx, "UseCase.DeleteSubscription", trace.WithAttributes(subscriptionIDAttr(id)))
+	defer span.End()
+
 	if id == uuid.Nil {
-		return errors.New("id is required")
+		return subscriptions.ErrIDRequired
 	}
-	return u.repo.DeleteSubscription(ctx, id)
+
+	deletedSubscription, err := u.repo.DeleteSubscription(ctx, id)
+	if err != nil {
+		return recordError(span, err)
+	}
+
+	logger.SetUserID(ctx, deletedSubscription.UserID.String())
+	u.publish(ctx, events.TypeDeleted, deletedSubscription)
+
+	return nil
 }
 
-func (u *UseCase) GetSumSubscriptions(ctx context.Context, startDate, endDate, name, usersIds string) (int, error) {
-	return u.repo.GetSumSubscriptions(ctx, startDate, endDate, name, usersIds)
+// GetSumSubscriptions без фильтров по пользователям, категории и тегу
+// считается хранилищем: доли общей подписки в сумме дают ее цену.
+// С фильтром по пользователям каждому засчитывается только его доля
+// в общих подписках.
+func (u *UseCase) GetSumSubscriptions(ctx context.Context, filter models.CostFilter) (int, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetSumSubscriptions")
+	defer span.End()
+
+	filter = u.normalizeCostFilter(ctx, filter)
+	if len(models.ParseUserIDs(filter.UsersIDs)) == 0 && filter.Category == "" && filter.Tag == "" {
+		sum, err := u.repo.GetSumSubscriptions(ctx, filter.StartDate, filter.EndDate, filter.ServiceName, filter.UsersIDs)
+		return sum, recordError(span, err)
+	}
+
+	shares, err := u.costShares(ctx, filter)
+	if err != nil {
+		return 0, recordError(span, err)
+	}
+
+	sum := 0
+	for _, share := range shares {
+		sum += share.amount
+	}
+	return sum, nil
+}
+
+// linkService связывает подписку с каталогом: по service_id, если он
+// указан, иначе по названию. Связанная подписка получает каноническое
+// название, неизвестный сервис остается свободным текстом.
+func (u *UseCase) linkService(ctx context.Context, sub *models.Subscription) error {
+	var (
+		service *models.Service
+		err     error
+	)
+	if sub.ServiceID != nil {
+		service, err = u.catalog.GetServiceByID(ctx, *sub.ServiceID)
+	} else {
+		service, err = u.catalog.FindServiceByName(ctx, sub.ServiceName)
+	}
+	if errors.Is(err, catalog.ErrNotFound) {
+		if sub.ServiceID != nil {
+			u.log.WarnContext(ctx, "link subscription: service "+sub.ServiceID.String()+" not found")
+			return subscriptions.ErrServiceNotFound
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sub.ServiceID = &service.ID
+	sub.ServiceName = service.Name
+	if sub.Category == "" {
+		sub.Category = service.Category
+	}
+	return nil
+}
+
+// linkServiceUpdate дополняет обновление так же, как linkService: новый
+// service_id задает название, новое название заново ищется в каталоге.
+// service_id = nil отвязывает подписку, не меняя название.
+func (u *UseCase) linkServiceUpdate(ctx context.Context, updates map[string]interface{}) error {
+	if value, ok := updates["service_id"]; ok {
+		id, ok := value.(uuid.UUID)
+		if !ok {
+			updates["service_id"] = nil
+			return nil
+		}
+		sub := &models.Subscription{ServiceID: &id}
+		if err := u.linkService(ctx, sub); err != nil {
+			return err
+		}
+		updates["service_name"] = sub.ServiceName
+		return nil
+	}
+
+	name, ok := updates["service_name"].(string)
+	if !ok {
+		return nil
+	}
+	sub := &models.Subscription{ServiceName: name}
+	if err := u.linkService(ctx, sub); err != nil {
+		return err
+	}
+	updates["service_name"] = sub.ServiceName
+	if sub.ServiceID != nil {
+		updates["service_id"] = *sub.ServiceID
+	} else {
+		updates["service_id"] = nil
+	}
+	return nil
+}
+
+// categoryUpdate дополняет обновление категорией из каталога: пустая
+// категория сбрасывается на категорию сервиса, а при смене сервиса
+// категория следует за ним, если не была задана вручную.
+func (u *UseCase) categoryUpdate(ctx context.Context, current *models.Subscription, updates map[string]interface{}) error {
+	serviceID := current.ServiceID
+	value, relinked := updates["service_id"]
+	if relinked {
+		serviceID = nil
+		if id, ok := value.(uuid.UUID); ok {
+			serviceID = &id
+		}
+	}
+
+	if category, ok := updates["category"]; ok {
+		if category != "" {
+			return nil
+		}
+	} else {
+		if !relinked {
+			return nil
+		}
+		inherited, err := u.serviceCategory(ctx, current.ServiceID)
+		if err != nil {
+			return err
+		}
+		if current.Category != inherited {
+			return nil
+		}
+	}
+
+	category, err := u.serviceCategory(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+	updates["category"] = category
+	return nil
+}
+
+// serviceCategory возвращает категорию сервиса каталога или пустую
+// строку, если подписка с каталогом не связана.
+func (u *UseCase) serviceCategory(ctx context.Context, id *uuid.UUID) (string, error) {
+	if id == nil {
+		return "", nil
+	}
+	service, err := u.catalog.GetServiceByID(ctx, *id)
+	if errors.Is(err, catalog.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return service.Category, nil
+}
+
+// canonicalName заменяет название из фильтра каноническим, чтобы отчеты
+// по "yandex plus" и "Яндекс Плюс" находили одни и те же подписки. Если
+// каталог недоступен, фильтр остается как есть.
+func (u *UseCase) canonicalName(ctx context.Context, name string) string {
+	if name == "" {
+		return name
+	}
+	service, err := u.catalog.FindServiceByName(ctx, name)
+	if err != nil {
+		if !errors.Is(err, catalog.ErrNotFound) {
+			u.log.WarnContext(ctx, "failed to resolve service name: "+err.Error())
+		}
+		return name
+	}
+	return service.Name
+}
+
+// publish вызывается после успешной записи: ошибка отправки события
+// логируется, но не отменяет уже сохраненное изменение. Внутри транзакции
+// событие уходит после фиксации.
+func (u *UseCase) publish(ctx context.Context, eventType events.Type, sub *models.Subscription) {
+	tx.AfterCommit(ctx, func(ctx context.Context) {
+		if err := u.events.Publish(ctx, events.NewEvent(eventType, sub)); err != nil {
+			u.log.WarnContext(ctx, "failed to publish "+string(eventType)+" event: "+err.Error())
+		}
+	})
+}
+
+// checkDates проверяет, что после обновления конец подписки и конец
+// пробного периода не раньше начала.
+func checkDates(current *models.Subscription, updates map[string]interface{}) error {
+	start := current.StartDate.Time()
+	if value, ok := updates["start_date"].(time.Time); ok {
+		start = value
+	}
+
+	if end := updatedDate(current.EndDate, updates, "end_date"); end != nil && end.Before(start) {
+		return subscriptions.ErrEndBeforeStart
+	}
+	if trialEnd := updatedDate(current.TrialEnd, updates, "trial_end"); trialEnd != nil && trialEnd.Before(start) {
+		return subscriptions.ErrTrialBeforeStart
+	}
+	return nil
+}
+
+// updatedDate возвращает необязательную дату field после обновления.
+func updatedDate(current *models.MonthYear, updates map[string]interface{}, field string) *time.Time {
+	value, ok := updates[field]
+	if !ok {
+		if current == nil {
+			return nil
+		}
+		return current.PtrTime()
+	}
+	if t, ok := value.(time.Time); ok {
+		return &t
+	}
+	return nil
 }
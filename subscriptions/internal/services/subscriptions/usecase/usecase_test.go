@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	catalogRepo "github.com/ekkserapopova/subscriptions/internal/services/catalog/repo"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	subscriptionRepo "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/repo"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace/noop"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// retryManager выполняет fn дважды, как tx.Run после конфликта, и дает
+// изменить данные между попытками.
+type retryManager struct {
+	between func(ctx context.Context)
+}
+
+func (m retryManager) Do(ctx context.Context, _ tx.Options, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	m.between(ctx)
+	return fn(ctx)
+}
+
+func TestUpdateSubscriptionRetry(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := subscriptionRepo.NewMemoryRepository(subscriptionRepo.MemoryParams{Logger: log})
+	catalog := catalogRepo.NewMemoryRepository(catalogRepo.MemoryParams{Logger: log})
+
+	price := 399
+	sub, err := repo.CreateSubscription(ctx, &models.Subscription{
+		ID:          uuid.New(),
+		ServiceName: "Yandex Plus",
+		Category:    "streaming",
+		Price:       &price,
+		UserID:      uuid.New(),
+		StartDate:   models.MonthYear(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := &UseCase{
+		log:     log,
+		repo:    repo,
+		events:  events.NewLocalPublisher(events.NewBroker(events.BrokerParams{Config: events.Config{LogSize: 1, BufferSize: 1}, Logger: log})),
+		catalog: catalog,
+		tracer:  noop.NewTracerProvider().Tracer(tracerName),
+		// Между попытками категорию задают вручную: повтор должен ее
+		// сохранить, а не подставить категорию, вычисленную в первой попытке.
+		tx: retryManager{between: func(ctx context.Context) {
+			if _, err := repo.UpdateSubscription(ctx, sub.ID, map[string]interface{}{
+				"service_name": "Yandex Plus",
+				"service_id":   nil,
+				"category":     "семья",
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+
+	updates := map[string]interface{}{"service_name": "Dropbox"}
+	updated, err := u.UpdateSubscription(ctx, sub.ID, updates)
+	if err != nil {
+		t.Fatalf("UpdateSubscription: %v", err)
+	}
+
+	if len(updates) != 1 {
+		t.Errorf("caller's updates were modified: %v", updates)
+	}
+	if updated.ServiceName != "Dropbox" || updated.ServiceID == nil {
+		t.Errorf("service = %q %v, want linked Dropbox", updated.ServiceName, updated.ServiceID)
+	}
+	if updated.Category != "семья" {
+		t.Errorf("category = %q, want семья", updated.Category)
+	}
+}
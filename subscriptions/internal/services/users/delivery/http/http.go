@@ -0,0 +1,274 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/services/users"
+	"github.com/ekkserapopova/subscriptions/pkg/reader"
+	"github.com/ekkserapopova/subscriptions/pkg/responser"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/fx"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Params struct {
+	fx.In
+
+	Logger  *slog.Logger
+	UseCase users.UseCase
+}
+
+type Handler struct {
+	logger  *slog.Logger
+	usecase users.UseCase
+}
+
+func NewHandler(params Params) *Handler {
+	return &Handler{
+		logger:  params.Logger,
+		usecase: params.UseCase,
+	}
+}
+
+// CreateUser godoc
+// @Summary Создать пользователя
+// @Description Создает пользователя. Валюта, локаль и часовой пояс по умолчанию: RUB, ru-RU, Europe/Moscow
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body models.User true "Пользователь"
+// @Success 201 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users [post]
+func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	user := &models.User{}
+	if err := reader.ReadResponseData(r, user); err != nil {
+		h.logger.ErrorContext(r.Context(), "create user request err: "+err.Error())
+		responser.SendErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.usecase.CreateUser(r.Context(), user)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusCreated, created)
+}
+
+// @Summary Изменить пользователя
+// @Description Изменяет переданные поля пользователя
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param user body models.UserUpdate true "Изменяемые поля"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id} [put]
+func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	update := models.UserUpdate{}
+	if err := reader.ReadResponseData(r, &update); err != nil {
+		h.logger.ErrorContext(r.Context(), "update user request err: "+err.Error())
+		responser.SendErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updated, err := h.usecase.UpdateUser(r.Context(), id, update)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, updated)
+}
+
+// @Summary Получить пользователя
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id} [get]
+func (h *Handler) GetUserByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.usecase.GetUserByID(r.Context(), id)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, user)
+}
+
+// @Summary Получить всех пользователей
+// @Tags users
+// @Produce json
+// @Success 200 {array} models.User
+// @Failure 500 {object} map[string]string
+// @Router /users [get]
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	list, err := h.usecase.ListUsers(r.Context())
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, list)
+}
+
+// @Summary Удалить пользователя
+// @Description Пользователя с подписками можно удалить только с cascade=true, тогда его подписки удаляются вместе с ним
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param cascade query bool false "Удалить и подписки пользователя"
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id} [delete]
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	cascade := false
+	if value := r.URL.Query().Get("cascade"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			responser.SendErr(w, http.StatusBadRequest, "invalid cascade")
+			return
+		}
+		cascade = parsed
+	}
+
+	if err := h.usecase.DeleteUser(r.Context(), id, cascade); err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusNoContent, map[string]string{"msg": "user deleted"})
+}
+
+// @Summary Подписки пользователя
+// @Description Подписки пользователя по возрастанию ID. Для следующей страницы передайте after_id — ID последней полученной подписки
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param limit query int false "Максимум подписок в ответе"
+// @Param after_id query string false "ID подписки, после которой начинать"
+// @Success 200 {array} models.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/subscriptions [get]
+func (h *Handler) GetUserSubscriptions(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	filter := models.SubscriptionFilter{}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			responser.SendErr(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+	if value := r.URL.Query().Get("after_id"); value != "" {
+		afterID, err := uuid.Parse(value)
+		if err != nil {
+			responser.SendErr(w, http.StatusBadRequest, "invalid after_id format")
+			return
+		}
+		filter.AfterID = afterID
+	}
+
+	subs, err := h.usecase.GetUserSubscriptions(r.Context(), id, filter)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+	if subs == nil {
+		subs = []*models.Subscription{}
+	}
+
+	responser.SendOK(w, http.StatusOK, subs)
+}
+
+// @Summary Сводка по подпискам пользователя
+// @Description Число подписок и их стоимость в текущем месяце пользователя, в его валюте. Подписки на сервисы каталога в других валютах не пересчитываются и возвращаются в other_currencies
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} models.UserSummary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/summary [get]
+func (h *Handler) GetUserSummary(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := h.usecase.GetUserSummary(r.Context(), id)
+	if err != nil {
+		responser.SendErr(w, statusFromError(err), err.Error())
+		return
+	}
+
+	responser.SendOK(w, http.StatusOK, summary)
+}
+
+func (h *Handler) userID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responser.SendErr(w, http.StatusBadRequest, "invalid id format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, users.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, users.ErrAlreadyExists),
+		errors.Is(err, users.ErrEmailTaken),
+		errors.Is(err, users.ErrHasSubscriptions):
+		return http.StatusConflict
+	case errors.Is(err, users.ErrInvalidArgument):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
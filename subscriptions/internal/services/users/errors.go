@@ -0,0 +1,23 @@
+package users
+
+import (
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/errs"
+)
+
+var (
+	ErrNotFound        = errors.New("user not found")
+	ErrAlreadyExists   = errors.New("user with this id already exists")
+	ErrEmailTaken      = errors.New("user with this email already exists")
+	ErrInvalidArgument = errs.ErrInvalidArgument
+	// ErrHasSubscriptions возвращается при удалении без cascade.
+	ErrHasSubscriptions = errors.New("user has subscriptions, delete them first or pass cascade=true")
+)
+
+var (
+	ErrNameRequired     = errs.InvalidArgument("name is required")
+	ErrInvalidCurrency  = errs.InvalidArgument("currency must be an ISO 4217 code")
+	ErrInvalidLocale    = errs.InvalidArgument("invalid locale")
+	ErrInvalidTimeZone  = errs.InvalidArgument("unknown time zone")
+	ErrNoFieldsToUpdate = errs.InvalidArgument("no fields to update")
+)
@@ -0,0 +1,26 @@
+package users
+
+import (
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
+	UpdateUser(ctx context.Context, id uuid.UUID, update models.UserUpdate) (*models.User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	ListUsers(ctx context.Context) ([]*models.User, error)
+	DeleteUser(ctx context.Context, id uuid.UUID, cascade bool) error
+	GetUserSubscriptions(ctx context.Context, id uuid.UUID, filter models.SubscriptionFilter) ([]*models.Subscription, error)
+	GetUserSummary(ctx context.Context, id uuid.UUID) (*models.UserSummary, error)
+}
+
+type Repository interface {
+	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
+	CopyUsers(ctx context.Context, users []*models.User) (int64, error)
+	UpdateUser(ctx context.Context, id uuid.UUID, update models.UserUpdate) (*models.User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	ListUsers(ctx context.Context) ([]*models.User, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) (*models.User, error)
+}
@@ -0,0 +1,199 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/users"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MemoryParams struct {
+	fx.In
+
+	Logger *slog.Logger
+}
+
+// MemoryRepository хранит пользователей в памяти процесса. Транзакциями
+// управляет хранилище подписок, поэтому изменения внутри tx.Manager.Do
+// отменяются через tx.OnRollback.
+type MemoryRepository struct {
+	log *slog.Logger
+
+	mu    sync.RWMutex
+	users map[uuid.UUID]*models.User
+}
+
+func NewMemoryRepository(params MemoryParams) *MemoryRepository {
+	return &MemoryRepository{
+		log:   params.Logger,
+		users: make(map[uuid.UUID]*models.User),
+	}
+}
+
+func (repo *MemoryRepository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	created := *user
+	if err := repo.checkUnique(&created); err != nil {
+		repo.log.WarnContext(ctx, "create user: "+err.Error())
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	created.CreatedAt, created.UpdatedAt = now, now
+	repo.put(ctx, &created)
+
+	result := created
+	return &result, nil
+}
+
+// CopyUsers вставляет всех пользователей или ни одного.
+func (repo *MemoryRepository) CopyUsers(ctx context.Context, list []*models.User) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	batch := make([]*models.User, 0, len(list))
+	seen := make(map[uuid.UUID]struct{}, len(list))
+	for _, u := range list {
+		user := *u
+		if _, ok := seen[user.ID]; ok {
+			return 0, users.ErrAlreadyExists
+		}
+		seen[user.ID] = struct{}{}
+		if err := repo.checkUnique(&user); err != nil {
+			return 0, err
+		}
+		batch = append(batch, &user)
+	}
+
+	now := time.Now().UTC()
+	for _, user := range batch {
+		user.CreatedAt, user.UpdatedAt = now, now
+		repo.put(ctx, user)
+	}
+
+	return int64(len(batch)), nil
+}
+
+func (repo *MemoryRepository) UpdateUser(ctx context.Context, id uuid.UUID, update models.UserUpdate) (*models.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	current, ok := repo.users[id]
+	if !ok {
+		return nil, users.ErrNotFound
+	}
+
+	updated := *current
+	update.Apply(&updated)
+	if updated.Email != "" && !strings.EqualFold(updated.Email, current.Email) && repo.emailTaken(updated.Email) {
+		return nil, users.ErrEmailTaken
+	}
+
+	updated.UpdatedAt = time.Now().UTC()
+	repo.put(ctx, &updated)
+
+	result := updated
+	return &result, nil
+}
+
+func (repo *MemoryRepository) GetUserByID(_ context.Context, id uuid.UUID) (*models.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	user, ok := repo.users[id]
+	if !ok {
+		return nil, users.ErrNotFound
+	}
+	result := *user
+	return &result, nil
+}
+
+func (repo *MemoryRepository) ListUsers(context.Context) ([]*models.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	list := make([]*models.User, 0, len(repo.users))
+	for _, user := range repo.users {
+		clone := *user
+		list = append(list, &clone)
+	}
+	slices.SortFunc(list, func(a, b *models.User) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	return list, nil
+}
+
+// DeleteUser не проверяет подписки: в памяти внешнего ключа нет, эту
+// проверку делает UseCase.DeleteUser.
+func (repo *MemoryRepository) DeleteUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[id]
+	if !ok {
+		return nil, users.ErrNotFound
+	}
+	delete(repo.users, id)
+	tx.OnRollback(ctx, func() {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		repo.users[id] = user
+	})
+
+	return user, nil
+}
+
+// UserExists нужен хранилищу подписок вместо внешнего ключа.
+func (repo *MemoryRepository) UserExists(id uuid.UUID) bool {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	_, ok := repo.users[id]
+	return ok
+}
+
+// put сохраняет пользователя и при откате транзакции возвращает прежнее
+// состояние записи.
+func (repo *MemoryRepository) put(ctx context.Context, user *models.User) {
+	previous, existed := repo.users[user.ID]
+	repo.users[user.ID] = user
+	tx.OnRollback(ctx, func() {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		if existed {
+			repo.users[user.ID] = previous
+		} else {
+			delete(repo.users, user.ID)
+		}
+	})
+}
+
+func (repo *MemoryRepository) checkUnique(user *models.User) error {
+	if _, ok := repo.users[user.ID]; ok {
+		return users.ErrAlreadyExists
+	}
+	if user.Email != "" && repo.emailTaken(user.Email) {
+		return users.ErrEmailTaken
+	}
+	return nil
+}
+
+func (repo *MemoryRepository) emailTaken(email string) bool {
+	for _, user := range repo.users {
+		if strings.EqualFold(user.Email, email) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/services/users"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/fx"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var userColumns = []string{
+	"id", "name", "COALESCE(email, '')", "currency", "locale", "time_zone", "created_at", "updated_at",
+}
+
+var returningUser = "RETURNING " + strings.Join(userColumns, ", ")
+
+type Params struct {
+	fx.In
+
+	Logger  *slog.Logger
+	Cluster *db.Cluster
+	Builder squirrel.StatementBuilderType
+	Metrics *metrics.Metrics
+}
+
+type Repository struct {
+	cluster *db.Cluster
+	log     *slog.Logger
+	builder squirrel.StatementBuilderType
+	metrics *metrics.Metrics
+}
+
+func NewRepository(params Params) *Repository {
+	return &Repository{
+		cluster: params.Cluster,
+		log:     params.Logger,
+		builder: params.Builder,
+		metrics: params.Metrics,
+	}
+}
+
+func (repo *Repository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	defer repo.metrics.ObserveQuery("CreateUser", time.Now())
+
+	query, args, err := repo.builder.
+		Insert("users").
+		Columns("id", "name", "email", "currency", "locale", "time_zone").
+		Values(user.ID, user.Name, nullString(user.Email), user.Currency, user.Locale, user.TimeZone).
+		Suffix(returningUser).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	created, err := scanUser(repo.cluster.Writer(ctx).QueryRow(ctx, query, args...))
+	if err != nil {
+		if domainErr := violation(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "create user: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to create user: "+err.Error())
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// CopyUsers вставляет пользователей одним COPY, для массовой загрузки.
+func (repo *Repository) CopyUsers(ctx context.Context, list []*models.User) (int64, error) {
+	defer repo.metrics.ObserveQuery("CopyUsers", time.Now())
+
+	rows := make([][]any, 0, len(list))
+	for _, user := range list {
+		rows = append(rows, []any{user.ID, user.Name, nullString(user.Email), user.Currency, user.Locale, user.TimeZone})
+	}
+
+	copied, err := repo.cluster.Writer(ctx).CopyFrom(
+		ctx,
+		pgx.Identifier{"users"},
+		[]string{"id", "name", "email", "currency", "locale", "time_zone"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		if domainErr := violation(err); domainErr != nil {
+			return 0, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to copy users: "+err.Error())
+		return 0, err
+	}
+
+	return copied, nil
+}
+
+func (repo *Repository) UpdateUser(ctx context.Context, id uuid.UUID, update models.UserUpdate) (*models.User, error) {
+	defer repo.metrics.ObserveQuery("UpdateUser", time.Now())
+
+	builder := repo.builder.Update("users")
+	if update.Name != nil {
+		builder = builder.Set("name", *update.Name)
+	}
+	if update.Email != nil {
+		builder = builder.Set("email", nullString(*update.Email))
+	}
+	if update.Currency != nil {
+		builder = builder.Set("currency", *update.Currency)
+	}
+	if update.Locale != nil {
+		builder = builder.Set("locale", *update.Locale)
+	}
+	if update.TimeZone != nil {
+		builder = builder.Set("time_zone", *update.TimeZone)
+	}
+
+	query, args, err := builder.
+		Set("updated_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": id}).
+		Suffix(returningUser).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	updated, err := scanUser(repo.cluster.Writer(ctx).QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, users.ErrNotFound
+		}
+		if domainErr := violation(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "update user: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to update user: "+err.Error())
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (repo *Repository) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	defer repo.metrics.ObserveQuery("GetUserByID", time.Now())
+
+	query, args, err := repo.builder.
+		Select(userColumns...).
+		From("users").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := scanUser(repo.cluster.Reader(ctx).QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, users.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (repo *Repository) ListUsers(ctx context.Context) ([]*models.User, error) {
+	defer repo.metrics.ObserveQuery("ListUsers", time.Now())
+
+	query, args, err := repo.builder.
+		Select(userColumns...).
+		From("users").
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.cluster.Reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to list users: "+err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, user)
+	}
+
+	return list, rows.Err()
+}
+
+func (repo *Repository) DeleteUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	defer repo.metrics.ObserveQuery("DeleteUser", time.Now())
+
+	query, args, err := repo.builder.
+		Delete("users").
+		Where(squirrel.Eq{"id": id}).
+		Suffix(returningUser).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := scanUser(repo.cluster.Writer(ctx).QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, users.ErrNotFound
+		}
+		if domainErr := violation(err); domainErr != nil {
+			return nil, domainErr
+		}
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	user := &models.User{}
+	if err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Currency,
+		&user.Locale,
+		&user.TimeZone,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// violation переводит нарушения ограничений таблицы в доменные ошибки.
+// Удаление пользователя с подписками упирается во внешний ключ.
+func violation(err error) error {
+	pgErr := &pgconn.PgError{}
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	switch pgErr.Code {
+	case "23505":
+		if pgErr.ConstraintName == "users_email_idx" {
+			return users.ErrEmailTaken
+		}
+		return users.ErrAlreadyExists
+	case "23503":
+		return users.ErrHasSubscriptions
+	case "23514":
+		if pgErr.ConstraintName == "users_currency_format" {
+			return users.ErrInvalidCurrency
+		}
+		return users.ErrInvalidArgument
+	default:
+		return nil
+	}
+}
+
+// nullString хранит пустой email как NULL, чтобы уникальный индекс
+// не мешал пользователям без почты.
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,277 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/db"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/metrics"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/users"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"log/slog"
+	sqlite3 "modernc.org/sqlite/lib"
+	"time"
+)
+
+type SQLiteParams struct {
+	fx.In
+
+	Logger  *slog.Logger
+	DB      *sql.DB
+	Metrics *metrics.Metrics
+}
+
+type SQLiteRepository struct {
+	db      *sql.DB
+	log     *slog.Logger
+	builder squirrel.StatementBuilderType
+	metrics *metrics.Metrics
+}
+
+func NewSQLiteRepository(params SQLiteParams) *SQLiteRepository {
+	return &SQLiteRepository{
+		db:      params.DB,
+		log:     params.Logger,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		metrics: params.Metrics,
+	}
+}
+
+func (repo *SQLiteRepository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	defer repo.metrics.ObserveQuery("CreateUser", time.Now())
+
+	query, args, err := repo.builder.
+		Insert("users").
+		Columns("id", "name", "email", "currency", "locale", "time_zone").
+		Values(sqliteUserRow(user)...).
+		Suffix(returningUser).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	created, err := scanSQLiteUser(db.SQLiteConn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "create user: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to create user: "+err.Error())
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// CopyUsers вставляет пользователей в одной транзакции: всех или ни одного.
+func (repo *SQLiteRepository) CopyUsers(ctx context.Context, list []*models.User) (int64, error) {
+	defer repo.metrics.ObserveQuery("CopyUsers", time.Now())
+
+	conn := db.SQLiteConn(ctx, repo.db)
+	var sqlTx *sql.Tx
+	if !tx.Active(ctx) {
+		var err error
+		if sqlTx, err = repo.db.BeginTx(ctx, nil); err != nil {
+			return 0, err
+		}
+		defer sqlTx.Rollback()
+		conn = sqlTx
+	}
+
+	stmt, err := conn.PrepareContext(ctx,
+		"INSERT INTO users (id, name, email, currency, locale, time_zone) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, user := range list {
+		if _, err := stmt.ExecContext(ctx, sqliteUserRow(user)...); err != nil {
+			if domainErr := sqliteViolations.Map(err); domainErr != nil {
+				return 0, domainErr
+			}
+			repo.log.ErrorContext(ctx, "failed to copy users: "+err.Error())
+			return 0, err
+		}
+	}
+
+	if sqlTx != nil {
+		if err := sqlTx.Commit(); err != nil {
+			repo.log.ErrorContext(ctx, "failed to copy users: "+err.Error())
+			return 0, err
+		}
+	}
+
+	return int64(len(list)), nil
+}
+
+func (repo *SQLiteRepository) UpdateUser(ctx context.Context, id uuid.UUID, update models.UserUpdate) (*models.User, error) {
+	defer repo.metrics.ObserveQuery("UpdateUser", time.Now())
+
+	builder := repo.builder.Update("users")
+	if update.Name != nil {
+		builder = builder.Set("name", *update.Name)
+	}
+	if update.Email != nil {
+		builder = builder.Set("email", nullString(*update.Email))
+	}
+	if update.Currency != nil {
+		builder = builder.Set("currency", *update.Currency)
+	}
+	if update.Locale != nil {
+		builder = builder.Set("locale", *update.Locale)
+	}
+	if update.TimeZone != nil {
+		builder = builder.Set("time_zone", *update.TimeZone)
+	}
+
+	query, args, err := builder.
+		Set("updated_at", squirrel.Expr(db.SQLiteNow)).
+		Where(squirrel.Eq{"id": id.String()}).
+		Suffix(returningUser).
+		ToSql()
+	if err != nil {
+		repo.log.ErrorContext(ctx, "build query error: "+err.Error())
+		return nil, err
+	}
+
+	updated, err := scanSQLiteUser(db.SQLiteConn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, users.ErrNotFound
+		}
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			repo.log.WarnContext(ctx, "update user: "+domainErr.Error())
+			return nil, domainErr
+		}
+		repo.log.ErrorContext(ctx, "failed to update user: "+err.Error())
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (repo *SQLiteRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	defer repo.metrics.ObserveQuery("GetUserByID", time.Now())
+
+	query, args, err := repo.builder.
+		Select(userColumns...).
+		From("users").
+		Where(squirrel.Eq{"id": id.String()}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := scanSQLiteUser(db.SQLiteConn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, users.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (repo *SQLiteRepository) ListUsers(ctx context.Context) ([]*models.User, error) {
+	defer repo.metrics.ObserveQuery("ListUsers", time.Now())
+
+	query, args, err := repo.builder.
+		Select(userColumns...).
+		From("users").
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, repo.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		repo.log.ErrorContext(ctx, "failed to list users: "+err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.User
+	for rows.Next() {
+		user, err := scanSQLiteUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, user)
+	}
+
+	return list, rows.Err()
+}
+
+func (repo *SQLiteRepository) DeleteUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	defer repo.metrics.ObserveQuery("DeleteUser", time.Now())
+
+	query, args, err := repo.builder.
+		Delete("users").
+		Where(squirrel.Eq{"id": id.String()}).
+		Suffix(returningUser).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := scanSQLiteUser(db.SQLiteConn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, users.ErrNotFound
+		}
+		if domainErr := sqliteViolations.Map(err); domainErr != nil {
+			return nil, domainErr
+		}
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func scanSQLiteUser(row interface{ Scan(dest ...any) error }) (*models.User, error) {
+	user := &models.User{}
+	var createdAt, updatedAt string
+	if err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Currency,
+		&user.Locale,
+		&user.TimeZone,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if user.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at %q: %w", createdAt, err)
+	}
+	if user.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return nil, fmt.Errorf("invalid updated_at %q: %w", updatedAt, err)
+	}
+	return user, nil
+}
+
+func sqliteUserRow(user *models.User) []interface{} {
+	return []interface{}{user.ID.String(), user.Name, nullString(user.Email), user.Currency, user.Locale, user.TimeZone}
+}
+
+var sqliteViolations = db.SQLiteViolations{
+	{Code: sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, Err: users.ErrAlreadyExists},
+	{Code: sqlite3.SQLITE_CONSTRAINT_UNIQUE, Constraint: "users_email_idx", Err: users.ErrEmailTaken},
+	{Code: sqlite3.SQLITE_CONSTRAINT_UNIQUE, Err: users.ErrAlreadyExists},
+	{Code: sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY, Err: users.ErrHasSubscriptions},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Constraint: "users_currency_format", Err: users.ErrInvalidCurrency},
+	{Code: sqlite3.SQLITE_CONSTRAINT_CHECK, Err: users.ErrInvalidArgument},
+}
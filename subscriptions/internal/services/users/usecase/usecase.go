@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/internal/pkg/tx"
+	"github.com/ekkserapopova/subscriptions/internal/services/catalog"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	"github.com/ekkserapopova/subscriptions/internal/services/users"
+	"github.com/ekkserapopova/subscriptions/pkg/logger"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"golang.org/x/text/language"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type Params struct {
+	fx.In
+
+	Logger         *slog.Logger
+	Repo           users.Repository
+	Subscriptions  subscriptions.Repository
+	Catalog        subscriptions.ServiceCatalog
+	Events         events.Publisher
+	Tx             tx.Manager
+	TracerProvider trace.TracerProvider
+}
+
+type UseCase struct {
+	log           *slog.Logger
+	repo          users.Repository
+	subscriptions subscriptions.Repository
+	catalog       subscriptions.ServiceCatalog
+	events        events.Publisher
+	tx            tx.Manager
+	tracer        trace.Tracer
+}
+
+func NewUseCase(params Params) *UseCase {
+	return &UseCase{
+		log:           params.Logger,
+		repo:          params.Repo,
+		subscriptions: params.Subscriptions,
+		catalog:       params.Catalog,
+		events:        params.Events,
+		tx:            params.Tx,
+		tracer:        params.TracerProvider.Tracer(tracerName),
+	}
+}
+
+func (u *UseCase) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.CreateUser")
+	defer span.End()
+
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	logger.SetUserID(ctx, user.ID.String())
+
+	if user.Currency == "" {
+		user.Currency = models.DefaultCurrency
+	}
+	if user.Locale == "" {
+		user.Locale = models.DefaultLocale
+	}
+	if user.TimeZone == "" {
+		user.TimeZone = models.DefaultTimeZone
+	}
+
+	if err := validate(user); err != nil {
+		u.log.WarnContext(ctx, "create user: "+err.Error())
+		return nil, recordError(span, err)
+	}
+
+	created, err := u.repo.CreateUser(ctx, user)
+	return created, recordError(span, err)
+}
+
+func (u *UseCase) UpdateUser(ctx context.Context, id uuid.UUID, update models.UserUpdate) (*models.User, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.UpdateUser", trace.WithAttributes(userIDAttr(id)))
+	defer span.End()
+
+	logger.SetUserID(ctx, id.String())
+
+	if update == (models.UserUpdate{}) {
+		return nil, users.ErrNoFieldsToUpdate
+	}
+
+	// Проверяется пользователь в том виде, в каком он будет сохранен.
+	probe := &models.User{Name: "-", Currency: models.DefaultCurrency, Locale: models.DefaultLocale, TimeZone: models.DefaultTimeZone}
+	update.Apply(probe)
+	if err := validate(probe); err != nil {
+		u.log.WarnContext(ctx, "update user: "+err.Error())
+		return nil, recordError(span, err)
+	}
+
+	updated, err := u.repo.UpdateUser(ctx, id, update)
+	return updated, recordError(span, err)
+}
+
+func (u *UseCase) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetUserByID", trace.WithAttributes(userIDAttr(id)))
+	defer span.End()
+
+	user, err := u.repo.GetUserByID(ctx, id)
+	return user, recordError(span, err)
+}
+
+func (u *UseCase) ListUsers(ctx context.Context) ([]*models.User, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.ListUsers")
+	defer span.End()
+
+	result, err := u.repo.ListUsers(ctx)
+	return result, recordError(span, err)
+}
+
+// DeleteUser удаляет пользователя без подписок. С cascade его подписки
+// удаляются в той же транзакции, о каждой публикуется событие удаления.
+func (u *UseCase) DeleteUser(ctx context.Context, id uuid.UUID, cascade bool) error {
+	ctx, span := u.tracer.Start(ctx, "UseCase.DeleteUser", trace.WithAttributes(userIDAttr(id)))
+	defer span.End()
+
+	logger.SetUserID(ctx, id.String())
+
+	err := u.tx.Do(ctx, tx.Options{}, func(ctx context.Context) error {
+		if _, err := u.repo.GetUserByID(ctx, id); err != nil {
+			return err
+		}
+
+		subs, err := u.subscriptions.ListSubscriptions(ctx, models.SubscriptionFilter{UserIDs: []uuid.UUID{id}})
+		if err != nil {
+			return err
+		}
+		if len(subs) > 0 && !cascade {
+			return users.ErrHasSubscriptions
+		}
+
+		for _, sub := range subs {
+			deleted, err := u.subscriptions.DeleteSubscription(ctx, sub.ID)
+			if err != nil {
+				return err
+			}
+			u.publish(ctx, deleted)
+		}
+
+		_, err = u.repo.DeleteUser(ctx, id)
+		return err
+	})
+	return recordError(span, err)
+}
+
+func (u *UseCase) GetUserSubscriptions(ctx context.Context, id uuid.UUID, filter models.SubscriptionFilter) ([]*models.Subscription, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetUserSubscriptions", trace.WithAttributes(userIDAttr(id)))
+	defer span.End()
+
+	if _, err := u.repo.GetUserByID(ctx, id); err != nil {
+		return nil, recordError(span, err)
+	}
+
+	filter.UserIDs = []uuid.UUID{id}
+	result, err := u.subscriptions.ListSubscriptions(ctx, filter)
+	return result, recordError(span, err)
+}
+
+// GetUserSummary считает подписки, действующие в текущем месяце по часовому
+// поясу пользователя. Подписки на сервисы каталога в другой валюте не входят
+// в MonthlyTotal и суммируются отдельно по своим валютам.
+func (u *UseCase) GetUserSummary(ctx context.Context, id uuid.UUID) (*models.UserSummary, error) {
+	ctx, span := u.tracer.Start(ctx, "UseCase.GetUserSummary", trace.WithAttributes(userIDAttr(id)))
+	defer span.End()
+
+	user, err := u.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	subs, err := u.subscriptions.ListSubscriptions(ctx, models.SubscriptionFilter{UserIDs: []uuid.UUID{id}})
+	if err != nil {
+		return nil, recordError(span, err)
+	}
+
+	now := time.Now()
+	if loc, err := time.LoadLocation(user.TimeZone); err == nil {
+		now = now.In(loc)
+	}
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	summary := &models.UserSummary{
+		UserID:        user.ID,
+		Currency:      user.Currency,
+		Month:         models.MonthYear(month),
+		Subscriptions: len(subs),
+	}
+	currencies := make(map[uuid.UUID]string)
+	for _, sub := range subs {
+		if !sub.ActiveIn(month) {
+			continue
+		}
+		summary.ActiveSubscriptions++
+
+		currency, err := u.currency(ctx, user, sub, currencies)
+		if err != nil {
+			return nil, recordError(span, err)
+		}
+		if currency == user.Currency {
+			summary.MonthlyTotal += sub.ChargeIn(month)
+			continue
+		}
+		if summary.OtherCurrencies == nil {
+			summary.OtherCurrencies = make(map[string]int)
+		}
+		summary.OtherCurrencies[currency] += sub.ChargeIn(month)
+	}
+
+	return summary, nil
+}
+
+// currency возвращает валюту цены подписки: валюту сервиса каталога или,
+// без сервиса, валюту пользователя. Валюты сервисов запоминаются в cache.
+func (u *UseCase) currency(ctx context.Context, user *models.User, sub *models.Subscription, cache map[uuid.UUID]string) (string, error) {
+	if sub.ServiceID == nil {
+		return user.Currency, nil
+	}
+	if currency, ok := cache[*sub.ServiceID]; ok {
+		return currency, nil
+	}
+
+	currency := user.Currency
+	service, err := u.catalog.GetServiceByID(ctx, *sub.ServiceID)
+	switch {
+	case errors.Is(err, catalog.ErrNotFound):
+	case err != nil:
+		return "", err
+	default:
+		currency = service.Currency
+	}
+	cache[*sub.ServiceID] = currency
+	return currency, nil
+}
+
+func (u *UseCase) publish(ctx context.Context, sub *models.Subscription) {
+	tx.AfterCommit(ctx, func(ctx context.Context) {
+		if err := u.events.Publish(ctx, events.NewEvent(events.TypeDeleted, sub)); err != nil {
+			u.log.WarnContext(ctx, "failed to publish "+string(events.TypeDeleted)+" event: "+err.Error())
+		}
+	})
+}
+
+func validate(user *models.User) error {
+	if strings.TrimSpace(user.Name) == "" {
+		return users.ErrNameRequired
+	}
+	if !currencyPattern.MatchString(user.Currency) {
+		return users.ErrInvalidCurrency
+	}
+	if _, err := language.Parse(user.Locale); err != nil {
+		return users.ErrInvalidLocale
+	}
+	if _, err := time.LoadLocation(user.TimeZone); err != nil || user.TimeZone == "" || user.TimeZone == "Local" {
+		return users.ErrInvalidTimeZone
+	}
+	return nil
+}
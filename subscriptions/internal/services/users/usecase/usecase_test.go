@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	catalogRepo "github.com/ekkserapopova/subscriptions/internal/services/catalog/repo"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	subscriptionRepo "github.com/ekkserapopova/subscriptions/internal/services/subscriptions/repo"
+	"github.com/ekkserapopova/subscriptions/internal/services/users"
+	userRepo "github.com/ekkserapopova/subscriptions/internal/services/users/repo"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace/noop"
+	"io"
+	"log/slog"
+	"maps"
+	"testing"
+	"time"
+)
+
+// recordingPublisher запоминает опубликованные события.
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e events.Event) error {
+	p.events = append(p.events, e)
+	return nil
+}
+
+// failingDelete не дает удалить пользователя, как ошибка базы после
+// удаления его подписок.
+type failingDelete struct {
+	users.Repository
+}
+
+func (failingDelete) DeleteUser(context.Context, uuid.UUID) (*models.User, error) {
+	return nil, errors.New("connection reset")
+}
+
+type testEnv struct {
+	u         *UseCase
+	users     *userRepo.MemoryRepository
+	subs      *subscriptionRepo.MemoryRepository
+	catalog   *catalogRepo.MemoryRepository
+	published *recordingPublisher
+}
+
+// newTestEnv собирает usecase на хранилищах в памяти, как при --storage memory.
+func newTestEnv() *testEnv {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	env := &testEnv{
+		users:     userRepo.NewMemoryRepository(userRepo.MemoryParams{Logger: log}),
+		catalog:   catalogRepo.NewMemoryRepository(catalogRepo.MemoryParams{Logger: log}),
+		published: &recordingPublisher{},
+	}
+	env.subs = subscriptionRepo.NewMemoryRepository(subscriptionRepo.MemoryParams{Logger: log, Users: env.users})
+	env.u = NewUseCase(Params{
+		Logger:         log,
+		Repo:           env.users,
+		Subscriptions:  env.subs,
+		Catalog:        env.catalog,
+		Events:         env.published,
+		Tx:             env.subs,
+		TracerProvider: noop.NewTracerProvider(),
+	})
+	return env
+}
+
+func (env *testEnv) createUser(t *testing.T, currency string) *models.User {
+	t.Helper()
+	user, err := env.u.CreateUser(context.Background(), &models.User{Name: "Анна", Currency: currency})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func (env *testEnv) createSubscription(t *testing.T, userID uuid.UUID, price int, serviceID *uuid.UUID, end *models.MonthYear) {
+	t.Helper()
+	_, err := env.subs.CreateSubscription(context.Background(), &models.Subscription{
+		ID:          uuid.New(),
+		ServiceName: "Yandex Plus",
+		ServiceID:   serviceID,
+		Price:       &price,
+		UserID:      userID,
+		StartDate:   models.MonthYear(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		EndDate:     end,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCreateUserValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		user models.User
+		err  error
+	}{
+		{name: "значения по умолчанию", user: models.User{Name: "Анна"}},
+		{name: "все поля", user: models.User{Name: "Анна", Currency: "EUR", Locale: "de-DE", TimeZone: "Europe/Berlin"}},
+		{name: "без имени", user: models.User{Name: "  "}, err: users.ErrNameRequired},
+		{name: "валюта строчными", user: models.User{Name: "Анна", Currency: "usd"}, err: users.ErrInvalidCurrency},
+		{name: "валюта из двух букв", user: models.User{Name: "Анна", Currency: "US"}, err: users.ErrInvalidCurrency},
+		{name: "неверная локаль", user: models.User{Name: "Анна", Locale: "not a locale"}, err: users.ErrInvalidLocale},
+		{name: "неизвестный часовой пояс", user: models.User{Name: "Анна", TimeZone: "Mars/Olympus"}, err: users.ErrInvalidTimeZone},
+		{name: "часовой пояс Local", user: models.User{Name: "Анна", TimeZone: "Local"}, err: users.ErrInvalidTimeZone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv()
+			user := tt.user
+			created, err := env.u.CreateUser(context.Background(), &user)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if created.Currency == "" || created.Locale == "" || created.TimeZone == "" {
+				t.Errorf("defaults not applied: %+v", created)
+			}
+		})
+	}
+}
+
+func TestUpdateUserValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		update models.UserUpdate
+		err    error
+	}{
+		{name: "валюта", update: models.UserUpdate{Currency: ptr("USD")}},
+		{name: "пустое обновление", err: users.ErrNoFieldsToUpdate},
+		{name: "неверная валюта", update: models.UserUpdate{Currency: ptr("rub")}, err: users.ErrInvalidCurrency},
+		{name: "неверная локаль", update: models.UserUpdate{Locale: ptr("???")}, err: users.ErrInvalidLocale},
+		{name: "пустой часовой пояс", update: models.UserUpdate{TimeZone: ptr("")}, err: users.ErrInvalidTimeZone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv()
+			user := env.createUser(t, "")
+
+			_, err := env.u.UpdateUser(context.Background(), user.ID, tt.update)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			stored, err := env.users.GetUserByID(context.Background(), user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.err != nil && *stored != *user {
+				t.Errorf("user changed after rejected update: %+v", stored)
+			}
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	tests := []struct {
+		name          string
+		subscriptions int
+		cascade       bool
+		failDelete    bool
+		err           error
+		deleted       bool
+	}{
+		{name: "без подписок", deleted: true},
+		{name: "подписки без cascade", subscriptions: 2, err: users.ErrHasSubscriptions},
+		{name: "подписки с cascade", subscriptions: 2, cascade: true, deleted: true},
+		{name: "ошибка удаления откатывает подписки", subscriptions: 2, cascade: true, failDelete: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv()
+			user := env.createUser(t, "")
+			for range tt.subscriptions {
+				env.createSubscription(t, user.ID, 299, nil, nil)
+			}
+			if tt.failDelete {
+				env.u.repo = failingDelete{Repository: env.users}
+			}
+
+			err := env.u.DeleteUser(ctx, user.ID, tt.cascade)
+			switch {
+			case tt.err != nil && !errors.Is(err, tt.err):
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			case tt.deleted && err != nil:
+				t.Fatalf("DeleteUser: %v", err)
+			case !tt.deleted && err == nil:
+				t.Fatal("DeleteUser succeeded, want error")
+			}
+
+			_, err = env.users.GetUserByID(ctx, user.ID)
+			if deleted := errors.Is(err, users.ErrNotFound); deleted != tt.deleted {
+				t.Errorf("user deleted = %v, want %v", deleted, tt.deleted)
+			}
+
+			subs, err := env.subs.ListSubscriptions(ctx, models.SubscriptionFilter{UserIDs: []uuid.UUID{user.ID}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantSubs, wantEvents := tt.subscriptions, 0
+			if tt.deleted {
+				wantSubs, wantEvents = 0, tt.subscriptions
+			}
+			if len(subs) != wantSubs {
+				t.Errorf("subscriptions left = %d, want %d", len(subs), wantSubs)
+			}
+			if len(env.published.events) != wantEvents {
+				t.Errorf("published events = %d, want %d", len(env.published.events), wantEvents)
+			}
+			for _, e := range env.published.events {
+				if e.Type != events.TypeDeleted {
+					t.Errorf("event type = %s, want %s", e.Type, events.TypeDeleted)
+				}
+			}
+		})
+	}
+}
+
+func TestGetUserSummary(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv()
+	user := env.createUser(t, "RUB")
+
+	service := func(currency string) *uuid.UUID {
+		t.Helper()
+		created, err := env.catalog.CreateService(ctx, &models.Service{ID: uuid.New(), Name: "Сервис " + currency, Currency: currency})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &created.ID
+	}
+
+	usd, eur, rub := service("USD"), service("EUR"), service("RUB")
+	ended := models.MonthYear(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	env.createSubscription(t, user.ID, 500, nil, nil)
+	env.createSubscription(t, user.ID, 300, rub, nil)
+	// Сервис удален из каталога: цена считается в валюте пользователя.
+	env.createSubscription(t, user.ID, 200, ptr(uuid.New()), nil)
+	env.createSubscription(t, user.ID, 10, usd, nil)
+	env.createSubscription(t, user.ID, 15, usd, nil)
+	env.createSubscription(t, user.ID, 7, eur, nil)
+	env.createSubscription(t, user.ID, 1000, nil, &ended)
+
+	summary, err := env.u.GetUserSummary(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Currency != "RUB" || summary.Subscriptions != 7 || summary.ActiveSubscriptions != 6 {
+		t.Errorf("summary = %+v, want RUB with 7 subscriptions, 6 active", summary)
+	}
+	if summary.MonthlyTotal != 1000 {
+		t.Errorf("MonthlyTotal = %d, want 1000", summary.MonthlyTotal)
+	}
+	if want := map[string]int{"USD": 25, "EUR": 7}; !maps.Equal(summary.OtherCurrencies, want) {
+		t.Errorf("OtherCurrencies = %v, want %v", summary.OtherCurrencies, want)
+	}
+}
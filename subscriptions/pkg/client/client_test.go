@@ -0,0 +1,357 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/ekkserapopova/subscriptions/pkg/client"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newServer поднимает httptest.Server с handler и клиент к нему.
+func newServer(t *testing.T, handler http.HandlerFunc, opts ...client.Option) *client.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	opts = append([]client.Option{client.WithRetries(3, time.Millisecond)}, opts...)
+	c, err := client.New(srv.URL+"/api/v1/", opts...)
+	if err != nil {
+		t.Fatalf("client.New: %v", err)
+	}
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestRequestEncoding(t *testing.T) {
+	userID := uuid.New()
+	id := uuid.New()
+	end := client.NewMonthYear(2026, time.December)
+	price := 450
+
+	tests := []struct {
+		name       string
+		call       func(c *client.Client) error
+		wantMethod string
+		wantPath   string
+		wantQuery  string
+		wantBody   string
+	}{
+		{
+			name: "create",
+			call: func(c *client.Client) error {
+				_, err := c.CreateSubscription(context.Background(), &client.Subscription{
+					ID: id, ServiceName: "Netflix", Price: &price, UserID: userID,
+					StartDate: client.NewMonthYear(2026, time.March), EndDate: &end,
+					Tags: []string{}, Promo: &client.Promo{Type: models.PromoFixed, Value: 1, Months: 3},
+				})
+				return err
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "/api/v1/subscriptions",
+			wantBody: fmt.Sprintf(`{"id":"%s","service_name":"Netflix","service_id":null,"category":"","tags":[],"price":450,`+
+				`"user_id":"%s","start_date":"03-2026","end_date":"12-2026","trial_end":null,`+
+				`"promo":{"type":"fixed","value":1,"months":3}}`, id, userID),
+		},
+		{
+			name: "update clears optional fields",
+			call: func(c *client.Client) error {
+				from := client.NewMonthYear(2027, time.January)
+				_, err := c.UpdateSubscription(context.Background(), id, client.UpdateSubscriptionRequest{
+					Price: &price, EffectiveFrom: &from, ClearEndDate: true, ClearPromo: true, Tags: []string{},
+				})
+				return err
+			},
+			wantMethod: http.MethodPut,
+			wantPath:   "/api/v1/subscriptions/" + id.String(),
+			wantBody:   `{"effective_from":"01-2027","end_date":null,"price":450,"promo":null,"tags":[]}`,
+		},
+		{
+			name: "sum filter",
+			call: func(c *client.Client) error {
+				start := client.NewMonthYear(2026, time.January)
+				_, err := c.SumSubscriptions(context.Background(), client.SumFilter{
+					StartDate: &start, EndDate: &end, ServiceName: "Netflix", UserIDs: []uuid.UUID{userID, id}, Tag: "work",
+				})
+				return err
+			},
+			wantMethod: http.MethodGet,
+			wantPath:   "/api/v1/subscriptions/sum",
+			wantQuery:  "end_date=12-2026&name=Netflix&start_date=01-2026&tag=work&users_ids=" + userID.String() + "%2C" + id.String(),
+		},
+		{
+			name: "delete price",
+			call: func(c *client.Client) error {
+				_, err := c.DeletePrice(context.Background(), id, end)
+				return err
+			},
+			wantMethod: http.MethodDelete,
+			wantPath:   "/api/v1/subscriptions/" + id.String() + "/prices/12-2026",
+		},
+		{
+			name: "trials ending",
+			call: func(c *client.Client) error {
+				_, err := c.TrialsEnding(context.Background(), end, userID)
+				return err
+			},
+			wantMethod: http.MethodGet,
+			wantPath:   "/api/v1/subscriptions/trials",
+			wantQuery:  "month=12-2026&users_ids=" + userID.String(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method != tt.wantMethod || r.URL.Path != tt.wantPath || r.URL.RawQuery != tt.wantQuery {
+					t.Errorf("request = %s %s?%s, want %s %s?%s", r.Method, r.URL.Path, r.URL.RawQuery, tt.wantMethod, tt.wantPath, tt.wantQuery)
+				}
+				if got := strings.TrimSpace(string(body)); got != tt.wantBody {
+					t.Errorf("body = %s, want %s", got, tt.wantBody)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+					t.Errorf("Authorization = %q", got)
+				}
+				if got := r.Header.Get("X-Team"); got != "billing" {
+					t.Errorf("X-Team = %q", got)
+				}
+				if tt.wantBody != "" && r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+				}
+				writeJSON(w, http.StatusOK, nil)
+			}, client.WithToken("secret"), client.WithHeader("X-Team", "billing"))
+
+			if err := tt.call(c); err != nil {
+				t.Fatalf("call: %v", err)
+			}
+		})
+	}
+}
+
+func TestResponseDecoding(t *testing.T) {
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"9d8b2f6e-1c1a-4a7e-9f59-0d0c8f1a3b11","service_name":"Netflix","price":500,`+
+			`"prices":[{"effective_from":"01-2026","price":400},{"effective_from":"06-2026","price":500}],`+
+			`"start_date":"01-2026","end_date":null,"trial_end":"02-2026"}`)
+	})
+
+	sub, err := c.GetSubscription(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("GetSubscription: %v", err)
+	}
+	if sub.StartDate.Time() != time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("start_date = %v", sub.StartDate.Time())
+	}
+	if sub.EndDate != nil {
+		t.Errorf("end_date = %v, want nil", sub.EndDate.Time())
+	}
+	if sub.TrialEnd == nil || sub.TrialEnd.Time().Month() != time.February {
+		t.Errorf("trial_end = %v", sub.TrialEnd)
+	}
+	if len(sub.Prices) != 2 || sub.Prices[1].Price != 500 || sub.Prices[1].EffectiveFrom.Time().Month() != time.June {
+		t.Errorf("prices = %+v", sub.Prices)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantIs      error
+		wantMessage string
+	}{
+		{name: "not found", status: http.StatusNotFound, body: `{"msg":"subscription not found"}`, wantIs: client.ErrNotFound, wantMessage: "subscription not found"},
+		{name: "conflict", status: http.StatusConflict, body: `{"msg":"subscription already exists"}`, wantIs: client.ErrAlreadyExists, wantMessage: "subscription already exists"},
+		{name: "bad request", status: http.StatusBadRequest, body: `{"msg":"invalid promo"}`, wantIs: client.ErrInvalidArgument, wantMessage: "invalid promo"},
+		{name: "plain text", status: http.StatusInternalServerError, body: "boom\n", wantMessage: "boom"},
+		{name: "empty body", status: http.StatusForbidden, wantMessage: "Forbidden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			})
+
+			_, err := c.CreateSubscription(context.Background(), &client.Subscription{})
+
+			var apiErr *client.Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want *client.Error", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.wantMessage {
+				t.Errorf("error = %d %q, want %d %q", apiErr.StatusCode, apiErr.Message, tt.status, tt.wantMessage)
+			}
+			for _, target := range []error{client.ErrNotFound, client.ErrAlreadyExists, client.ErrInvalidArgument} {
+				if got := errors.Is(err, target); got != (target == tt.wantIs) {
+					t.Errorf("errors.Is(err, %v) = %v", target, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		statuses  []int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{name: "GET повторяется до успеха", method: http.MethodGet, statuses: []int{503, 502, 200}, wantCalls: 3},
+		{name: "429 повторяется", method: http.MethodDelete, statuses: []int{429, 204}, wantCalls: 2},
+		{name: "попытки заканчиваются", method: http.MethodGet, statuses: []int{503, 503, 503, 503, 200}, wantCalls: 4, wantErr: true},
+		{name: "POST не повторяется", method: http.MethodPost, statuses: []int{503, 200}, wantCalls: 1, wantErr: true},
+		{name: "4xx не повторяется", method: http.MethodGet, statuses: []int{404, 200}, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				writeJSON(w, tt.statuses[n-1], map[string]any{"msg": "status"})
+			})
+
+			var err error
+			switch tt.method {
+			case http.MethodGet:
+				_, err = c.GetSubscription(context.Background(), uuid.New())
+			case http.MethodDelete:
+				err = c.DeleteSubscription(context.Background(), uuid.New())
+			case http.MethodPost:
+				_, err = c.CreateSubscription(context.Background(), &client.Subscription{})
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetriesStopOnContextCancel(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c, err := client.New(srv.URL, client.WithRetries(5, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = c.GetSubscription(ctx, uuid.New())
+	if !errors.Is(err, context.DeadlineExceeded) || calls.Load() != 1 {
+		t.Errorf("error = %v, calls = %d, want deadline exceeded after 1 call", err, calls.Load())
+	}
+}
+
+func TestListUserSubscriptionsPagination(t *testing.T) {
+	userID := uuid.New()
+	ids := make([]uuid.UUID, 5)
+	for i := range ids {
+		ids[i] = uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1))
+	}
+
+	var afterIDs []string
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/users/"+userID.String()+"/subscriptions" || r.URL.Query().Get("limit") != "2" {
+			t.Errorf("request = %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		after := r.URL.Query().Get("after_id")
+		afterIDs = append(afterIDs, after)
+
+		page := []*client.Subscription{}
+		for _, id := range ids {
+			if (after == "" || id.String() > after) && len(page) < 2 {
+				page = append(page, &client.Subscription{ID: id, UserID: userID})
+			}
+		}
+		writeJSON(w, http.StatusOK, page)
+	})
+
+	subs, err := c.ListUserSubscriptions(context.Background(), userID, 2)
+	if err != nil {
+		t.Fatalf("ListUserSubscriptions: %v", err)
+	}
+	if len(subs) != len(ids) {
+		t.Fatalf("got %d subscriptions, want %d", len(subs), len(ids))
+	}
+	for i, sub := range subs {
+		if sub.ID != ids[i] {
+			t.Errorf("subs[%d] = %s, want %s", i, sub.ID, ids[i])
+		}
+	}
+	want := []string{"", ids[1].String(), ids[3].String()}
+	if strings.Join(afterIDs, ",") != strings.Join(want, ",") {
+		t.Errorf("after_id = %v, want %v", afterIDs, want)
+	}
+
+	if _, err := c.ListUserSubscriptions(context.Background(), userID, 0); err == nil {
+		t.Error("page size 0: want error")
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Last-Event-ID") != "7" || r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, ": heartbeat\n\n"+
+			"id: 3\nevent: stream.reset\ndata: {\"id\":3,\"type\":\"stream.reset\"}\n\n"+
+			"id: 4\nevent: subscription.created\ndata: {\"id\":4,\n"+
+			"data: \"type\":\"subscription.created\"}\n\n")
+	})
+
+	stream, err := c.StreamEvents(context.Background(), client.EventsFilter{LastEventID: 7})
+	if err != nil {
+		t.Fatalf("StreamEvents: %v", err)
+	}
+	defer stream.Close()
+
+	for _, want := range []struct {
+		id  uint64
+		typ client.EventType
+	}{{3, client.EventReset}, {4, client.EventCreated}} {
+		e, err := stream.Next()
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if e.ID != want.id || e.Type != want.typ || stream.LastEventID() != want.id {
+			t.Errorf("event = %d %s, last = %d, want %d %s", e.ID, e.Type, stream.LastEventID(), want.id, want.typ)
+		}
+	}
+	if _, err := stream.Next(); err != io.EOF {
+		t.Errorf("Next after end = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"encoding/json"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions"
+	"net/http"
+	"strings"
+)
+
+// Ошибки сервиса, с которыми сопоставляется *Error через errors.Is.
+var (
+	ErrNotFound        = subscriptions.ErrNotFound
+	ErrAlreadyExists   = subscriptions.ErrAlreadyExists
+	ErrInvalidArgument = subscriptions.ErrInvalidArgument
+)
+
+// Error — ответ API с кодом 4xx или 5xx.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func newError(statusCode int, body []byte) *Error {
+	e := &Error{StatusCode: statusCode}
+
+	var resp struct {
+		Msg string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Msg != "" {
+		e.Message = resp.Msg
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+
+	if e.Message == "" {
+		e.Message = http.StatusText(statusCode)
+	}
+
+	return e
+}
+
+func (e *Error) Error() string {
+	return http.StatusText(e.StatusCode) + ": " + e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrAlreadyExists:
+		return e.StatusCode == http.StatusConflict
+	case ErrInvalidArgument:
+		return e.StatusCode == http.StatusBadRequest
+	default:
+		return false
+	}
+}
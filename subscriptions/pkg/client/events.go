@@ -0,0 +1,132 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/services/subscriptions/events"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type (
+	Event     = events.Event
+	EventType = events.Type
+)
+
+const (
+	EventCreated = events.TypeCreated
+	EventUpdated = events.TypeUpdated
+	EventDeleted = events.TypeDeleted
+	// EventReset приходит вместо событий, которые сервер уже не может
+	// повторить: подписки нужно перечитать.
+	EventReset = events.TypeReset
+)
+
+// EventsFilter задает параметры GET /subscriptions/events.
+type EventsFilter struct {
+	// UserID ограничивает поток событиями одного пользователя.
+	UserID uuid.UUID
+	// LastEventID продолжает поток после указанного события.
+	LastEventID uint64
+}
+
+// EventStream читает Server-Sent Events. После обрыва соединения поток можно
+// открыть заново с LastEventID из LastEventID().
+type EventStream struct {
+	body        io.ReadCloser
+	reader      *bufio.Reader
+	lastEventID uint64
+}
+
+// StreamEvents открывает поток изменений подписок. Запрос не повторяется
+// автоматически и живет, пока не отменен ctx или не вызван Close.
+func (c *Client) StreamEvents(ctx context.Context, filter EventsFilter) (*EventStream, error) {
+	query := make(url.Values)
+	if filter.UserID != uuid.Nil {
+		query.Set("user_id", filter.UserID.String())
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/subscriptions/events", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if filter.LastEventID > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(filter.LastEventID, 10))
+	}
+
+	// Общий таймаут http.Client оборвал бы долгоживущий поток.
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, handleResponse(resp, nil)
+	}
+
+	return &EventStream{
+		body:        resp.Body,
+		reader:      bufio.NewReader(resp.Body),
+		lastEventID: filter.LastEventID,
+	}, nil
+}
+
+// Next блокируется до следующего события. При закрытии потока сервером
+// возвращается io.EOF.
+func (s *EventStream) Next() (Event, error) {
+	var data strings.Builder
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" {
+				return Event{}, io.EOF
+			}
+			if err != io.EOF {
+				return Event{}, err
+			}
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+
+			var e Event
+			if err := json.Unmarshal([]byte(data.String()), &e); err != nil {
+				return Event{}, fmt.Errorf("failed to decode event: %w", err)
+			}
+			if e.ID > s.lastEventID || e.Type == EventReset {
+				s.lastEventID = e.ID
+			}
+			return e, nil
+		case strings.HasPrefix(line, ":"):
+			// Комментарий, например heartbeat.
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+}
+
+func (s *EventStream) LastEventID() uint64 {
+	return s.lastEventID
+}
+
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
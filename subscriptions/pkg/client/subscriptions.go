@@ -0,0 +1,235 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/ekkserapopova/subscriptions/internal/models"
+	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	Subscription = models.Subscription
+	GroupCost    = models.GroupCost
+	MonthYear    = models.MonthYear
+	Promo        = models.Promo
+)
+
+// NewMonthYear возвращает MonthYear для первого дня месяца.
+func NewMonthYear(year int, month time.Month) MonthYear {
+	return MonthYear(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// UpdateSubscriptionRequest содержит изменяемые поля. Поля со значением nil
+// не передаются; ClearEndDate сбрасывает дату окончания. Tags заменяет теги
+// целиком, пустой срез (не nil) убирает все теги. Price действует с месяца
+// EffectiveFrom, без него - с текущего. ClearTrialEnd и ClearPromo убирают
+// пробный период и промо.
+type UpdateSubscriptionRequest struct {
+	ServiceName   *string
+	ServiceID     *uuid.UUID
+	Category      *string
+	Tags          []string
+	Price         *int
+	EffectiveFrom *MonthYear
+	UserID        *uuid.UUID
+	StartDate     *MonthYear
+	EndDate       *MonthYear
+	ClearEndDate  bool
+	TrialEnd      *MonthYear
+	ClearTrialEnd bool
+	Promo         *Promo
+	ClearPromo    bool
+}
+
+func (r UpdateSubscriptionRequest) body() map[string]any {
+	body := make(map[string]any)
+	if r.ServiceName != nil {
+		body["service_name"] = *r.ServiceName
+	}
+	if r.ServiceID != nil {
+		body["service_id"] = *r.ServiceID
+	}
+	if r.Category != nil {
+		body["category"] = *r.Category
+	}
+	if r.Tags != nil {
+		body["tags"] = r.Tags
+	}
+	if r.Price != nil {
+		body["price"] = *r.Price
+	}
+	if r.EffectiveFrom != nil {
+		body["effective_from"] = *r.EffectiveFrom
+	}
+	if r.UserID != nil {
+		body["user_id"] = *r.UserID
+	}
+	if r.StartDate != nil {
+		body["start_date"] = *r.StartDate
+	}
+	if r.ClearEndDate {
+		body["end_date"] = nil
+	} else if r.EndDate != nil {
+		body["end_date"] = *r.EndDate
+	}
+	if r.ClearTrialEnd {
+		body["trial_end"] = nil
+	} else if r.TrialEnd != nil {
+		body["trial_end"] = *r.TrialEnd
+	}
+	if r.ClearPromo {
+		body["promo"] = nil
+	} else if r.Promo != nil {
+		body["promo"] = r.Promo
+	}
+	return body
+}
+
+// SumFilter — фильтры GET /subscriptions/sum. Пустые поля не учитываются.
+type SumFilter struct {
+	StartDate   *MonthYear
+	EndDate     *MonthYear
+	ServiceName string
+	UserIDs     []uuid.UUID
+	Category    string
+	Tag         string
+}
+
+func (f SumFilter) query() url.Values {
+	query := make(url.Values)
+	if f.StartDate != nil {
+		query.Set("start_date", f.StartDate.Time().Format("01-2006"))
+	}
+	if f.EndDate != nil {
+		query.Set("end_date", f.EndDate.Time().Format("01-2006"))
+	}
+	if f.ServiceName != "" {
+		query.Set("name", f.ServiceName)
+	}
+	if len(f.UserIDs) > 0 {
+		ids := make([]string, 0, len(f.UserIDs))
+		for _, id := range f.UserIDs {
+			ids = append(ids, id.String())
+		}
+		query.Set("users_ids", strings.Join(ids, ","))
+	}
+	if f.Category != "" {
+		query.Set("category", f.Category)
+	}
+	if f.Tag != "" {
+		query.Set("tag", f.Tag)
+	}
+	return query
+}
+
+func (c *Client) CreateSubscription(ctx context.Context, sub *Subscription) (*Subscription, error) {
+	created := &Subscription{}
+	if err := c.do(ctx, http.MethodPost, "/subscriptions", nil, sub, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (c *Client) GetSubscription(ctx context.Context, id uuid.UUID) (*Subscription, error) {
+	sub := &Subscription{}
+	if err := c.do(ctx, http.MethodGet, "/subscriptions/"+id.String(), nil, nil, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (c *Client) ListSubscriptions(ctx context.Context) ([]*Subscription, error) {
+	var subs []*Subscription
+	if err := c.do(ctx, http.MethodGet, "/subscriptions", nil, nil, &subs); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (c *Client) UpdateSubscription(ctx context.Context, id uuid.UUID, req UpdateSubscriptionRequest) (*Subscription, error) {
+	updated := &Subscription{}
+	if err := c.do(ctx, http.MethodPut, "/subscriptions/"+id.String(), nil, req.body(), updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (c *Client) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/subscriptions/"+id.String(), nil, nil, nil)
+}
+
+// DeletePrice отменяет изменение цены, начинающееся с month.
+func (c *Client) DeletePrice(ctx context.Context, id uuid.UUID, month MonthYear) (*Subscription, error) {
+	updated := &Subscription{}
+	path := "/subscriptions/" + id.String() + "/prices/" + month.Time().Format("01-2006")
+	if err := c.do(ctx, http.MethodDelete, path, nil, nil, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ListUserSubscriptions читает все подписки пользователя страницами
+// по pageSize, продолжая каждую страницу после ID последней подписки
+// (GET /users/{id}/subscriptions).
+func (c *Client) ListUserSubscriptions(ctx context.Context, userID uuid.UUID, pageSize int) ([]*Subscription, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+
+	query := make(url.Values)
+	query.Set("limit", strconv.Itoa(pageSize))
+
+	var subs []*Subscription
+	for {
+		var page []*Subscription
+		if err := c.do(ctx, http.MethodGet, "/users/"+userID.String()+"/subscriptions", query, nil, &page); err != nil {
+			return nil, err
+		}
+		subs = append(subs, page...)
+		if len(page) < pageSize {
+			return subs, nil
+		}
+		query.Set("after_id", page[len(page)-1].ID.String())
+	}
+}
+
+// TrialsEnding возвращает подписки пользователей userIDs (без них - всех),
+// пробный период которых заканчивается в месяце month.
+func (c *Client) TrialsEnding(ctx context.Context, month MonthYear, userIDs ...uuid.UUID) ([]*Subscription, error) {
+	query := SumFilter{UserIDs: userIDs}.query()
+	query.Set("month", month.Time().Format("01-2006"))
+
+	var subs []*Subscription
+	if err := c.do(ctx, http.MethodGet, "/subscriptions/trials", query, nil, &subs); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (c *Client) SumSubscriptions(ctx context.Context, filter SumFilter) (int, error) {
+	var resp struct {
+		Sum int `json:"sum"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/subscriptions/sum", filter.query(), nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Sum, nil
+}
+
+// SumGroups делит сумму по категориям (models.GroupByCategory) или тегам
+// (models.GroupByTag).
+func (c *Client) SumGroups(ctx context.Context, filter SumFilter, groupBy string) ([]*GroupCost, error) {
+	query := filter.query()
+	query.Set("group_by", groupBy)
+
+	var groups []*GroupCost
+	if err := c.do(ctx, http.MethodGet, "/subscriptions/sum/groups", query, nil, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
@@ -0,0 +1,28 @@
+package logger
+
+const (
+	EnvLocal = "local"
+	EnvDev   = "dev"
+	EnvProd  = "prod"
+
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type Config struct {
+	// Environment задает формат и уровень по умолчанию:
+	// local - text/debug, dev - json/debug, prod - json/info.
+	Environment string     `yaml:"environment" env:"LOG_ENV" env-default:"local"`
+	Level       string     `yaml:"level" env:"LOG_LEVEL"`
+	Format      string     `yaml:"format" env:"LOG_FORMAT"`
+	File        FileConfig `yaml:"file"`
+}
+
+type FileConfig struct {
+	Enabled    bool   `yaml:"enabled" env:"LOG_FILE_ENABLED"`
+	Path       string `yaml:"path" env:"LOG_FILE_PATH"`
+	MaxSizeMB  int    `yaml:"maxSizeMB" env-default:"100"`
+	MaxBackups int    `yaml:"maxBackups" env-default:"5"`
+	MaxAgeDays int    `yaml:"maxAgeDays" env-default:"30"`
+	Compress   bool   `yaml:"compress"`
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+type requestKey struct{}
+
+// requestFields создается один раз на запрос; route и user id
+// становятся известны позже, поэтому поля изменяемые.
+type requestFields struct {
+	mu        sync.RWMutex
+	requestID string
+	userID    string
+	route     string
+}
+
+// WithRequestID сохраняет ID запроса в контексте. Добавленные позже
+// route и user id попадут во все записи, сделанные с этим контекстом.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestKey{}, &requestFields{requestID: requestID})
+}
+
+func RequestID(ctx context.Context) string {
+	fields := fieldsFromContext(ctx)
+	if fields == nil {
+		return ""
+	}
+	fields.mu.RLock()
+	defer fields.mu.RUnlock()
+	return fields.requestID
+}
+
+// SetUserID ничего не делает, если контекст создан не HTTP-запросом.
+func SetUserID(ctx context.Context, userID string) {
+	if fields := fieldsFromContext(ctx); fields != nil {
+		fields.mu.Lock()
+		fields.userID = userID
+		fields.mu.Unlock()
+	}
+}
+
+func SetRoute(ctx context.Context, route string) {
+	if fields := fieldsFromContext(ctx); fields != nil {
+		fields.mu.Lock()
+		fields.route = route
+		fields.mu.Unlock()
+	}
+}
+
+func fieldsFromContext(ctx context.Context) *requestFields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(requestKey{}).(*requestFields)
+	return fields
+}
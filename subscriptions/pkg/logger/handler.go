@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+)
+
+// contextHandler дописывает к записи данные запроса и активного спана,
+// если лог вызван с контекстом (InfoContext, ErrorContext и т.д.).
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if fields := fieldsFromContext(ctx); fields != nil {
+		fields.mu.RLock()
+		record.AddAttrs(slog.String("request_id", fields.requestID))
+		if fields.route != "" {
+			record.AddAttrs(slog.String("route", fields.route))
+		}
+		if fields.userID != "" {
+			record.AddAttrs(slog.String("user_id", fields.userID))
+		}
+		fields.mu.RUnlock()
+	}
+
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanCtx.TraceID().String()),
+			slog.String("span_id", spanCtx.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
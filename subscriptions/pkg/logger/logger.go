@@ -1,16 +1,105 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"go.uber.org/fx"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 )
 
 const logFile = "subscriptionService.log"
 
-func SetupLogger() *slog.Logger {
-	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	}))
+type Params struct {
+	fx.In
 
-	return log
+	Lifecycle fx.Lifecycle
+	Config    Config
+}
+
+type Out struct {
+	fx.Out
+
+	Logger *slog.Logger
+	// Level можно менять на лету, например при перечитывании конфига.
+	Level *slog.LevelVar
+}
+
+func SetupLogger(p Params) (Out, error) {
+	level, format, err := Resolve(p.Config)
+	if err != nil {
+		return Out{}, err
+	}
+
+	var out io.Writer = os.Stdout
+	if p.Config.File.Enabled {
+		path := p.Config.File.Path
+		if path == "" {
+			path = logFile
+		}
+
+		file := &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    p.Config.File.MaxSizeMB,
+			MaxBackups: p.Config.File.MaxBackups,
+			MaxAge:     p.Config.File.MaxAgeDays,
+			Compress:   p.Config.File.Compress,
+		}
+		out = io.MultiWriter(os.Stdout, file)
+
+		p.Lifecycle.Append(fx.Hook{
+			OnStop: func(context.Context) error {
+				return file.Close()
+			},
+		})
+	}
+
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(level)
+	opts := &slog.HandlerOptions{Level: levelVar}
+
+	var handler slog.Handler
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(out, opts)
+	} else {
+		handler = slog.NewTextHandler(out, opts)
+	}
+
+	log := slog.New(contextHandler{handler})
+
+	return Out{Logger: log, Level: levelVar}, nil
+}
+
+// Resolve вычисляет уровень и формат: явные значения из конфига
+// имеют приоритет над значениями по умолчанию для окружения.
+func Resolve(cfg Config) (slog.Level, string, error) {
+	level, format := slog.LevelDebug, FormatText
+
+	switch cfg.Environment {
+	case EnvLocal, "":
+	case EnvDev:
+		format = FormatJSON
+	case EnvProd:
+		level, format = slog.LevelInfo, FormatJSON
+	default:
+		return 0, "", fmt.Errorf("unknown logger environment %q", cfg.Environment)
+	}
+
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return 0, "", fmt.Errorf("invalid logger level %q: %w", cfg.Level, err)
+		}
+	}
+
+	if cfg.Format != "" {
+		format = strings.ToLower(cfg.Format)
+		if format != FormatText && format != FormatJSON {
+			return 0, "", fmt.Errorf("unknown logger format %q", cfg.Format)
+		}
+	}
+
+	return level, format, nil
 }
@@ -0,0 +1,49 @@
+package responser
+
+import "net/http"
+
+// Recorder запоминает код ответа и число записанных байт
+// для логов и метрик запросов.
+type Recorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// NewRecorder переиспользует w, если это уже Recorder: логи и метрики
+// оборачивают один и тот же ответ, и второй слой ничего не добавит.
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	if rec, ok := w.(*Recorder); ok {
+		return rec
+	}
+	return &Recorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *Recorder) Status() int {
+	return w.status
+}
+
+func (w *Recorder) Bytes() int64 {
+	return w.bytes
+}
+
+func (w *Recorder) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *Recorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap нужен http.ResponseController, например для Flush в SSE.
+func (w *Recorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}